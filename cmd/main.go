@@ -1,9 +1,80 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
 	"github.com/bigxxby/effective-mobile-test/internal"
+	config "github.com/bigxxby/effective-mobile-test/pkg/config"
 )
 
+const usage = `Usage: app [command]
+
+Commands:
+  serve               start the HTTP server (default)
+  migrate up          apply all pending migrations
+  migrate down [N]    revert the last N migrations (default 1)
+  migrate status      show applied and pending migrations
+  migrate redo        revert and re-apply the last migration
+  seed                load mock data into an empty database
+  token -user N       issue an access token for the user
+        [-role R]     set the user's role first (admin, manager, employee)
+        [-ttl D]      token lifetime, e.g. 720h
+
+Every command accepts configuration flags named after the environment variables
+(DB_HOST is -db-host, HTTP_ADDR is -http-addr) and -config FILE (.env or YAML).
+Precedence: defaults < config file < environment < flags. Run "app serve -h" for the list.
+`
+
+// @title Effective Mobile Time Tracker API
+// @version 1.0
+// @description Users, tasks, projects and time tracking.
+// @BasePath /
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Type "Bearer" followed by a space and the token from POST /api/auth/token or the "token" command.
 func main() {
-	internal.Run()
+	args := os.Args[1:]
+	command := "serve"
+	// app -http-addr :9090 — флаги без команды относятся к serve
+	if len(args) > 0 && (!strings.HasPrefix(args[0], "-") || args[0] == "-h" || args[0] == "--help") {
+		command, args = args[0], args[1:]
+	}
+
+	var err error
+	switch command {
+	case "serve":
+		err = runServe(args)
+	case "migrate":
+		err = runMigrate(args)
+	case "seed":
+		err = runSeed(args)
+	case "token":
+		err = runToken(args)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func runServe(args []string) error {
+	cfg, err := config.Load(flag.NewFlagSet("serve", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+	return internal.Run(cfg)
 }
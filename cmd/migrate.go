@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/bigxxby/effective-mobile-test/internal"
+	config "github.com/bigxxby/effective-mobile-test/pkg/config"
+	"github.com/bigxxby/effective-mobile-test/pkg/migrations"
+)
+
+var errUsage = errors.New("invalid arguments, run with --help for usage")
+
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	cfg, err := config.LoadDB(flags, args)
+	if err != nil {
+		return err
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	db, migrator, err := openMigrator(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			n, err = strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid number of migrations %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(n)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Reverted %d migration(s)\n", reverted)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		printStatus(statuses)
+	case "redo":
+		return migrator.Redo()
+	default:
+		return errUsage
+	}
+	return nil
+}
+
+func runSeed(args []string) error {
+	cfg, err := config.LoadDB(flag.NewFlagSet("seed", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	db, migrator, err := openMigrator(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return migrator.Seed()
+}
+
+func openMigrator(cfg config.Config) (*sql.DB, migrations.Migrator, error) {
+	if err := internal.SetupLogger(cfg.Log); err != nil {
+		return nil, migrations.Migrator{}, err
+	}
+	db, err := internal.OpenDB(context.Background(), cfg.DB)
+	if err != nil {
+		return nil, migrations.Migrator{}, err
+	}
+	migrator, err := migrations.New(db)
+	if err != nil {
+		db.Close()
+		return nil, migrations.Migrator{}, err
+	}
+	return db, migrator, nil
+}
+
+func printStatus(statuses []migrations.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", ""
+		if status.Applied {
+			state = "applied"
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		if status.ChecksumMismatch {
+			state = "modified"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	w.Flush()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal"
+	"github.com/bigxxby/effective-mobile-test/internal/auth"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/bigxxby/effective-mobile-test/internal/repository"
+	"github.com/bigxxby/effective-mobile-test/internal/service"
+	config "github.com/bigxxby/effective-mobile-test/pkg/config"
+)
+
+// runToken выпускает токен для пользователя напрямую через базу.
+// Нужен, чтобы получить первый токен администратора, когда других ещё нет.
+func runToken(args []string) error {
+	flags := flag.NewFlagSet("token", flag.ContinueOnError)
+	userID := flags.Int("user", 0, "user ID")
+	role := flags.String("role", "", "set the user's role (admin, manager or employee) before issuing the token")
+	ttl := flags.Duration("ttl", 0, "token lifetime (default AUTH_TOKEN_TTL)")
+	cfg, err := config.Load(flags, args)
+	if err != nil {
+		return err
+	}
+	if *userID <= 0 {
+		return errUsage
+	}
+
+	if err := internal.SetupLogger(cfg.Log); err != nil {
+		return err
+	}
+	authenticator, err := internal.NewAuthenticator(cfg.Auth)
+	if err != nil {
+		return err
+	}
+	if authenticator == nil {
+		return errors.New("authentication is disabled (AUTH_ENABLED=false)")
+	}
+
+	db, err := internal.OpenDB(context.Background(), cfg.DB)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	repo := repository.New(db)
+	s := service.New(&repo, nil)
+	ctx := context.Background()
+
+	// через сервис: роль проверяется до записи, удалённый пользователь не найден
+	user, err := s.GetUser(ctx, *userID)
+	if err != nil {
+		return err
+	}
+	if *role != "" && *role != user.Role {
+		user, err = s.UpdateUserRole(ctx, user.ID, models.UserRoleData{Role: *role, ManagerID: user.ManagerID})
+		if err != nil {
+			return err
+		}
+	}
+
+	token, expiresAt, err := authenticator.IssueToken(auth.Principal{UserID: user.ID, Role: user.Role}, *ttl)
+	if err != nil {
+		return err
+	}
+	fmt.Println(token)
+	fmt.Printf("# user %d, role %s, expires %s\n", user.ID, user.Role, expiresAt.Format(time.RFC3339))
+	return nil
+}
@@ -15,256 +15,446 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/tasks": {
+        "/api/active": {
             "get": {
-                "description": "Retrieves all tasks.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Team-wide view of everyone with a running timer, oldest first, for dashboards.\nA manager sees only the timers of their own team.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Get running timers of all users.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only timers of this task",
+                        "name": "task_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only timers of tasks in this project",
+                        "name": "project_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get all tasks.",
                 "responses": {
                     "200": {
-                        "description": "Successful response with tasks",
+                        "description": "Running timers",
                         "schema": {
-                            "$ref": "#/definitions/models.ResponseTasksList"
+                            "$ref": "#/definitions/models.ResponseActiveTimers"
                         }
                     },
-                    "404": {
-                        "description": "Tasks not found",
+                    "400": {
+                        "description": "Invalid task or project filter",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/users": {
+        "/api/audit": {
             "get": {
-                "description": "Retrieves a list of users based on optional filters, paginated results, and sorting criteria.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists changes newest first: who (actor_id, null for changes made by the service itself), what (action, entity_type, entity_id)\nand the changed fields before and after. Pages are selected by page number or by cursor; the Link header points to the neighbouring pages. Admin only.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
-                "summary": "Get users with optional filtering, pagination, and sorting.",
+                "summary": "Get the audit log.",
                 "parameters": [
                     {
+                        "enum": [
+                            "user",
+                            "task",
+                            "project",
+                            "time_entry",
+                            "pause"
+                        ],
                         "type": "string",
-                        "description": "Passport number to filter users",
-                        "name": "passport_number",
+                        "description": "Entity type",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entity ID",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the user who made the change",
+                        "name": "actor_id",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Surname to filter users",
-                        "name": "surname",
+                        "description": "Action, e.g. user.update or timer.start",
+                        "name": "action",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Name to filter users",
-                        "name": "name",
+                        "description": "Only events at or after this time, RFC 3339 or YYYY-MM-DD",
+                        "name": "from",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Page number for pagination (default 1)",
-                        "name": "page",
+                        "type": "string",
+                        "description": "Only events before this time, RFC 3339 or YYYY-MM-DD",
+                        "name": "to",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Number of users per page (default 10)",
-                        "name": "page_size",
+                        "description": "Page number for offset pagination (default 1)",
+                        "name": "page",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "Field to sort by (default 'id')",
-                        "name": "sort_by",
+                        "type": "integer",
+                        "description": "Number of events per page (default 50, max 200)",
+                        "name": "page_size",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Sort order, either 'asc' or 'desc' (default 'asc')",
-                        "name": "sort_order",
+                        "description": "Cursor from next_cursor or prev_cursor of another page, replaces page",
+                        "name": "cursor",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successful response with list of users",
+                        "description": "Audit events",
                         "schema": {
-                            "$ref": "#/definitions/models.ResponseUsersList"
+                            "$ref": "#/definitions/models.ResponseAuditEvents"
                         }
                     },
-                    "404": {
-                        "description": "Users not found",
+                    "400": {
+                        "description": "Invalid filter, period or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/auth/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the user ID and role from the access token.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Get the current user.",
+                "responses": {
+                    "200": {
+                        "description": "Current user",
+                        "schema": {
+                            "$ref": "#/definitions/models.Me"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/token": {
             "post": {
-                "description": "Creates a new user with the provided data.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issues a signed JWT for the user with the user's current role. The ttl may not exceed AUTH_MAX_TOKEN_TTL. Admin only.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
-                "summary": "Create a new user.",
+                "summary": "Issue an access token.",
                 "parameters": [
                     {
-                        "description": "User data to create",
+                        "description": "Token request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UserData"
+                            "$ref": "#/definitions/models.TokenRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "User created successfully",
+                        "description": "Issued token",
                         "schema": {
-                            "$ref": "#/definitions/models.OKresponse"
+                            "$ref": "#/definitions/models.TokenResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body or user already exists",
+                        "description": "Invalid request body, ttl or ttl above the maximum",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Authentication is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/users/{id}": {
+        "/api/projects": {
             "get": {
-                "description": "Retrieves a user by their ID.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a paginated list of projects ordered by ID.\nPages are selected by page number or by cursor; the Link header points to the neighbouring pages.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
-                "summary": "Get a user by ID.",
+                "summary": "Get projects.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Page number for offset pagination (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of projects per page (default 10, max 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_cursor or prev_cursor of another page, replaces page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successful response with user details",
+                        "description": "Successful response with projects",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.ResponseProjectsList"
                         }
                     },
                     "400": {
-                        "description": "Invalid user ID",
+                        "description": "Invalid cursor",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "401": {
+                        "description": "Missing or invalid token",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
             },
-            "put": {
-                "description": "Updates a user with the provided data.",
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a new project.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
-                "summary": "Update a user by ID.",
+                "summary": "Create a new project.",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Updated user data",
+                        "description": "Project data to create",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UserUpdate"
+                            "$ref": "#/definitions/models.ProjectData"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "User updated successfully",
+                    "201": {
+                        "description": "Project created successfully",
                         "schema": {
                             "$ref": "#/definitions/models.OKresponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid user ID or request body",
+                        "description": "Invalid request body or name",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Deletes a user by their ID.",
+            }
+        },
+        "/api/projects/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a project by its ID.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
-                "summary": "Delete a user by ID.",
+                "summary": "Get a project by ID.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Project ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -272,166 +462,3074 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "User deleted successfully",
+                        "description": "Successful response with project details",
                         "schema": {
-                            "$ref": "#/definitions/models.OKresponse"
+                            "$ref": "#/definitions/models.ResponseProject"
                         }
                     },
                     "400": {
-                        "description": "Invalid user ID",
+                        "description": "Invalid project ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "404": {
-                        "description": "User not found",
+                        "description": "Project not found",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/api/users/{id}/tasks/{taskId}/start": {
-            "post": {
-                "description": "Starts a task for a user by their IDs.",
-                "produces": [
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the name and description of a project.",
+                "consumes": [
                     "application/json"
                 ],
-                "summary": "Start a task for a user by ID and task ID.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Update a project by ID.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Project ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Task ID",
-                        "name": "taskId",
-                        "in": "path",
-                        "required": true
+                        "description": "Project data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProjectData"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Task started successfully",
+                        "description": "Project updated successfully",
                         "schema": {
                             "$ref": "#/definitions/models.OKresponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid user ID or task ID",
+                        "description": "Invalid project ID or request body",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "404": {
-                        "description": "User or task not found",
+                        "description": "Project not found",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/api/users/{id}/tasks/{taskId}/stop": {
-            "post": {
-                "description": "Ends a task for a user by their IDs.",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes a project that has no tasks.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
-                "summary": "End a task for a user by ID and task ID.",
+                "summary": "Delete a project by ID.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Project ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Task ID",
-                        "name": "taskId",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Task ended successfully",
+                        "description": "Project deleted successfully",
                         "schema": {
                             "$ref": "#/definitions/models.OKresponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid user ID or task ID",
+                        "description": "Invalid project ID",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
-                    "404": {
-                        "description": "User or task not found",
+                    "401": {
+                        "description": "Missing or invalid token",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Project not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Project has tasks",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/projects/{id}/members": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves users allowed to start tasks of the project.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Get project members.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response with members",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseUsersList"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid project ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Project not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Allows a user to start tasks of the project.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Add a project member.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User to add",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProjectMemberData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Member added successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.OKresponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid project ID or request body",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Project or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "User is already a member",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/projects/{id}/members/{userId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes a user's access to start tasks of the project.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Remove a project member.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Member removed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.OKresponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid project ID or user ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Project or member not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/projects/{id}/workloads": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sums logged time across every task and user of the project. Without dates the report covers all time.\nFor a manager the report covers only the time of their own team.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Get project workloads.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date in YYYY-MM-DD format",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date in YYYY-MM-DD format",
+                        "name": "end_date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Project workload report",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProjectWorkloadReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid project ID or dates",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Project not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tasks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a list of tasks. Archived tasks are hidden unless include_archived is true.\nPages are selected by page number or by cursor; the Link header points to the neighbouring pages.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Get tasks with optional filtering, pagination, and sorting.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task name to filter tasks",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "todo",
+                            "in_progress",
+                            "done"
+                        ],
+                        "type": "string",
+                        "description": "Task status to filter tasks",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Project ID to filter tasks",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include archived tasks (default false)",
+                        "name": "include_archived",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for offset pagination (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of tasks per page (default 10, max 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_cursor or prev_cursor of another page, replaces page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to sort by: id, name, status, created_at (default 'id')",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order, either 'asc' or 'desc' (default 'asc')",
+                        "name": "sort_order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response with tasks",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseTasksList"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status, project filter or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Tasks not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a new task. Status defaults to 'todo'.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Create a new task.",
+                "parameters": [
+                    {
+                        "description": "Task data to create",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Task created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.OKresponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, name or status",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tasks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a task by its ID.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Get a task by ID.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response with task details",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseTask"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces all editable fields of a task.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Replace a task by ID.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Task data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.OKresponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID or request body",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes a task without time logs. Tasks with logged time should be archived instead.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Delete a task by ID.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.OKresponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Task has time logs",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates only the provided fields of a task. Set archived to true to archive a task.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Partially update a task by ID.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskPatch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated task",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseTask"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID or request body",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a list of users based on optional filters, paginated results, and sorting criteria.\nPages are selected by page number or by cursor; the Link header points to the neighbouring pages.\nAdmins see all users, managers only the users they manage.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Get users with optional filtering, pagination, and sorting.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Passport number to filter users (exact match)",
+                        "name": "passport_number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Surname to filter users (exact match)",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name to filter users (exact match)",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by field and operation, filter[field][op]=value: passport_number, surname and name with eq (default), ieq, prefix, iprefix, contains, icontains; search for full-text search by name and address; id with eq, in (comma-separated), gt, gte, lt, lte; created_at and updated_at with gte, lt (RFC 3339 or YYYY-MM-DD)",
+                        "name": "filter[surname][icontains]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include soft-deleted users (default false)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for offset pagination (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of users per page (default 10, max 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_cursor or prev_cursor of another page, replaces page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to sort by (default 'id')",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order, either 'asc' or 'desc' (default 'asc')",
+                        "name": "sort_order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response with list of users",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseUsersList"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Users not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a new user with the provided passport number. Surname, name, patronymic and address are filled from the people info API when it is configured.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Create a new user.",
+                "parameters": [
+                    {
+                        "description": "User data to create",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.OKresponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or passport number",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "User already exists",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a user by their ID.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Get a user by ID.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response with user details",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates a user with the provided data. Surname and name are replaced; patronymic and address\nare replaced only when given and not empty, so values filled in by enrichment are kept otherwise.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Update a user by ID.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated user data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.OKresponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID or request body",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Soft-deletes a user: the user is hidden from lists and cannot track time, running timers are stopped,\ntime entries are kept. Use restore to undo or purge to delete permanently. Admin only.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Delete a user by ID.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.OKresponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/active": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the user's open time entries, oldest first, with time elapsed since start and worked time without breaks.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Get running timers of a user.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Running timers",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseActiveTimers"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/purge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Permanently deletes a soft-deleted user together with their time entries. Admin only.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Permanently delete a user.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User purged",
+                        "schema": {
+                            "$ref": "#/definitions/models.OKresponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "User is not deleted",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restores a soft-deleted user. Admin only.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Restore a deleted user.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Restored user",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "User is not deleted",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Assigns a role (admin, manager or employee) and an optional manager to the user. The manager must have the manager or admin role. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Set a user's role and manager.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserRoleData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated user",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID, request body, role or manager",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/tasks/{taskId}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Starts a break inside the running session. The break is not counted in workloads until the timer is resumed or stopped.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Pause a running task timer.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Started break",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponsePause"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID or task ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Task not started or already paused",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/tasks/{taskId}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ends the current break of the running session.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Resume a paused task timer.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ended break",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponsePause"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID or task ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Task not started or not paused",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/tasks/{taskId}/start": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Starts a task for a user by their IDs. If the user already has a running timer for another task,\nthe TIMER_START_POLICY setting decides: parallel starts one more timer, reject answers 409\nanother_timer_running, switch stops the running timers and lists them in stopped.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Start a task for a user by ID and task ID.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Started time entry and timers stopped by the switch policy",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseTimerStart"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID or task ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not a member of the task project",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User or task not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Task is archived or already started, or another timer is running",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/tasks/{taskId}/stop": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ends a task for a user by their IDs. Stopping a paused timer also ends the current break.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "End a task for a user by ID and task ID.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task ended successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.OKresponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID or task ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User or task not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Task not started",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/time-entries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves time entries overlapping the optional period, newest first. Running timers have a null end_time.\nPages are selected by page number or by cursor; the Link header points to the neighbouring pages.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Get time entries of a user.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date in YYYY-MM-DD format",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date in YYYY-MM-DD format, inclusive",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for offset pagination (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of entries per page (default 10, max 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_cursor or prev_cursor of another page, replaces page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response with time entries",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseTimeEntriesList"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID, dates or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Back-fills work with explicit start and end times. Entries may not be in the future or overlap other entries of the user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Create a time entry for a user.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Time entry data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TimeEntryData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Time entry created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.OKresponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or time range",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not a member of the task project",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User or task not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Entry overlaps another entry or task is archived",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/time-entries/{entryId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a single time entry.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Get a time entry of a user.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Time entry ID",
+                        "name": "entryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response with time entry",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseTimeEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID or entry ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Time entry not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces task, start, end and note of an entry. Can be used to close a forgotten running timer.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Update a time entry of a user.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Time entry ID",
+                        "name": "entryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Time entry data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TimeEntryData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Time entry updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.OKresponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or time range",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not a member of the task project",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Time entry or task not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Entry overlaps another entry or task is archived",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes a time entry.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Delete a time entry of a user.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Time entry ID",
+                        "name": "entryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Time entry deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.OKresponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID or entry ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Time entry not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/time-entries/{entryId}/segments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Splits the session into work and pause segments in chronological order. The last segment of a running timer has no end_time and is counted up to now.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Get the segment timeline of a time entry.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Time entry ID",
+                        "name": "entryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Segments with worked and paused totals",
+                        "schema": {
+                            "$ref": "#/definitions/models.Timeline"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID or entry ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Time entry not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/workloads": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sums logged time per task, sorted from most to least, with a grand total. Running timers and entries crossing the period boundaries are counted only within the period. Breaks recorded with pause and resume are not counted.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Get user workloads for a period.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date in YYYY-MM-DD format",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date in YYYY-MM-DD format, inclusive",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User workload report",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserWorkloadReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID or dates",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Returns 200 while the process is running. Does not check dependencies.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Status"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Returns 200 when the database answers and its migrations are at the version built into the binary, 503 otherwise.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Status"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Status"
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "description": "Returns the module version, Go version and VCS revision the binary was built from.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Build information.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.BuildInfo"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.ErrorResponse": {
+        }
+    },
+    "definitions": {
+        "apperr.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "task_not_found"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "task not found"
+                },
+                "details": {
+                    "type": "object"
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/tasks/42"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
+        "health.BuildInfo": {
+            "type": "object",
+            "properties": {
+                "go_version": {
+                    "type": "string"
+                },
+                "modified": {
+                    "type": "boolean"
+                },
+                "module": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "string"
+                },
+                "revision_time": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "health.Status": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "models.ActiveTimer": {
+            "type": "object",
+            "properties": {
+                "elapsed_seconds": {
+                    "type": "integer"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "task_name": {
+                    "type": "string"
+                },
+                "time_entry": {
+                    "$ref": "#/definitions/models.TimeEntry"
+                },
+                "user_name": {
+                    "type": "string"
+                },
+                "user_surname": {
+                    "type": "string"
+                },
+                "worked_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "user.update"
+                },
+                "actor_id": {
+                    "type": "integer"
+                },
+                "actor_role": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "integer"
+                },
+                "entity_type": {
+                    "type": "string",
+                    "example": "user"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Me": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OKresponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Pause": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "time_entry_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Project": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProjectData": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProjectMemberData": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProjectTaskWorkload": {
+            "type": "object",
+            "properties": {
+                "task_id": {
+                    "type": "integer"
+                },
+                "task_name": {
+                    "type": "string"
+                },
+                "total_hours": {
+                    "type": "integer"
+                },
+                "total_minutes": {
+                    "type": "integer"
+                },
+                "total_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProjectUserWorkload": {
+            "type": "object",
+            "properties": {
+                "total_hours": {
+                    "type": "integer"
+                },
+                "total_minutes": {
+                    "type": "integer"
+                },
+                "total_seconds": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProjectWorkloadReport": {
+            "type": "object",
+            "properties": {
+                "project_id": {
+                    "type": "integer"
+                },
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProjectTaskWorkload"
+                    }
+                },
+                "total_hours": {
+                    "type": "integer"
+                },
+                "total_minutes": {
+                    "type": "integer"
+                },
+                "total_seconds": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProjectUserWorkload"
+                    }
+                }
+            }
+        },
+        "models.ResponseActiveTimers": {
+            "type": "object",
+            "properties": {
+                "active_timers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ActiveTimer"
+                    }
+                }
+            }
+        },
+        "models.ResponseAuditEvents": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditEvent"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ResponsePause": {
             "type": "object",
             "properties": {
-                "error": {
+                "message": {
                     "type": "string"
+                },
+                "pause": {
+                    "$ref": "#/definitions/models.Pause"
                 }
             }
         },
-        "models.OKresponse": {
+        "models.ResponseProject": {
             "type": "object",
             "properties": {
-                "message": {
+                "project": {
+                    "$ref": "#/definitions/models.Project"
+                }
+            }
+        },
+        "models.ResponseProjectsList": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "prev_cursor": {
                     "type": "string"
+                },
+                "projects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Project"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ResponseTask": {
+            "type": "object",
+            "properties": {
+                "task": {
+                    "$ref": "#/definitions/models.Task"
                 }
             }
         },
         "models.ResponseTasksList": {
             "type": "object",
             "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
                 "tasks": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/models.Task"
                     }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ResponseTimeEntriesList": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "time_entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TimeEntry"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ResponseTimeEntry": {
+            "type": "object",
+            "properties": {
+                "time_entry": {
+                    "$ref": "#/definitions/models.TimeEntry"
+                }
+            }
+        },
+        "models.ResponseTimerStart": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "stopped": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TimeEntry"
+                    }
+                },
+                "time_entry": {
+                    "$ref": "#/definitions/models.TimeEntry"
+                }
+            }
+        },
+        "models.ResponseUser": {
+            "type": "object",
+            "properties": {
+                "user": {
+                    "$ref": "#/definitions/models.User"
                 }
             }
         },
         "models.ResponseUsersList": {
             "type": "object",
             "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                },
                 "users": {
                     "type": "array",
                     "items": {
@@ -440,14 +3538,206 @@ const docTemplate = `{
                 }
             }
         },
+        "models.Segment": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "work",
+                        "pause"
+                    ]
+                },
+                "seconds": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
         "models.Task": {
             "type": "object",
             "properties": {
+                "archived": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "name": {
                     "type": "string"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TaskData": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "archived": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "todo",
+                        "in_progress",
+                        "done"
+                    ]
+                }
+            }
+        },
+        "models.TaskPatch": {
+            "type": "object",
+            "properties": {
+                "archived": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "todo",
+                        "in_progress",
+                        "done"
+                    ]
+                }
+            }
+        },
+        "models.TimeEntry": {
+            "type": "object",
+            "properties": {
+                "auto_closed": {
+                    "type": "boolean"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "needs_review": {
+                    "type": "boolean"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "task_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TimeEntryData": {
+            "type": "object",
+            "required": [
+                "end_time",
+                "start_time",
+                "task_id"
+            ],
+            "properties": {
+                "end_time": {
+                    "type": "string",
+                    "example": "2024-07-01T12:30:00Z"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "2024-07-01T10:00:00Z"
+                },
+                "task_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Timeline": {
+            "type": "object",
+            "properties": {
+                "paused_seconds": {
+                    "type": "integer"
+                },
+                "segments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Segment"
+                    }
+                },
+                "time_entry": {
+                    "$ref": "#/definitions/models.TimeEntry"
+                },
+                "worked_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TokenRequest": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "ttl": {
+                    "type": "string",
+                    "example": "24h"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
                 }
             }
         },
@@ -458,17 +3748,38 @@ const docTemplate = `{
                 "surname"
             ],
             "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "manager_id": {
+                    "type": "integer"
+                },
                 "name": {
                     "type": "string"
                 },
                 "passport_number": {
                     "type": "string"
                 },
+                "patronymic": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
                 "surname": {
                     "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
@@ -479,7 +3790,27 @@ const docTemplate = `{
             ],
             "properties": {
                 "passport_number": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "1234 567890"
+                }
+            }
+        },
+        "models.UserRoleData": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "manager_id": {
+                    "type": "integer"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "manager",
+                        "employee"
+                    ]
                 }
             }
         },
@@ -490,25 +3821,88 @@ const docTemplate = `{
                 "surname"
             ],
             "properties": {
+                "address": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
+                "patronymic": {
+                    "type": "string"
+                },
                 "surname": {
                     "type": "string"
                 }
             }
+        },
+        "models.UserWorkload": {
+            "type": "object",
+            "properties": {
+                "task_id": {
+                    "type": "integer"
+                },
+                "task_name": {
+                    "type": "string"
+                },
+                "total_hours": {
+                    "type": "integer"
+                },
+                "total_minutes": {
+                    "type": "integer"
+                },
+                "total_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.UserWorkloadReport": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "total_hours": {
+                    "type": "integer"
+                },
+                "total_minutes": {
+                    "type": "integer"
+                },
+                "total_seconds": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "user_workloads": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserWorkload"
+                    }
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Type \"Bearer\" followed by a space and the token from POST /api/auth/token or the \"token\" command.",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
-	Version:          "",
+	Version:          "1.0",
 	Host:             "",
-	BasePath:         "",
+	BasePath:         "/",
 	Schemes:          []string{},
-	Title:            "",
-	Description:      "",
+	Title:            "Effective Mobile Time Tracker API",
+	Description:      "Users, tasks, projects and time tracking.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -0,0 +1,141 @@
+package apperr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// Error — ошибка API: машинный код, HTTP-статус, сообщение и подробности для клиента.
+// Ошибки с одинаковым Code равны для errors.Is, поэтому копии из With* и Wrap
+// совпадают с исходной ошибкой.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Details map[string]any
+	// Err — исходная причина, в ответ не попадает
+	Err error
+}
+
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap возвращает копию ошибки с причиной err
+func (e *Error) Wrap(err error) *Error {
+	c := e.clone()
+	c.Err = err
+	return c
+}
+
+// WithStatus возвращает копию ошибки с другим HTTP-статусом, когда он зависит от места ошибки
+func (e *Error) WithStatus(status int) *Error {
+	c := e.clone()
+	c.Status = status
+	return c
+}
+
+// WithMessage возвращает копию ошибки с уточнённым сообщением
+func (e *Error) WithMessage(format string, args ...any) *Error {
+	c := e.clone()
+	c.Message = fmt.Sprintf(format, args...)
+	return c
+}
+
+// WithDetail возвращает копию ошибки с дополнительным полем details
+func (e *Error) WithDetail(key string, value any) *Error {
+	c := e.clone()
+	c.Details = maps.Clone(e.Details)
+	if c.Details == nil {
+		c.Details = make(map[string]any)
+	}
+	c.Details[key] = value
+	return c
+}
+
+func (e *Error) clone() *Error {
+	c := *e
+	return &c
+}
+
+// Общие ошибки, не относящиеся к конкретной сущности
+var (
+	ErrInvalidBody      = New(400, "invalid_body", "invalid request body")
+	ErrInvalidParameter = New(400, "invalid_parameter", "invalid parameter")
+	ErrUnauthorized     = New(401, "unauthorized", "missing or invalid token")
+	ErrForbidden        = New(403, "forbidden", "forbidden")
+	ErrNotFound         = New(404, "not_found", "resource not found")
+	ErrCanceled         = New(StatusClientClosedRequest, "canceled", "request canceled by client")
+	ErrInternal         = New(500, "internal", "internal server error")
+	ErrUnavailable      = New(503, "unavailable", "service unavailable")
+	ErrTimeout          = New(504, "timeout", "operation timed out")
+)
+
+// StatusClientClosedRequest — нестандартный код nginx для запросов, брошенных клиентом
+const StatusClientClosedRequest = 499
+
+// InvalidParameter — параметр пути или запроса name не прошёл проверку
+func InvalidParameter(name string) *Error {
+	return ErrInvalidParameter.WithMessage("invalid parameter %s", name).WithDetail("parameter", name)
+}
+
+// InvalidField — поле name тела запроса не прошло проверку
+func InvalidField(name string) *Error {
+	return ErrInvalidBody.WithMessage("invalid field %s", name).WithDetail("field", name)
+}
+
+// InvalidBody — тело запроса не разобрано или не прошло проверку binding
+func InvalidBody(err error) *Error {
+	return ErrInvalidBody.Wrap(err).WithDetail("reason", err.Error())
+}
+
+// mapping сопоставляет ошибки стандартной библиотеки с ошибками API
+var mapping = []struct {
+	target error
+	err    *Error
+}{
+	{sql.ErrNoRows, ErrNotFound},
+	{context.DeadlineExceeded, ErrTimeout},
+	{context.Canceled, ErrCanceled},
+}
+
+// From приводит любую ошибку к *Error. Неизвестные ошибки становятся ErrInternal с исходной причиной,
+// а ошибки при истёкшем или отменённом requestCtx — ErrTimeout и ErrCanceled:
+// драйвер базы возвращает на отмену свои ошибки, а не ошибку контекста.
+func From(requestCtx context.Context, err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	for _, m := range mapping {
+		if errors.Is(err, m.target) {
+			return m.err.Wrap(err)
+		}
+	}
+	switch {
+	case errors.Is(requestCtx.Err(), context.DeadlineExceeded):
+		return ErrTimeout.Wrap(err)
+	case errors.Is(requestCtx.Err(), context.Canceled):
+		return ErrCanceled.Wrap(err)
+	}
+	return ErrInternal.Wrap(err)
+}
@@ -0,0 +1,137 @@
+package apperr
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIs(t *testing.T) {
+	errTaskNotFound := New(404, "task_not_found", "task not found")
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"same", errTaskNotFound},
+		{"wrapped", fmt.Errorf("get task: %w", errTaskNotFound)},
+		{"with status", errTaskNotFound.WithStatus(400)},
+		{"with detail", errTaskNotFound.WithDetail("task_id", 42)},
+		{"with cause", errTaskNotFound.Wrap(sql.ErrNoRows)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, errTaskNotFound) {
+				t.Errorf("errors.Is(%v, errTaskNotFound) = false", tt.err)
+			}
+		})
+	}
+
+	if errors.Is(errTaskNotFound, ErrNotFound) {
+		t.Error("errors with different codes must not match")
+	}
+	if !errors.Is(errTaskNotFound.Wrap(sql.ErrNoRows), sql.ErrNoRows) {
+		t.Error("cause is not reachable through errors.Is")
+	}
+}
+
+func TestWithDetailDoesNotShareDetails(t *testing.T) {
+	base := New(400, "invalid", "invalid").WithDetail("a", 1)
+	_ = base.WithDetail("b", 2)
+	if _, ok := base.Details["b"]; ok {
+		t.Error("WithDetail modified the original error")
+	}
+}
+
+func TestFrom(t *testing.T) {
+	expired, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"typed", context.Background(), fmt.Errorf("wrapped: %w", ErrForbidden), 403, "forbidden"},
+		{"no rows", context.Background(), sql.ErrNoRows, 404, "not_found"},
+		{"deadline", context.Background(), context.DeadlineExceeded, 504, "timeout"},
+		{"driver error after deadline", expired, errors.New("pq: canceling statement due to user request"), 504, "timeout"},
+		{"driver error after cancel", canceled, errors.New("driver: bad connection"), 499, "canceled"},
+		{"unknown", context.Background(), errors.New("boom"), 500, "internal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := From(tt.ctx, tt.err)
+			if got.Status != tt.wantStatus || got.Code != tt.wantCode {
+				t.Errorf("From() = %d %s, want %d %s", got.Status, got.Code, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/tasks/:id", func(ctx *gin.Context) {
+		ctx.Error(New(404, "task_not_found", "task not found").WithDetail("task_id", 7))
+	})
+	router.GET("/internal", func(ctx *gin.Context) {
+		ctx.Error(errors.New("connection refused to 10.0.0.5"))
+	})
+	router.GET("/ok", func(ctx *gin.Context) {
+		ctx.Error(errors.New("already answered"))
+		ctx.JSON(200, gin.H{"ok": true})
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tasks/7", nil))
+	if rec.Code != 404 {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != ContentType {
+		t.Errorf("Content-Type = %q, want %q", got, ContentType)
+	}
+	var problem Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	want := Problem{Type: "about:blank", Title: "Not Found", Status: 404, Detail: "task not found", Instance: "/tasks/7", Code: "task_not_found"}
+	if problem.Type != want.Type || problem.Title != want.Title || problem.Status != want.Status ||
+		problem.Detail != want.Detail || problem.Instance != want.Instance || problem.Code != want.Code {
+		t.Errorf("problem = %+v, want %+v", problem, want)
+	}
+	if problem.Details["task_id"] != float64(7) {
+		t.Errorf("details = %v, want task_id 7", problem.Details)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/internal", nil))
+	if rec.Code != 500 {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Detail != "internal server error" {
+		t.Errorf("detail = %q, internal error text must not leak", problem.Detail)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	if rec.Code != 200 {
+		t.Errorf("status = %d, want 200 when the handler already answered", rec.Code)
+	}
+}
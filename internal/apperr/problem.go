@@ -0,0 +1,80 @@
+package apperr
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType ответов об ошибках по RFC 7807
+const ContentType = "application/problem+json"
+
+// Problem — тело ответа об ошибке по RFC 7807. Type всегда about:blank, поэтому Title —
+// текст HTTP-статуса; тип ошибки различается по code.
+type Problem struct {
+	Type     string         `json:"type" example:"about:blank"`
+	Title    string         `json:"title" example:"Not Found"`
+	Status   int            `json:"status" example:"404"`
+	Detail   string         `json:"detail,omitempty" example:"task not found"`
+	Instance string         `json:"instance,omitempty" example:"/api/tasks/42"`
+	Code     string         `json:"code" example:"task_not_found"`
+	Details  map[string]any `json:"details,omitempty" swaggertype:"object"`
+}
+
+func (e *Error) Problem(instance string) Problem {
+	title := http.StatusText(e.Status)
+	if e.Status == StatusClientClosedRequest {
+		title = "Client Closed Request"
+	}
+	return Problem{
+		Type:     "about:blank",
+		Title:    title,
+		Status:   e.Status,
+		Detail:   e.Message,
+		Instance: instance,
+		Code:     e.Code,
+		Details:  e.Details,
+	}
+}
+
+// Middleware отвечает problem+json на ошибку, которую обработчик передал через ctx.Error.
+// Это единственное место, где ошибки превращаются в HTTP-статусы.
+func Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+			return
+		}
+		Write(ctx, ctx.Errors.Last().Err)
+	}
+}
+
+// Abort прерывает цепочку обработчиков и сразу отвечает ошибкой; для middleware,
+// которые отклоняют запрос до обработчика
+func Abort(ctx *gin.Context, err error) {
+	ctx.Abort()
+	Write(ctx, err)
+}
+
+// Write логирует ошибку и пишет ответ. Серверные ошибки логируются с причиной,
+// клиентские попадают только в журнал доступа.
+func Write(ctx *gin.Context, err error) {
+	requestCtx := ctx.Request.Context()
+	e := From(requestCtx, err)
+
+	switch {
+	case e.Status == StatusClientClosedRequest:
+		slog.InfoContext(requestCtx, "request canceled by client", "error", err)
+		ctx.Status(e.Status)
+		return
+	case e.Status == 504:
+		slog.WarnContext(requestCtx, "request timed out", "error", err)
+	case e.Status >= 500:
+		slog.ErrorContext(requestCtx, "request failed", "error", err)
+	}
+
+	ctx.Header("Content-Type", ContentType)
+	ctx.JSON(e.Status, e.Problem(ctx.Request.URL.Path))
+}
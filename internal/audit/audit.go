@@ -0,0 +1,67 @@
+// Package audit передаёт автора изменения от HTTP-запроса до репозитория
+// и вычисляет разницу состояний сущности для журнала аудита.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+// Actor — пользователь, от имени которого выполняется изменение
+type Actor struct {
+	UserID int
+	Role   string
+}
+
+type actorKey struct{}
+
+// WithActor возвращает контекст, изменения в котором записываются от имени actor
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom возвращает автора изменения. false — изменение делает сам сервис
+// (фоновые задачи, остановка) или запрос без аутентификации.
+func ActorFrom(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
+
+// Diff оставляет в JSON-объектах before и after только поля, значения которых различаются.
+// Если одного из состояний нет (создание или удаление), другое возвращается целиком.
+func Diff(before, after []byte) (json.RawMessage, json.RawMessage, error) {
+	if before == nil || after == nil {
+		return before, after, nil
+	}
+	var prev, next map[string]json.RawMessage
+	if err := json.Unmarshal(before, &prev); err != nil {
+		return nil, nil, err
+	}
+	if err := json.Unmarshal(after, &next); err != nil {
+		return nil, nil, err
+	}
+	for key, value := range prev {
+		if nextValue, ok := next[key]; ok && bytes.Equal(compact(value), compact(nextValue)) {
+			delete(prev, key)
+			delete(next, key)
+		}
+	}
+	prevDiff, err := json.Marshal(prev)
+	if err != nil {
+		return nil, nil, err
+	}
+	nextDiff, err := json.Marshal(next)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prevDiff, nextDiff, nil
+}
+
+func compact(value json.RawMessage) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, value); err != nil {
+		return value
+	}
+	return buf.Bytes()
+}
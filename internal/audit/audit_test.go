@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name       string
+		before     string
+		after      string
+		wantBefore string
+		wantAfter  string
+	}{
+		{"create", "", `{"id": 1, "name": "Ivan"}`, "", `{"id": 1, "name": "Ivan"}`},
+		{"delete", `{"id": 1}`, "", `{"id": 1}`, ""},
+		{"update", `{"id": 1, "name": "Ivan", "role": "employee"}`, `{"id":1,"name":"Petr","role":"employee"}`, `{"name":"Ivan"}`, `{"name":"Petr"}`},
+		{"field added", `{"id": 1}`, `{"id": 1, "end_time": "2024-07-01T10:00:00"}`, `{}`, `{"end_time":"2024-07-01T10:00:00"}`},
+		{"no changes", `{"id": 1}`, `{"id": 1}`, `{}`, `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, after, err := Diff(bytesOrNil(tt.before), bytesOrNil(tt.after))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(before) != tt.wantBefore || string(after) != tt.wantAfter {
+				t.Errorf("Diff() = %s, %s, want %s, %s", before, after, tt.wantBefore, tt.wantAfter)
+			}
+		})
+	}
+
+	if _, _, err := Diff([]byte(`[1]`), []byte(`{}`)); err == nil {
+		t.Error("Diff of a non-object = nil error")
+	}
+}
+
+func bytesOrNil(s string) []byte {
+	if s == "" {
+		return nil
+	}
+	return []byte(s)
+}
+
+func TestActor(t *testing.T) {
+	if _, ok := ActorFrom(context.Background()); ok {
+		t.Error("ActorFrom(empty context) ok = true")
+	}
+	ctx := WithActor(context.Background(), Actor{UserID: 7, Role: "admin"})
+	if actor, ok := ActorFrom(ctx); !ok || actor.UserID != 7 || actor.Role != "admin" {
+		t.Errorf("ActorFrom() = %+v, %v", actor, ok)
+	}
+}
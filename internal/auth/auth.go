@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrNoKeys       = errors.New("auth: at least one HMAC key is required")
+	ErrInvalidToken = errors.New("auth: invalid token")
+	ErrTTLTooLong   = errors.New("auth: token lifetime exceeds the maximum")
+)
+
+const issuer = "effective-mobile-test"
+
+// Principal — пользователь, от имени которого выполняется запрос
+type Principal struct {
+	UserID int
+	Role   string
+}
+
+type Config struct {
+	// Keys — HMAC ключи по идентификатору (kid). Новые токены подписываются ActiveKeyID,
+	// остальные ключи принимаются при проверке, что позволяет менять ключи без простоя.
+	Keys        map[string][]byte
+	ActiveKeyID string
+	TokenTTL    time.Duration
+	// MaxTokenTTL — наибольший срок токена, который можно запросить при выпуске
+	MaxTokenTTL time.Duration
+}
+
+type Authenticator struct {
+	keys        map[string][]byte
+	activeKeyID string
+	tokenTTL    time.Duration
+	maxTokenTTL time.Duration
+}
+
+type claims struct {
+	Role string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+func New(cfg Config) (*Authenticator, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	if _, ok := cfg.Keys[cfg.ActiveKeyID]; !ok {
+		return nil, fmt.Errorf("auth: active key %q is not configured", cfg.ActiveKeyID)
+	}
+	if cfg.TokenTTL <= 0 {
+		cfg.TokenTTL = 24 * time.Hour
+	}
+	if cfg.MaxTokenTTL <= 0 {
+		cfg.MaxTokenTTL = max(cfg.TokenTTL, 7*24*time.Hour)
+	}
+	if cfg.TokenTTL > cfg.MaxTokenTTL {
+		return nil, fmt.Errorf("auth: token ttl %v exceeds the maximum %v", cfg.TokenTTL, cfg.MaxTokenTTL)
+	}
+	return &Authenticator{
+		keys:        cfg.Keys,
+		activeKeyID: cfg.ActiveKeyID,
+		tokenTTL:    cfg.TokenTTL,
+		maxTokenTTL: cfg.MaxTokenTTL,
+	}, nil
+}
+
+// placeholderSecret — секрет из прежних .env.example и docker-compose.yaml. Он опубликован,
+// поэтому токен с ним может выпустить кто угодно.
+const placeholderSecret = "change-me-to-a-random-secret-of-32-bytes"
+
+// ParseKeys разбирает строку вида "kid1:secret1,kid2:secret2".
+// Первый ключ становится активным, известный пример секрета отклоняется.
+func ParseKeys(value string) (map[string][]byte, string, error) {
+	keys := make(map[string][]byte)
+	var activeKeyID string
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kid, secret, ok := strings.Cut(pair, ":")
+		if !ok || kid == "" || len(secret) < 32 {
+			return nil, "", fmt.Errorf("auth: key %q must look like kid:secret with a secret of at least 32 bytes", kid)
+		}
+		if secret == placeholderSecret {
+			return nil, "", fmt.Errorf("auth: key %q uses the published example secret, generate a random one", kid)
+		}
+		keys[kid] = []byte(secret)
+		if activeKeyID == "" {
+			activeKeyID = kid
+		}
+	}
+	if len(keys) == 0 {
+		return nil, "", ErrNoKeys
+	}
+	return keys, activeKeyID, nil
+}
+
+// MaxTokenTTL — наибольший срок, который принимает IssueToken
+func (a *Authenticator) MaxTokenTTL() time.Duration {
+	return a.maxTokenTTL
+}
+
+// IssueToken выпускает HS256 токен для пользователя. ttl <= 0 означает срок по умолчанию,
+// срок больше MaxTokenTTL — ErrTTLTooLong.
+func (a *Authenticator) IssueToken(principal Principal, ttl time.Duration) (string, time.Time, error) {
+	if ttl <= 0 {
+		ttl = a.tokenTTL
+	}
+	if ttl > a.maxTokenTTL {
+		return "", time.Time{}, ErrTTLTooLong
+	}
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		Role: principal.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   strconv.Itoa(principal.UserID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+	token.Header["kid"] = a.activeKeyID
+
+	signed, err := token.SignedString(a.keys[a.activeKeyID])
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// ParseToken проверяет подпись и срок действия токена
+func (a *Authenticator) ParseToken(tokenString string) (Principal, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(tokenString, &c, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := a.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q", kid)
+		}
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	userID, err := strconv.Atoi(c.Subject)
+	if err != nil || userID <= 0 {
+		return Principal{}, ErrInvalidToken
+	}
+	return Principal{UserID: userID, Role: c.Role}, nil
+}
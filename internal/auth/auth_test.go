@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/audit"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	secret1 = "0123456789abcdef0123456789abcdef"
+	secret2 = "fedcba9876543210fedcba9876543210"
+)
+
+func newTestAuthenticator(t *testing.T, keys string) *Authenticator {
+	t.Helper()
+	parsed, active, err := ParseKeys(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := New(Config{Keys: parsed, ActiveKeyID: active, TokenTTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestParseKeys(t *testing.T) {
+	keys, active, err := ParseKeys(" k2:" + secret2 + ", k1:" + secret1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active != "k2" || len(keys) != 2 || string(keys["k1"]) != secret1 {
+		t.Errorf("keys = %v, active = %q", keys, active)
+	}
+
+	for _, value := range []string{"", "k1", "k1:short", ":" + secret1, "k1:change-me-to-a-random-secret-of-32-bytes"} {
+		if _, _, err := ParseKeys(value); err == nil {
+			t.Errorf("ParseKeys(%q) err = nil, want error", value)
+		}
+	}
+}
+
+func TestTokenRoundTrip(t *testing.T) {
+	a := newTestAuthenticator(t, "k1:"+secret1)
+
+	token, expiresAt, err := a.IssueToken(Principal{UserID: 7, Role: models.RoleManager}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Until(expiresAt); d <= 59*time.Minute || d > time.Hour {
+		t.Errorf("expires in %v, want about 1h", d)
+	}
+
+	principal, err := a.ParseToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal != (Principal{UserID: 7, Role: models.RoleManager}) {
+		t.Errorf("principal = %+v", principal)
+	}
+
+	// после ротации старые токены проверяются прежним ключом
+	rotated := newTestAuthenticator(t, "k2:"+secret2+",k1:"+secret1)
+	if _, err := rotated.ParseToken(token); err != nil {
+		t.Errorf("token signed with previous key: %v", err)
+	}
+
+	other := newTestAuthenticator(t, "k1:"+secret2)
+	if _, err := other.ParseToken(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("wrong secret err = %v, want %v", err, ErrInvalidToken)
+	}
+
+	expired, _, err := a.IssueToken(Principal{UserID: 7, Role: models.RoleManager}, time.Nanosecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	if _, err := a.ParseToken(expired); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expired token err = %v, want %v", err, ErrInvalidToken)
+	}
+
+	if _, err := a.ParseToken(token + "x"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("tampered token err = %v, want %v", err, ErrInvalidToken)
+	}
+
+	if _, _, err := a.IssueToken(Principal{UserID: 7, Role: models.RoleManager}, 8*24*time.Hour); !errors.Is(err, ErrTTLTooLong) {
+		t.Errorf("ttl above the maximum err = %v, want %v", err, ErrTTLTooLong)
+	}
+}
+
+// users: текущие роли пользователей и их руководители
+type users struct {
+	roles    map[int]string
+	managers map[int]int
+}
+
+func (u users) IsManagerOf(ctx context.Context, managerID, userID int) (bool, error) {
+	return u.managers[userID] == managerID, nil
+}
+
+func (u users) UserRole(ctx context.Context, userID int) (string, error) {
+	role, ok := u.roles[userID]
+	if !ok {
+		return "", models.ErrUserNotFound
+	}
+	return role, nil
+}
+
+func TestGuard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a := newTestAuthenticator(t, "k1:"+secret1)
+	// пользователь 2 в команде руководителя 10, 4 понижен до сотрудника, 5 удалён
+	guard := NewGuard(a, users{
+		roles:    map[int]string{1: models.RoleAdmin, 2: models.RoleEmployee, 4: models.RoleEmployee, 10: models.RoleManager},
+		managers: map[int]int{2: 10},
+	})
+
+	router := gin.New()
+	api := router.Group("/api", guard.Authenticate())
+	ok := func(ctx *gin.Context) { ctx.Status(200) }
+	api.GET("/users", guard.RequireRole(models.RoleAdmin, models.RoleManager), ok)
+	api.GET("/users/:id/workloads", guard.RequireUserAccess("id"), ok)
+
+	tokenFor := func(userID int, role string) string {
+		token, _, err := a.IssueToken(Principal{UserID: userID, Role: role}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + token
+	}
+	admin := tokenFor(1, models.RoleAdmin)
+	manager := tokenFor(10, models.RoleManager)
+	employee := tokenFor(2, models.RoleEmployee)
+	demoted := tokenFor(4, models.RoleManager)
+	deleted := tokenFor(5, models.RoleAdmin)
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		want   int
+	}{
+		{"no token", "/api/users", "", 401},
+		{"malformed header", "/api/users", "Token abc", 401},
+		{"invalid token", "/api/users", "Bearer abc", 401},
+		{"role changed since issue", "/api/users", demoted, 401},
+		{"user deleted since issue", "/api/users", deleted, 401},
+		{"admin lists users", "/api/users", admin, 200},
+		{"manager lists users", "/api/users", manager, 200},
+		{"employee cannot list users", "/api/users", employee, 403},
+		{"employee reads own workloads", "/api/users/2/workloads", employee, 200},
+		{"employee cannot read others", "/api/users/3/workloads", employee, 403},
+		{"manager reads team member", "/api/users/2/workloads", manager, 200},
+		{"manager reads self", "/api/users/10/workloads", manager, 200},
+		{"manager cannot read outside team", "/api/users/3/workloads", manager, 403},
+		{"admin reads anyone", "/api/users/3/workloads", admin, 200},
+		{"invalid user id", "/api/users/abc/workloads", admin, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.want, strings.TrimSpace(rec.Body.String()))
+			}
+		})
+	}
+}
+
+func TestAuthenticateSetsAuditActor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a := newTestAuthenticator(t, "k1:"+secret1)
+	guard := NewGuard(a, users{roles: map[int]string{5: models.RoleManager}})
+
+	var actor audit.Actor
+	var ok bool
+	router := gin.New()
+	router.GET("/api/users", guard.Authenticate(), func(ctx *gin.Context) {
+		actor, ok = audit.ActorFrom(ctx.Request.Context())
+	})
+
+	token, _, err := a.IssueToken(Principal{UserID: 5, Role: models.RoleManager}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if !ok || actor.UserID != 5 || actor.Role != models.RoleManager {
+		t.Errorf("audit actor = %+v, %v, want user 5 with role manager", actor, ok)
+	}
+}
+
+func TestGuardDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	guard := NewGuard(nil, nil)
+
+	router := gin.New()
+	router.GET("/api/users/:id", guard.Authenticate(), guard.RequireRole(models.RoleAdmin), guard.RequireUserAccess("id"), func(ctx *gin.Context) {
+		ctx.Status(200)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/5", nil))
+	if rec.Code != 200 {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bigxxby/effective-mobile-test/internal/apperr"
+	"github.com/bigxxby/effective-mobile-test/internal/audit"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const principalKey = "auth.principal"
+
+var (
+	errMissingToken = apperr.ErrUnauthorized.WithMessage("missing bearer token")
+	errInvalidToken = apperr.New(401, "invalid_token", "invalid or expired token")
+	errStaleToken   = errInvalidToken.WithMessage("token user was deleted or changed role")
+)
+
+// TeamChecker сообщает, является ли manager руководителем пользователя
+type TeamChecker interface {
+	IsManagerOf(ctx context.Context, managerID, userID int) (bool, error)
+}
+
+// Users — текущие данные пользователей. Роль в токене записана при выпуске, поэтому на каждом
+// запросе она сверяется с текущей: удаление пользователя или смена роли сразу отзывают его токены.
+type Users interface {
+	TeamChecker
+	// UserRole возвращает роль пользователя, models.ErrUserNotFound если его нет или он удалён
+	UserRole(ctx context.Context, userID int) (string, error)
+}
+
+// Guard строит gin middleware для аутентификации и проверки прав.
+// Если Authenticator равен nil, авторизация отключена и все проверки пропускают запрос.
+type Guard struct {
+	Authenticator *Authenticator
+	Users         Users
+}
+
+func NewGuard(authenticator *Authenticator, users Users) Guard {
+	return Guard{
+		Authenticator: authenticator,
+		Users:         users,
+	}
+}
+
+func (g Guard) Enabled() bool {
+	return g.Authenticator != nil
+}
+
+// Authenticate требует заголовок "Authorization: Bearer <token>" с токеном действующего пользователя
+// и его текущей ролью
+func (g Guard) Authenticate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !g.Enabled() {
+			ctx.Next()
+			return
+		}
+
+		header := ctx.GetHeader("Authorization")
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			ctx.Header("WWW-Authenticate", `Bearer realm="api"`)
+			apperr.Abort(ctx, errMissingToken)
+			return
+		}
+
+		principal, err := g.Authenticator.ParseToken(token)
+		if err != nil {
+			ctx.Header("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+			apperr.Abort(ctx, errInvalidToken)
+			return
+		}
+
+		role, err := g.Users.UserRole(ctx.Request.Context(), principal.UserID)
+		if err != nil && !errors.Is(err, models.ErrUserNotFound) {
+			apperr.Abort(ctx, fmt.Errorf("user check: %w", err))
+			return
+		}
+		if err != nil || role != principal.Role {
+			ctx.Header("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+			apperr.Abort(ctx, errStaleToken)
+			return
+		}
+
+		ctx.Set(principalKey, principal)
+		// автор изменений для журнала аудита, который пишет репозиторий
+		actor := audit.Actor{UserID: principal.UserID, Role: principal.Role}
+		ctx.Request = ctx.Request.WithContext(audit.WithActor(ctx.Request.Context(), actor))
+		ctx.Next()
+	}
+}
+
+// RequireRole пропускает только пользователей с одной из ролей
+func (g Guard) RequireRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !g.Enabled() {
+			ctx.Next()
+			return
+		}
+
+		principal, _ := PrincipalFrom(ctx)
+		for _, role := range roles {
+			if principal.Role == role {
+				ctx.Next()
+				return
+			}
+		}
+		apperr.Abort(ctx, apperr.ErrForbidden)
+	}
+}
+
+// RequireUserAccess проверяет доступ к пользователю из параметра пути param:
+// администратор видит всех, руководитель — себя и свою команду, сотрудник — только себя
+func (g Guard) RequireUserAccess(param string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !g.Enabled() {
+			ctx.Next()
+			return
+		}
+
+		userID, err := strconv.Atoi(ctx.Param(param))
+		if err != nil || userID <= 0 {
+			apperr.Abort(ctx, apperr.InvalidParameter(param))
+			return
+		}
+
+		principal, _ := PrincipalFrom(ctx)
+		switch {
+		case principal.Role == models.RoleAdmin, principal.UserID == userID:
+			ctx.Next()
+			return
+		case principal.Role == models.RoleManager:
+			isManager, err := g.Users.IsManagerOf(ctx.Request.Context(), principal.UserID, userID)
+			if err != nil {
+				apperr.Abort(ctx, fmt.Errorf("team check: %w", err))
+				return
+			}
+			if isManager {
+				ctx.Next()
+				return
+			}
+		}
+		apperr.Abort(ctx, apperr.ErrForbidden)
+	}
+}
+
+// PrincipalFrom возвращает пользователя, прошедшего аутентификацию
+func PrincipalFrom(ctx *gin.Context) (Principal, bool) {
+	value, ok := ctx.Get(principalKey)
+	if !ok {
+		return Principal{}, false
+	}
+	principal, ok := value.(Principal)
+	return principal, ok
+}
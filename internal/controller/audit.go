@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"github.com/bigxxby/effective-mobile-test/internal/apperr"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+var auditEntityTypes = map[string]bool{
+	models.AuditEntityUser:      true,
+	models.AuditEntityTask:      true,
+	models.AuditEntityProject:   true,
+	models.AuditEntityTimeEntry: true,
+	models.AuditEntityPause:     true,
+}
+
+// GetAuditEvents godoc
+// @Summary Get the audit log.
+// @Description Lists changes newest first: who (actor_id, null for changes made by the service itself), what (action, entity_type, entity_id)
+// @Description and the changed fields before and after. Pages are selected by page number or by cursor; the Link header points to the neighbouring pages. Admin only.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param entity_type query string false "Entity type" Enums(user, task, project, time_entry, pause)
+// @Param entity_id query int false "Entity ID"
+// @Param actor_id query int false "ID of the user who made the change"
+// @Param action query string false "Action, e.g. user.update or timer.start"
+// @Param from query string false "Only events at or after this time, RFC 3339 or YYYY-MM-DD"
+// @Param to query string false "Only events before this time, RFC 3339 or YYYY-MM-DD"
+// @Param page query int false "Page number for offset pagination (default 1)"
+// @Param page_size query int false "Number of events per page (default 50, max 200)"
+// @Param cursor query string false "Cursor from next_cursor or prev_cursor of another page, replaces page"
+// @Success 200 {object} models.ResponseAuditEvents "Audit events"
+// @Failure 400 {object} apperr.Problem "Invalid filter, period or cursor"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/audit [get]
+func (c *Controller) GetAuditEvents(ctx *gin.Context) {
+	var filter models.AuditFilter
+	var ok bool
+	filter.EntityType = ctx.Query("entity_type")
+	if filter.EntityType != "" && !auditEntityTypes[filter.EntityType] {
+		ctx.Error(apperr.InvalidParameter("entity_type"))
+		return
+	}
+	filter.Action = ctx.Query("action")
+	if filter.EntityID, ok = queryID(ctx, "entity_id"); !ok {
+		return
+	}
+	if filter.ActorID, ok = queryID(ctx, "actor_id"); !ok {
+		return
+	}
+	if filter.From, ok = queryTime(ctx, "from"); !ok {
+		return
+	}
+	if filter.To, ok = queryTime(ctx, "to"); !ok {
+		return
+	}
+
+	pagination, ok := queryPagination(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := c.Service.GetAuditEvents(ctx.Request.Context(), filter, pagination)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	setLinkHeader(ctx, page)
+	ctx.JSON(200, models.ResponseAuditEvents{Events: page.Items, PageInfo: page.PageInfo})
+}
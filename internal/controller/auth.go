@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/apperr"
+	"github.com/bigxxby/effective-mobile-test/internal/auth"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+var errAuthDisabled = apperr.ErrUnavailable.WithMessage("authentication is disabled")
+
+// UpdateUserRole godoc
+// @Summary Set a user's role and manager.
+// @Description Assigns a role (admin, manager or employee) and an optional manager to the user. The manager must have the manager or admin role. Admin only.
+// @Accept json
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body models.UserRoleData true "Role data"
+// @Success 200 {object} models.ResponseUser "Updated user"
+// @Failure 400 {object} apperr.Problem "Invalid user ID, request body, role or manager"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "User not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/users/{id}/role [put]
+func (c *Controller) UpdateUserRole(ctx *gin.Context) {
+	uid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var data models.UserRoleData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.Error(apperr.InvalidBody(err))
+		return
+	}
+
+	user, err := c.Service.UpdateUserRole(ctx.Request.Context(), uid, data)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, gin.H{"user": user})
+}
+
+// IssueToken godoc
+// @Summary Issue an access token.
+// @Description Issues a signed JWT for the user with the user's current role. The ttl may not exceed AUTH_MAX_TOKEN_TTL. Admin only.
+// @Accept json
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param request body models.TokenRequest true "Token request"
+// @Success 201 {object} models.TokenResponse "Issued token"
+// @Failure 400 {object} apperr.Problem "Invalid request body, ttl or ttl above the maximum"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "User not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Failure 503 {object} apperr.Problem "Authentication is disabled"
+// @Router /api/auth/token [post]
+func (c *Controller) IssueToken(ctx *gin.Context) {
+	if c.Auth == nil {
+		ctx.Error(errAuthDisabled)
+		return
+	}
+
+	var request models.TokenRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.Error(apperr.InvalidBody(err))
+		return
+	}
+	if request.UserID <= 0 {
+		ctx.Error(apperr.InvalidField("user_id"))
+		return
+	}
+	var ttl time.Duration
+	if request.TTL != "" {
+		parsed, err := time.ParseDuration(request.TTL)
+		if err != nil || parsed <= 0 {
+			ctx.Error(apperr.InvalidField("ttl").WithDetail("format", "24h"))
+			return
+		}
+		if parsed > c.Auth.MaxTokenTTL() {
+			ctx.Error(apperr.InvalidField("ttl").WithDetail("max", c.Auth.MaxTokenTTL().String()))
+			return
+		}
+		ttl = parsed
+	}
+
+	user, err := c.Service.GetUser(ctx.Request.Context(), request.UserID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	token, expiresAt, err := c.Auth.IssueToken(auth.Principal{UserID: user.ID, Role: user.Role}, ttl)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(201, models.TokenResponse{Token: token, TokenType: "Bearer", ExpiresAt: expiresAt})
+}
+
+// Me godoc
+// @Summary Get the current user.
+// @Description Returns the user ID and role from the access token.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Success 200 {object} models.Me "Current user"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Router /api/auth/me [get]
+func (c *Controller) Me(ctx *gin.Context) {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		ctx.Error(errAuthDisabled.WithStatus(401))
+		return
+	}
+	ctx.JSON(200, models.Me{UserID: principal.UserID, Role: principal.Role})
+}
@@ -1,11 +1,8 @@
 package controller
 
 import (
-	"database/sql"
-	"log"
-	"strconv"
-	"time"
-
+	"github.com/bigxxby/effective-mobile-test/internal/apperr"
+	"github.com/bigxxby/effective-mobile-test/internal/auth"
 	"github.com/bigxxby/effective-mobile-test/internal/models"
 	"github.com/bigxxby/effective-mobile-test/internal/service"
 	"github.com/gin-gonic/gin"
@@ -13,45 +10,54 @@ import (
 
 type Controller struct {
 	Service *service.Service
+	// Auth выпускает токены, nil — авторизация отключена
+	Auth *auth.Authenticator
 }
 
-func New(service service.Service) Controller {
+func New(service service.Service, authenticator *auth.Authenticator) Controller {
 	return Controller{
 		Service: &service,
+		Auth:    authenticator,
 	}
 }
 
 // GetUsers godoc
 // @Summary Get users with optional filtering, pagination, and sorting.
 // @Description Retrieves a list of users based on optional filters, paginated results, and sorting criteria.
-// @Produce json
-// @Param passport_number query string false "Passport number to filter users"
-// @Param surname query string false "Surname to filter users"
-// @Param name query string false "Name to filter users"
-// @Param page query int false "Page number for pagination (default 1)"
-// @Param page_size query int false "Number of users per page (default 10)"
+// @Description Pages are selected by page number or by cursor; the Link header points to the neighbouring pages.
+// @Description Admins see all users, managers only the users they manage.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param passport_number query string false "Passport number to filter users (exact match)"
+// @Param surname query string false "Surname to filter users (exact match)"
+// @Param name query string false "Name to filter users (exact match)"
+// @Param filter[surname][icontains] query string false "Filter by field and operation, filter[field][op]=value: passport_number, surname and name with eq (default), ieq, prefix, iprefix, contains, icontains; search for full-text search by name and address; id with eq, in (comma-separated), gt, gte, lt, lte; created_at and updated_at with gte, lt (RFC 3339 or YYYY-MM-DD)"
+// @Param include_deleted query bool false "Include soft-deleted users (default false)"
+// @Param page query int false "Page number for offset pagination (default 1)"
+// @Param page_size query int false "Number of users per page (default 10, max 100)"
+// @Param cursor query string false "Cursor from next_cursor or prev_cursor of another page, replaces page"
 // @Param sort_by query string false "Field to sort by (default 'id')"
 // @Param sort_order query string false "Sort order, either 'asc' or 'desc' (default 'asc')"
 // @Success 200 {object} models.ResponseUsersList "Successful response with list of users"
-// @Failure 404 {object} models.ErrorResponse "Users not found"
-// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 400 {object} apperr.Problem "Invalid filter or cursor"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "Users not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
 // @Router /api/users [get]
 func (c *Controller) GetUsers(ctx *gin.Context) {
-	var filter models.Filter
-	var pagination models.Pagination
-
-	filter.PassportNumber = ctx.Query("passport_number")
-	filter.Surname = ctx.Query("surname")
-	filter.Name = ctx.Query("name")
-
-	// Валидация параметров
-	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
-	if err != nil {
-		page = 1
+	filter, ok := userFilter(ctx)
+	if !ok {
+		return
 	}
-	pageSize, err := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
-	if err != nil {
-		pageSize = 10
+	// руководитель видит только свою команду
+	if principal, ok := auth.PrincipalFrom(ctx); ok && principal.Role != models.RoleAdmin {
+		filter.ManagerID = principal.UserID
+	}
+	pagination, ok := queryPagination(ctx)
+	if !ok {
+		return
 	}
 
 	sortBy := ctx.DefaultQuery("sort_by", "id")
@@ -60,52 +66,39 @@ func (c *Controller) GetUsers(ctx *gin.Context) {
 		sortOrder = "asc"
 	}
 
-	pagination.Page = page
-	pagination.PageSize = pageSize
-
-	users, err := c.Service.GetUsers(filter, pagination, sortBy, sortOrder)
+	page, err := c.Service.GetUsers(ctx.Request.Context(), filter, pagination, sortBy, sortOrder)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(404, gin.H{"error": "Users not found"})
-			return
-		}
-		ctx.JSON(500, gin.H{"error": "Internal server error"})
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(200, gin.H{"users": users})
+	setLinkHeader(ctx, page)
+	ctx.JSON(200, models.ResponseUsersList{Users: page.Items, PageInfo: page.PageInfo})
 }
 
 // GetUser godoc
 // @Summary Get a user by ID.
 // @Description Retrieves a user by their ID.
-// @Produce json
+// @Produce json,application/problem+json
+// @Security BearerAuth
 // @Param id path int true "User ID"
 // @Success 200 {object} models.User "Successful response with user details"
-// @Failure 400 {object} models.ErrorResponse "Invalid user ID"
-// @Failure 404 {object} models.ErrorResponse "User not found"
-// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 400 {object} apperr.Problem "Invalid user ID"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "User not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
 // @Router /api/users/{id} [get]
 func (c *Controller) GetUser(ctx *gin.Context) {
-	userID := ctx.Param("id")
-
-	uid, err := strconv.Atoi(userID)
-	if err != nil {
-		ctx.JSON(400, gin.H{"error": "Invalid userID"})
-		return
-	}
-	if uid <= 0 {
-		ctx.JSON(400, gin.H{"error": "Invalid userID"})
+	uid, ok := paramID(ctx, "id")
+	if !ok {
 		return
 	}
 
-	user, err := c.Service.GetUser(uid)
+	user, err := c.Service.GetUser(ctx.Request.Context(), uid)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(404, gin.H{"error": "User not found"})
-			return
-		}
-		ctx.JSON(500, gin.H{"error": "Internal server error"})
+		ctx.Error(err)
 		return
 	}
 
@@ -114,78 +107,67 @@ func (c *Controller) GetUser(ctx *gin.Context) {
 
 // CreateUser godoc
 // @Summary Create a new user.
-// @Description Creates a new user with the provided data.
+// @Description Creates a new user with the provided passport number. Surname, name, patronymic and address are filled from the people info API when it is configured.
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
+// @Security BearerAuth
 // @Param request body models.UserData true "User data to create"
 // @Success 201 {object} models.OKresponse "User created successfully"
-// @Failure 400 {object} models.ErrorResponse "Invalid request body or user already exists"
-// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 400 {object} apperr.Problem "Invalid request body or passport number"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 409 {object} apperr.Problem "User already exists"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
 // @Router /api/users [post]
 func (c *Controller) CreateUser(ctx *gin.Context) {
 	var userData models.UserData
-
-	err := ctx.ShouldBindJSON(&userData)
-	if err != nil {
-		ctx.JSON(400, gin.H{"error": "Invalid request body"})
+	if err := ctx.ShouldBindJSON(&userData); err != nil {
+		ctx.Error(apperr.InvalidBody(err))
 		return
 	}
 
-	userId, err := c.Service.CreateUser(userData)
+	userId, err := c.Service.CreateUser(ctx.Request.Context(), userData)
 	if err != nil {
-		if err == models.ErrUserAlreadyExists {
-			ctx.JSON(400, gin.H{"error": "User already exists"})
-			return
-		}
-		log.Println(err)
-		ctx.JSON(500, gin.H{"error": "Internal server error"})
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(201, gin.H{"message ": "User created", "user_id": userId})
+	ctx.JSON(201, gin.H{"message": "User created", "user_id": userId})
 }
 
 // UpdateUser godoc
 // @Summary Update a user by ID.
-// @Description Updates a user with the provided data.
+// @Description Updates a user with the provided data. Surname and name are replaced; patronymic and address
+// @Description are replaced only when given and not empty, so values filled in by enrichment are kept otherwise.
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
+// @Security BearerAuth
 // @Param id path int true "User ID"
 // @Param request body models.UserUpdate true "Updated user data"
 // @Success 200 {object} models.OKresponse "User updated successfully"
-// @Failure 400 {object} models.ErrorResponse "Invalid user ID or request body"
-// @Failure 404 {object} models.ErrorResponse "User not found"
-// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 400 {object} apperr.Problem "Invalid user ID or request body"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "User not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
 // @Router /api/users/{id} [put]
 func (c *Controller) UpdateUser(ctx *gin.Context) {
-	userID := ctx.Param("id")
-
-	uid, err := strconv.Atoi(userID)
-	if err != nil {
-		ctx.JSON(400, gin.H{"error": "Invalid userID"})
-		return
-	}
-	if uid <= 0 {
-		ctx.JSON(400, gin.H{"error": "Invalid userID"})
+	uid, ok := paramID(ctx, "id")
+	if !ok {
 		return
 	}
 
 	var userData models.User
-
-	err = ctx.ShouldBindJSON(&userData)
-	if err != nil {
-		ctx.JSON(400, gin.H{"error": "Invalid request body"})
+	if err := ctx.ShouldBindJSON(&userData); err != nil {
+		ctx.Error(apperr.InvalidBody(err))
 		return
 	}
 
-	err = c.Service.UpdateUser(uid, userData)
+	err := c.Service.UpdateUser(ctx.Request.Context(), uid, userData)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(404, gin.H{"error": "User not found"})
-			return
-		}
-		log.Println(err)
-		ctx.JSON(500, gin.H{"error": "Internal server error"})
+		ctx.Error(err)
 		return
 	}
 
@@ -195,221 +177,491 @@ func (c *Controller) UpdateUser(ctx *gin.Context) {
 
 // DeleteUser godoc
 // @Summary Delete a user by ID.
-// @Description Deletes a user by their ID.
-// @Produce json
+// @Description Soft-deletes a user: the user is hidden from lists and cannot track time, running timers are stopped,
+// @Description time entries are kept. Use restore to undo or purge to delete permanently. Admin only.
+// @Produce json,application/problem+json
+// @Security BearerAuth
 // @Param id path int true "User ID"
 // @Success 200 {object} models.OKresponse "User deleted successfully"
-// @Failure 400 {object} models.ErrorResponse "Invalid user ID"
-// @Failure 404 {object} models.ErrorResponse "User not found"
-// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 400 {object} apperr.Problem "Invalid user ID"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "User not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
 // @Router /api/users/{id} [delete]
 func (c *Controller) DeleteUser(ctx *gin.Context) {
-	userID := ctx.Param("id")
+	uid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
 
-	uid, err := strconv.Atoi(userID)
+	err := c.Service.DeleteUser(ctx.Request.Context(), uid)
 	if err != nil {
-		ctx.JSON(400, gin.H{"error": "Invalid userID"})
+		ctx.Error(err)
 		return
 	}
-	if uid <= 0 {
-		ctx.JSON(400, gin.H{"error": "Invalid userID"})
+
+	ctx.JSON(200, gin.H{"message": "User deleted"})
+}
+
+// RestoreUser godoc
+// @Summary Restore a deleted user.
+// @Description Restores a soft-deleted user. Admin only.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} models.ResponseUser "Restored user"
+// @Failure 400 {object} apperr.Problem "Invalid user ID"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "User not found"
+// @Failure 409 {object} apperr.Problem "User is not deleted"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/users/{id}/restore [post]
+func (c *Controller) RestoreUser(ctx *gin.Context) {
+	uid, ok := paramID(ctx, "id")
+	if !ok {
 		return
 	}
 
-	err = c.Service.DeleteUser(uid)
+	user, err := c.Service.RestoreUser(ctx.Request.Context(), uid)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(404, gin.H{"error": "User not found"})
-			return
-		}
-		log.Println(err)
-		ctx.JSON(500, gin.H{"error": "Internal server error"})
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(200, gin.H{"message": "User deleted"})
+	ctx.JSON(200, gin.H{"user": user})
+}
+
+// PurgeUser godoc
+// @Summary Permanently delete a user.
+// @Description Permanently deletes a soft-deleted user together with their time entries. Admin only.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} models.OKresponse "User purged"
+// @Failure 400 {object} apperr.Problem "Invalid user ID"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "User not found"
+// @Failure 409 {object} apperr.Problem "User is not deleted"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/users/{id}/purge [post]
+func (c *Controller) PurgeUser(ctx *gin.Context) {
+	uid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	err := c.Service.PurgeUser(ctx.Request.Context(), uid)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, gin.H{"message": "User purged"})
 }
 
 // StartTask godoc
 // @Summary Start a task for a user by ID and task ID.
-// @Description Starts a task for a user by their IDs.
-// @Produce json
+// @Description Starts a task for a user by their IDs. If the user already has a running timer for another task,
+// @Description the TIMER_START_POLICY setting decides: parallel starts one more timer, reject answers 409
+// @Description another_timer_running, switch stops the running timers and lists them in stopped.
+// @Produce json,application/problem+json
+// @Security BearerAuth
 // @Param id path int true "User ID"
 // @Param taskId path int true "Task ID"
-// @Success 200 {object} models.OKresponse "Task started successfully"
-// @Failure 400 {object} models.ErrorResponse "Invalid user ID or task ID"
-// @Failure 404 {object} models.ErrorResponse "User or task not found"
-// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Success 200 {object} models.ResponseTimerStart "Started time entry and timers stopped by the switch policy"
+// @Failure 400 {object} apperr.Problem "Invalid user ID or task ID"
+// @Failure 403 {object} apperr.Problem "User is not a member of the task project"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 404 {object} apperr.Problem "User or task not found"
+// @Failure 409 {object} apperr.Problem "Task is archived or already started, or another timer is running"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
 // @Router /api/users/{id}/tasks/{taskId}/start [post]
 func (c *Controller) StartTask(ctx *gin.Context) {
-	userID := ctx.Param("id")
-	taskID := ctx.Param("taskId")
-
-	uid, err := strconv.Atoi(userID)
-	if err != nil {
-		ctx.JSON(400, gin.H{"error": "Invalid userID"})
+	uid, ok := paramID(ctx, "id")
+	if !ok {
 		return
 	}
-	if uid <= 0 {
-		ctx.JSON(400, gin.H{"error": "Invalid userID"})
+	tid, ok := paramID(ctx, "taskId")
+	if !ok {
 		return
 	}
 
-	tid, err := strconv.Atoi(taskID)
+	result, err := c.Service.StartTask(ctx.Request.Context(), uid, tid)
 	if err != nil {
-		ctx.JSON(400, gin.H{"error": "Invalid taskID"})
-		return
-	}
-	if tid <= 0 {
-		ctx.JSON(400, gin.H{"error": "Invalid taskID"})
+		ctx.Error(err)
 		return
 	}
 
-	err = c.Service.StartTask(uid, tid)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(404, gin.H{"error": "User or task not found"})
-			return
-		}
-		if err == models.ErrTaskAlreadyStarted {
-			ctx.JSON(400, gin.H{"error": "Task already started"})
-			return
-		}
-		if err == models.ErrTaskNotFound {
-			ctx.JSON(404, gin.H{"error": "Task not found"})
-			return
-		}
-		log.Println(err)
-		ctx.JSON(500, gin.H{"error": "Internal server error"})
-		return
-	}
-
-	ctx.JSON(200, gin.H{"message": "Task started"})
+	ctx.JSON(200, models.ResponseTimerStart{Message: "Task started", TimeEntry: result.TimeEntry, Stopped: result.Stopped})
 }
 
 // EndTask godoc
 // @Summary End a task for a user by ID and task ID.
-// @Description Ends a task for a user by their IDs.
-// @Produce json
+// @Description Ends a task for a user by their IDs. Stopping a paused timer also ends the current break.
+// @Produce json,application/problem+json
+// @Security BearerAuth
 // @Param id path int true "User ID"
 // @Param taskId path int true "Task ID"
 // @Success 200 {object} models.OKresponse "Task ended successfully"
-// @Failure 400 {object} models.ErrorResponse "Invalid user ID or task ID"
-// @Failure 404 {object} models.ErrorResponse "User or task not found"
-// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 400 {object} apperr.Problem "Invalid user ID or task ID"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "User or task not found"
+// @Failure 409 {object} apperr.Problem "Task not started"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
 // @Router /api/users/{id}/tasks/{taskId}/stop [post]
 func (c *Controller) EndTask(ctx *gin.Context) {
-	userID := ctx.Param("id")
-	taskID := ctx.Param("taskId")
+	uid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+	tid, ok := paramID(ctx, "taskId")
+	if !ok {
+		return
+	}
 
-	uid, err := strconv.Atoi(userID)
+	err := c.Service.EndTask(ctx.Request.Context(), uid, tid)
 	if err != nil {
-		ctx.JSON(400, gin.H{"error": "Invalid userID"})
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, gin.H{"message": "Task ended"})
+}
+
+// PauseTask godoc
+// @Summary Pause a running task timer.
+// @Description Starts a break inside the running session. The break is not counted in workloads until the timer is resumed or stopped.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param taskId path int true "Task ID"
+// @Success 200 {object} models.ResponsePause "Started break"
+// @Failure 400 {object} apperr.Problem "Invalid user ID or task ID"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "Task not found"
+// @Failure 409 {object} apperr.Problem "Task not started or already paused"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/users/{id}/tasks/{taskId}/pause [post]
+func (c *Controller) PauseTask(ctx *gin.Context) {
+	uid, ok := paramID(ctx, "id")
+	if !ok {
 		return
 	}
-	if uid <= 0 {
-		ctx.JSON(400, gin.H{"error": "Invalid userID"})
+	tid, ok := paramID(ctx, "taskId")
+	if !ok {
 		return
 	}
 
-	tid, err := strconv.Atoi(taskID)
+	pause, err := c.Service.PauseTask(ctx.Request.Context(), uid, tid)
 	if err != nil {
-		ctx.JSON(400, gin.H{"error": "Invalid taskID"})
+		ctx.Error(err)
 		return
 	}
-	if tid <= 0 {
-		ctx.JSON(400, gin.H{"error": "Invalid taskID"})
+
+	ctx.JSON(200, models.ResponsePause{Message: "Task paused", Pause: pause})
+}
+
+// ResumeTask godoc
+// @Summary Resume a paused task timer.
+// @Description Ends the current break of the running session.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param taskId path int true "Task ID"
+// @Success 200 {object} models.ResponsePause "Ended break"
+// @Failure 400 {object} apperr.Problem "Invalid user ID or task ID"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "Task not found"
+// @Failure 409 {object} apperr.Problem "Task not started or not paused"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/users/{id}/tasks/{taskId}/resume [post]
+func (c *Controller) ResumeTask(ctx *gin.Context) {
+	uid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+	tid, ok := paramID(ctx, "taskId")
+	if !ok {
 		return
 	}
 
-	err = c.Service.EndTask(uid, tid)
+	pause, err := c.Service.ResumeTask(ctx.Request.Context(), uid, tid)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(404, gin.H{"error": "User or task not found"})
-			return
-		}
-		if err == models.ErrTaskNotStarted {
-			ctx.JSON(400, gin.H{"error": "Task not started yet"})
-			return
-		}
-		log.Println(err)
-		ctx.JSON(500, gin.H{"error": "Internal server error"})
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(200, gin.H{"message": "Task ended"})
+	ctx.JSON(200, models.ResponsePause{Message: "Task resumed", Pause: pause})
 }
 
-// Получение нагрузки пользователя по ID пользователя
+// GetUserWorkloadsByUserID godoc
+// @Summary Get user workloads for a period.
+// @Description Sums logged time per task, sorted from most to least, with a grand total. Running timers and entries crossing the period boundaries are counted only within the period. Breaks recorded with pause and resume are not counted.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param start_date query string true "Start date in YYYY-MM-DD format"
+// @Param end_date query string true "End date in YYYY-MM-DD format, inclusive"
+// @Success 200 {object} models.UserWorkloadReport "User workload report"
+// @Failure 400 {object} apperr.Problem "Invalid user ID or dates"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "User not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/users/{id}/workloads [get]
 func (c *Controller) GetUserWorkloadsByUserID(ctx *gin.Context) {
-	userID := ctx.Param("id")
+	uid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+	startDate, ok := queryDate(ctx, "start_date")
+	if !ok {
+		return
+	}
+	endDate, ok := queryDate(ctx, "end_date")
+	if !ok {
+		return
+	}
+	if startDate.IsZero() || endDate.IsZero() {
+		ctx.Error(apperr.ErrInvalidParameter.WithMessage("start_date and end_date are required").WithDetail("format", "YYYY-MM-DD"))
+		return
+	}
+	// end_date включительно
+	endDate = endDate.AddDate(0, 0, 1)
+
+	report, err := c.Service.GetUserWorkloadsByUserID(ctx.Request.Context(), uid, startDate, endDate)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, report)
+}
+
+// GetTasks godoc
+// @Summary Get tasks with optional filtering, pagination, and sorting.
+// @Description Retrieves a list of tasks. Archived tasks are hidden unless include_archived is true.
+// @Description Pages are selected by page number or by cursor; the Link header points to the neighbouring pages.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param name query string false "Task name to filter tasks"
+// @Param status query string false "Task status to filter tasks" Enums(todo, in_progress, done)
+// @Param project_id query int false "Project ID to filter tasks"
+// @Param include_archived query bool false "Include archived tasks (default false)"
+// @Param page query int false "Page number for offset pagination (default 1)"
+// @Param page_size query int false "Number of tasks per page (default 10, max 100)"
+// @Param cursor query string false "Cursor from next_cursor or prev_cursor of another page, replaces page"
+// @Param sort_by query string false "Field to sort by: id, name, status, created_at (default 'id')"
+// @Param sort_order query string false "Sort order, either 'asc' or 'desc' (default 'asc')"
+// @Success 200 {object} models.ResponseTasksList "Successful response with tasks"
+// @Failure 400 {object} apperr.Problem "Invalid status, project filter or cursor"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 404 {object} apperr.Problem "Tasks not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/tasks [get]
+func (c *Controller) GetTasks(ctx *gin.Context) {
+	var filter models.TaskFilter
+
+	filter.Name = ctx.Query("name")
+	filter.Status = ctx.Query("status")
+	filter.IncludeArchived = ctx.Query("include_archived") == "true"
+	projectID, ok := queryID(ctx, "project_id")
+	if !ok {
+		return
+	}
+	filter.ProjectID = projectID
+
+	pagination, ok := queryPagination(ctx)
+	if !ok {
+		return
+	}
+
+	sortBy := ctx.DefaultQuery("sort_by", "id")
+	sortOrder := ctx.DefaultQuery("sort_order", "asc")
+
+	page, err := c.Service.GetTasks(ctx.Request.Context(), filter, pagination, sortBy, sortOrder)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	setLinkHeader(ctx, page)
+	ctx.JSON(200, models.ResponseTasksList{Tasks: page.Items, PageInfo: page.PageInfo})
+}
 
-	var startDate, endDate time.Time
-	var err error
+// GetTask godoc
+// @Summary Get a task by ID.
+// @Description Retrieves a task by its ID.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Success 200 {object} models.ResponseTask "Successful response with task details"
+// @Failure 400 {object} apperr.Problem "Invalid task ID"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 404 {object} apperr.Problem "Task not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/tasks/{id} [get]
+func (c *Controller) GetTask(ctx *gin.Context) {
+	tid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
 
-	startDate, err = time.Parse("2006-01-02", ctx.Query("start_date"))
+	task, err := c.Service.GetTask(ctx.Request.Context(), tid)
 	if err != nil {
-		ctx.JSON(400, gin.H{"error": "Invalid start_date, format should be YYYY-MM-DD"})
+		ctx.Error(err)
 		return
 	}
 
-	endDate, err = time.Parse("2006-01-02", ctx.Query("end_date"))
+	ctx.JSON(200, gin.H{"task": task})
+}
+
+// CreateTask godoc
+// @Summary Create a new task.
+// @Description Creates a new task. Status defaults to 'todo'.
+// @Accept json
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param request body models.TaskData true "Task data to create"
+// @Success 201 {object} models.OKresponse "Task created successfully"
+// @Failure 400 {object} apperr.Problem "Invalid request body, name or status"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/tasks [post]
+func (c *Controller) CreateTask(ctx *gin.Context) {
+	var taskData models.TaskData
+	if err := ctx.ShouldBindJSON(&taskData); err != nil {
+		ctx.Error(apperr.InvalidBody(err))
+		return
+	}
+
+	taskID, err := c.Service.CreateTask(ctx.Request.Context(), taskData)
 	if err != nil {
-		ctx.JSON(400, gin.H{"error": "Invalid end_date, format should be YYYY-MM-DD"})
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(201, gin.H{"message": "Task created", "task_id": taskID})
+}
+
+// UpdateTask godoc
+// @Summary Replace a task by ID.
+// @Description Replaces all editable fields of a task.
+// @Accept json
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param request body models.TaskData true "Task data"
+// @Success 200 {object} models.OKresponse "Task updated successfully"
+// @Failure 400 {object} apperr.Problem "Invalid task ID or request body"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "Task not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/tasks/{id} [put]
+func (c *Controller) UpdateTask(ctx *gin.Context) {
+	tid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var taskData models.TaskData
+	if err := ctx.ShouldBindJSON(&taskData); err != nil {
+		ctx.Error(apperr.InvalidBody(err))
 		return
 	}
 
-	uid, err := strconv.Atoi(userID)
+	err := c.Service.UpdateTask(ctx.Request.Context(), tid, taskData)
 	if err != nil {
-		ctx.JSON(400, gin.H{"error": "Invalid userID"})
+		ctx.Error(err)
 		return
 	}
-	if uid <= 0 {
-		ctx.JSON(400, gin.H{"error": "Invalid userID"})
+
+	ctx.JSON(200, gin.H{"message": "Task updated"})
+}
+
+// PatchTask godoc
+// @Summary Partially update a task by ID.
+// @Description Updates only the provided fields of a task. Set archived to true to archive a task.
+// @Accept json
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param request body models.TaskPatch true "Fields to update"
+// @Success 200 {object} models.ResponseTask "Updated task"
+// @Failure 400 {object} apperr.Problem "Invalid task ID or request body"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "Task not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/tasks/{id} [patch]
+func (c *Controller) PatchTask(ctx *gin.Context) {
+	tid, ok := paramID(ctx, "id")
+	if !ok {
 		return
 	}
 
-	userWorkloads, err := c.Service.GetUserWorkloadsByUserID(uid, startDate, endDate)
+	var patch models.TaskPatch
+	if err := ctx.ShouldBindJSON(&patch); err != nil {
+		ctx.Error(apperr.InvalidBody(err))
+		return
+	}
+
+	task, err := c.Service.PatchTask(ctx.Request.Context(), tid, patch)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(404, gin.H{"error": "User workloads not found"})
-			return
-		}
-		if err == models.ErrStartDateAfterEndDate {
-			ctx.JSON(400, gin.H{"error": "start_date should be before end_date"})
-			return
-		}
-		if err == models.ErrStartDateInFuture {
-			ctx.JSON(400, gin.H{"error": "start_date should be in the past"})
-			return
-		}
-		log.Println(err)
-		ctx.JSON(500, gin.H{"error": "Internal server error"})
-		return
-	}
-
-	ctx.JSON(200, gin.H{"user_workloads": userWorkloads})
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, gin.H{"task": task})
 }
 
-// GetTasks godoc
-// @Summary Get all tasks.
-// @Description Retrieves all tasks.
-// @Produce json
-// @Success 200 {object} models.ResponseTasksList "Successful response with tasks"
-// @Failure 404 {object} models.ErrorResponse "Tasks not found"
-// @Failure 500 {object} models.ErrorResponse "Internal server error"
-// @Router /api/tasks [get]
-func (c *Controller) GetTasks(ctx *gin.Context) {
-	tasks, err := c.Service.GetTasks()
+// DeleteTask godoc
+// @Summary Delete a task by ID.
+// @Description Deletes a task without time logs. Tasks with logged time should be archived instead.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Success 200 {object} models.OKresponse "Task deleted successfully"
+// @Failure 400 {object} apperr.Problem "Invalid task ID"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "Task not found"
+// @Failure 409 {object} apperr.Problem "Task has time logs"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/tasks/{id} [delete]
+func (c *Controller) DeleteTask(ctx *gin.Context) {
+	tid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	err := c.Service.DeleteTask(ctx.Request.Context(), tid)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(404, gin.H{"error": "Tasks not found"})
-			return
-		}
-		log.Println(err.Error())
-		ctx.JSON(500, gin.H{"error": "Internal server error"})
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(200, gin.H{"tasks": tasks})
+	ctx.JSON(200, gin.H{"message": "Task deleted"})
 }
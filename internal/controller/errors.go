@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/apperr"
+	"github.com/gin-gonic/gin"
+)
+
+// Обработчики не выбирают HTTP-статус ошибки сами: они передают её в ctx.Error,
+// а apperr.Middleware отвечает по коду и статусу ошибки.
+
+// paramID разбирает положительный целочисленный параметр пути
+func paramID(ctx *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param(name))
+	if err != nil || id <= 0 {
+		ctx.Error(apperr.InvalidParameter(name))
+		return 0, false
+	}
+	return id, true
+}
+
+// queryID разбирает необязательный положительный целочисленный параметр запроса, пустой — 0
+func queryID(ctx *gin.Context, name string) (int, bool) {
+	value := ctx.Query(name)
+	if value == "" {
+		return 0, true
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		ctx.Error(apperr.InvalidParameter(name))
+		return 0, false
+	}
+	return id, true
+}
+
+// queryDate разбирает необязательную дату YYYY-MM-DD из строки запроса, пустая — нулевое время
+func queryDate(ctx *gin.Context, name string) (time.Time, bool) {
+	value := ctx.Query(name)
+	if value == "" {
+		return time.Time{}, true
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		ctx.Error(apperr.InvalidParameter(name).WithDetail("format", "YYYY-MM-DD"))
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// queryTime разбирает необязательный момент времени в RFC 3339 или дату YYYY-MM-DD, пустой — нулевое время.
+// Момент приводится к UTC: в базе время хранится в UTC без часового пояса.
+func queryTime(ctx *gin.Context, name string) (time.Time, bool) {
+	value := ctx.Query(name)
+	if value == "" {
+		return time.Time{}, true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), true
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		ctx.Error(apperr.InvalidParameter(name).WithDetail("format", "RFC 3339 or YYYY-MM-DD"))
+		return time.Time{}, false
+	}
+	return date, true
+}
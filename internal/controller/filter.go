@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/apperr"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Операции фильтров filter[field][op]=value помимо режимов сравнения строк
+const (
+	opSearch = "match"
+	opIn     = "in"
+	opGt     = "gt"
+	opGte    = "gte"
+	opLt     = "lt"
+	opLte    = "lte"
+)
+
+var textOps = []string{models.MatchExact, models.MatchIExact, models.MatchPrefix, models.MatchIPrefix, models.MatchContains, models.MatchIContains}
+
+// userFilterOps — поля фильтра пользователей и допустимые операции, первая — операция по умолчанию
+var userFilterOps = map[string][]string{
+	"passport_number": textOps,
+	"surname":         textOps,
+	"name":            textOps,
+	"search":          {opSearch},
+	"id":              {models.MatchExact, opIn, opGt, opGte, opLt, opLte},
+	"created_at":      {opGte, opLt},
+	"updated_at":      {opGte, opLt},
+}
+
+var filterKeyRegexp = regexp.MustCompile(`^filter\[(\w+)\](?:\[(\w+)\])?$`)
+
+// userFilter разбирает фильтр пользователей из параметров filter[field][op]=value
+// и прежних параметров passport_number, surname и name с точным совпадением.
+// Операцию можно не указывать: filter[name]=John — то же, что filter[name][eq]=John.
+func userFilter(ctx *gin.Context) (models.Filter, bool) {
+	filter := models.Filter{
+		PassportNumber: models.TextMatch{Value: ctx.Query("passport_number")},
+		Surname:        models.TextMatch{Value: ctx.Query("surname")},
+		Name:           models.TextMatch{Value: ctx.Query("name")},
+		IncludeDeleted: ctx.Query("include_deleted") == "true",
+	}
+
+	for key, values := range ctx.Request.URL.Query() {
+		if !strings.HasPrefix(key, "filter[") {
+			continue
+		}
+		match := filterKeyRegexp.FindStringSubmatch(key)
+		if match == nil {
+			ctx.Error(apperr.InvalidParameter(key).WithDetail("format", "filter[field][op]=value"))
+			return models.Filter{}, false
+		}
+		field, op := match[1], match[2]
+		ops, ok := userFilterOps[field]
+		if !ok {
+			ctx.Error(apperr.InvalidParameter(key).WithDetail("allowed", filterFields()))
+			return models.Filter{}, false
+		}
+		if op == "" {
+			op = ops[0]
+		}
+		if !slices.Contains(ops, op) {
+			ctx.Error(apperr.InvalidParameter(key).WithDetail("allowed", ops))
+			return models.Filter{}, false
+		}
+		if len(values) != 1 || values[0] == "" {
+			ctx.Error(apperr.InvalidParameter(key).WithDetail("reason", "a single non-empty value is required"))
+			return models.Filter{}, false
+		}
+		if err := applyUserFilter(&filter, field, op, values[0]); err != nil {
+			ctx.Error(err.WithDetail("parameter", key))
+			return models.Filter{}, false
+		}
+	}
+	return filter, true
+}
+
+// applyUserFilter добавляет в фильтр условие field op value
+func applyUserFilter(filter *models.Filter, field, op, value string) *apperr.Error {
+	switch field {
+	case "passport_number":
+		return setTextMatch(&filter.PassportNumber, op, value)
+	case "surname":
+		return setTextMatch(&filter.Surname, op, value)
+	case "name":
+		return setTextMatch(&filter.Name, op, value)
+	case "search":
+		filter.Search = value
+	case "id":
+		return applyIDFilter(&filter.ID, op, value)
+	case "created_at":
+		return applyTimeFilter(&filter.CreatedAt, op, value)
+	case "updated_at":
+		return applyTimeFilter(&filter.UpdatedAt, op, value)
+	}
+	return nil
+}
+
+// setTextMatch задаёт условие на строковое поле; у поля может быть только одно условие
+func setTextMatch(match *models.TextMatch, op, value string) *apperr.Error {
+	if match.Value != "" {
+		return apperr.ErrInvalidParameter.WithMessage("only one condition per text field is allowed")
+	}
+	*match = models.TextMatch{Mode: op, Value: value}
+	return nil
+}
+
+// applyIDFilter сужает диапазон ID: gt и lt переводятся в границы включительно,
+// eq задаёт обе границы, in — список через запятую
+func applyIDFilter(ids *models.IDRange, op, value string) *apperr.Error {
+	if op == opIn {
+		for _, item := range strings.Split(value, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(item))
+			if err != nil || id <= 0 {
+				return apperr.ErrInvalidParameter.WithMessage("id list must contain positive integers separated by commas")
+			}
+			ids.In = append(ids.In, id)
+		}
+		return nil
+	}
+
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return apperr.ErrInvalidParameter.WithMessage("id must be a positive integer")
+	}
+	switch op {
+	case models.MatchExact:
+		raiseMin(ids, id)
+		lowerMax(ids, id)
+	case opGt:
+		raiseMin(ids, id+1)
+	case opGte:
+		raiseMin(ids, id)
+	case opLt:
+		if id == 1 {
+			return apperr.ErrInvalidParameter.WithMessage("id upper bound must be positive")
+		}
+		lowerMax(ids, id-1)
+	case opLte:
+		lowerMax(ids, id)
+	}
+	return nil
+}
+
+func raiseMin(ids *models.IDRange, min int) {
+	if min > ids.Min {
+		ids.Min = min
+	}
+}
+
+func lowerMax(ids *models.IDRange, max int) {
+	if ids.Max == 0 || max < ids.Max {
+		ids.Max = max
+	}
+}
+
+// applyTimeFilter задаёт границу периода [gte, lt): момент в RFC 3339 или дату YYYY-MM-DD
+func applyTimeFilter(period *models.TimeRange, op, value string) *apperr.Error {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", value)
+	}
+	if err != nil {
+		return apperr.ErrInvalidParameter.WithMessage("time must be in RFC 3339 or YYYY-MM-DD format").WithDetail("format", "RFC 3339 or YYYY-MM-DD")
+	}
+	if op == opGte {
+		period.From = t.UTC()
+	} else {
+		period.To = t.UTC()
+	}
+	return nil
+}
+
+func filterFields() []string {
+	fields := make([]string, 0, len(userFilterOps))
+	for field := range userFilterOps {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+	return fields
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// queryPagination разбирает page, page_size и cursor. Некорректные page и page_size заменяются
+// значениями по умолчанию в сервисе, повреждённый cursor — ошибка.
+func queryPagination(ctx *gin.Context) (models.Pagination, bool) {
+	var pagination models.Pagination
+	pagination.Page, _ = strconv.Atoi(ctx.Query("page"))
+	pagination.PageSize, _ = strconv.Atoi(ctx.Query("page_size"))
+	if value := ctx.Query("cursor"); value != "" {
+		cursor, err := models.DecodeCursor(value)
+		if err != nil {
+			ctx.Error(err)
+			return models.Pagination{}, false
+		}
+		pagination.Cursor = &cursor
+	}
+	return pagination, true
+}
+
+// setLinkHeader задаёт заголовок Link (RFC 8288) со ссылками на соседние страницы. Ссылки повторяют
+// запрос с другим cursor, а в режиме номеров страниц — с другим page, и тогда есть ещё ссылка на последнюю.
+func setLinkHeader[T any](ctx *gin.Context, page models.Page[T]) {
+	var links []string
+	link := func(rel string, set func(query url.Values)) {
+		u := *ctx.Request.URL
+		query := u.Query()
+		query.Del("page")
+		query.Del("cursor")
+		set(query)
+		u.RawQuery = query.Encode()
+		links = append(links, "<"+u.RequestURI()+`>; rel="`+rel+`"`)
+	}
+	setCursor := func(cursor string) func(url.Values) {
+		return func(query url.Values) { query.Set("cursor", cursor) }
+	}
+	setPage := func(number int) func(url.Values) {
+		return func(query url.Values) { query.Set("page", strconv.Itoa(number)) }
+	}
+
+	if page.Pagination.Cursor != nil {
+		link("first", func(url.Values) {})
+		if page.PrevCursor != "" {
+			link("prev", setCursor(page.PrevCursor))
+		}
+		if page.NextCursor != "" {
+			link("next", setCursor(page.NextCursor))
+		}
+	} else {
+		number := page.Pagination.Page
+		last := max(1, (page.Total+page.Pagination.PageSize-1)/page.Pagination.PageSize)
+		link("first", setPage(1))
+		if number > 1 {
+			link("prev", setPage(min(number-1, last)))
+		}
+		if number < last {
+			link("next", setPage(number+1))
+		}
+		link("last", setPage(last))
+	}
+	ctx.Header("Link", strings.Join(links, ", "))
+}
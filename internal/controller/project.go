@@ -0,0 +1,315 @@
+package controller
+
+import (
+	"github.com/bigxxby/effective-mobile-test/internal/apperr"
+	"github.com/bigxxby/effective-mobile-test/internal/auth"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetProjects godoc
+// @Summary Get projects.
+// @Description Retrieves a paginated list of projects ordered by ID.
+// @Description Pages are selected by page number or by cursor; the Link header points to the neighbouring pages.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param page query int false "Page number for offset pagination (default 1)"
+// @Param page_size query int false "Number of projects per page (default 10, max 100)"
+// @Param cursor query string false "Cursor from next_cursor or prev_cursor of another page, replaces page"
+// @Success 200 {object} models.ResponseProjectsList "Successful response with projects"
+// @Failure 400 {object} apperr.Problem "Invalid cursor"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/projects [get]
+func (c *Controller) GetProjects(ctx *gin.Context) {
+	pagination, ok := queryPagination(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := c.Service.GetProjects(ctx.Request.Context(), pagination)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	setLinkHeader(ctx, page)
+	ctx.JSON(200, models.ResponseProjectsList{Projects: page.Items, PageInfo: page.PageInfo})
+}
+
+// GetProject godoc
+// @Summary Get a project by ID.
+// @Description Retrieves a project by its ID.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.ResponseProject "Successful response with project details"
+// @Failure 400 {object} apperr.Problem "Invalid project ID"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 404 {object} apperr.Problem "Project not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/projects/{id} [get]
+func (c *Controller) GetProject(ctx *gin.Context) {
+	pid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	project, err := c.Service.GetProject(ctx.Request.Context(), pid)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, gin.H{"project": project})
+}
+
+// CreateProject godoc
+// @Summary Create a new project.
+// @Description Creates a new project.
+// @Accept json
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param request body models.ProjectData true "Project data to create"
+// @Success 201 {object} models.OKresponse "Project created successfully"
+// @Failure 400 {object} apperr.Problem "Invalid request body or name"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/projects [post]
+func (c *Controller) CreateProject(ctx *gin.Context) {
+	var projectData models.ProjectData
+	if err := ctx.ShouldBindJSON(&projectData); err != nil {
+		ctx.Error(apperr.InvalidBody(err))
+		return
+	}
+
+	projectID, err := c.Service.CreateProject(ctx.Request.Context(), projectData)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(201, gin.H{"message": "Project created", "project_id": projectID})
+}
+
+// UpdateProject godoc
+// @Summary Update a project by ID.
+// @Description Replaces the name and description of a project.
+// @Accept json
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param request body models.ProjectData true "Project data"
+// @Success 200 {object} models.OKresponse "Project updated successfully"
+// @Failure 400 {object} apperr.Problem "Invalid project ID or request body"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "Project not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/projects/{id} [put]
+func (c *Controller) UpdateProject(ctx *gin.Context) {
+	pid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var projectData models.ProjectData
+	if err := ctx.ShouldBindJSON(&projectData); err != nil {
+		ctx.Error(apperr.InvalidBody(err))
+		return
+	}
+
+	err := c.Service.UpdateProject(ctx.Request.Context(), pid, projectData)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, gin.H{"message": "Project updated"})
+}
+
+// DeleteProject godoc
+// @Summary Delete a project by ID.
+// @Description Deletes a project that has no tasks.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.OKresponse "Project deleted successfully"
+// @Failure 400 {object} apperr.Problem "Invalid project ID"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "Project not found"
+// @Failure 409 {object} apperr.Problem "Project has tasks"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/projects/{id} [delete]
+func (c *Controller) DeleteProject(ctx *gin.Context) {
+	pid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	err := c.Service.DeleteProject(ctx.Request.Context(), pid)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, gin.H{"message": "Project deleted"})
+}
+
+// GetProjectMembers godoc
+// @Summary Get project members.
+// @Description Retrieves users allowed to start tasks of the project.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.ResponseUsersList "Successful response with members"
+// @Failure 400 {object} apperr.Problem "Invalid project ID"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 404 {object} apperr.Problem "Project not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/projects/{id}/members [get]
+func (c *Controller) GetProjectMembers(ctx *gin.Context) {
+	pid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	users, err := c.Service.GetProjectMembers(ctx.Request.Context(), pid)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, gin.H{"users": users})
+}
+
+// AddProjectMember godoc
+// @Summary Add a project member.
+// @Description Allows a user to start tasks of the project.
+// @Accept json
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param request body models.ProjectMemberData true "User to add"
+// @Success 201 {object} models.OKresponse "Member added successfully"
+// @Failure 400 {object} apperr.Problem "Invalid project ID or request body"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "Project or user not found"
+// @Failure 409 {object} apperr.Problem "User is already a member"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/projects/{id}/members [post]
+func (c *Controller) AddProjectMember(ctx *gin.Context) {
+	pid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var memberData models.ProjectMemberData
+	if err := ctx.ShouldBindJSON(&memberData); err != nil {
+		ctx.Error(apperr.InvalidBody(err))
+		return
+	}
+	if memberData.UserID <= 0 {
+		ctx.Error(apperr.InvalidField("user_id"))
+		return
+	}
+
+	err := c.Service.AddProjectMember(ctx.Request.Context(), pid, memberData.UserID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(201, gin.H{"message": "Member added"})
+}
+
+// RemoveProjectMember godoc
+// @Summary Remove a project member.
+// @Description Revokes a user's access to start tasks of the project.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param userId path int true "User ID"
+// @Success 200 {object} models.OKresponse "Member removed successfully"
+// @Failure 400 {object} apperr.Problem "Invalid project ID or user ID"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "Project or member not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/projects/{id}/members/{userId} [delete]
+func (c *Controller) RemoveProjectMember(ctx *gin.Context) {
+	pid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+	uid, ok := paramID(ctx, "userId")
+	if !ok {
+		return
+	}
+
+	err := c.Service.RemoveProjectMember(ctx.Request.Context(), pid, uid)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, gin.H{"message": "Member removed"})
+}
+
+// GetProjectWorkloads godoc
+// @Summary Get project workloads.
+// @Description Sums logged time across every task and user of the project. Without dates the report covers all time.
+// @Description For a manager the report covers only the time of their own team.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param start_date query string false "Start date in YYYY-MM-DD format"
+// @Param end_date query string false "End date in YYYY-MM-DD format"
+// @Success 200 {object} models.ProjectWorkloadReport "Project workload report"
+// @Failure 400 {object} apperr.Problem "Invalid project ID or dates"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "Project not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/projects/{id}/workloads [get]
+func (c *Controller) GetProjectWorkloads(ctx *gin.Context) {
+	pid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	startDate, ok := queryDate(ctx, "start_date")
+	if !ok {
+		return
+	}
+	endDate, ok := queryDate(ctx, "end_date")
+	if !ok {
+		return
+	}
+
+	// руководитель видит только время своей команды
+	var managerID int
+	if principal, ok := auth.PrincipalFrom(ctx); ok && principal.Role != models.RoleAdmin {
+		managerID = principal.UserID
+	}
+
+	report, err := c.Service.GetProjectWorkloads(ctx.Request.Context(), pid, managerID, startDate, endDate)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, report)
+}
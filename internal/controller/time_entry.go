@@ -0,0 +1,307 @@
+package controller
+
+import (
+	"github.com/bigxxby/effective-mobile-test/internal/apperr"
+	"github.com/bigxxby/effective-mobile-test/internal/auth"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetTimeEntries godoc
+// @Summary Get time entries of a user.
+// @Description Retrieves time entries overlapping the optional period, newest first. Running timers have a null end_time.
+// @Description Pages are selected by page number or by cursor; the Link header points to the neighbouring pages.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param start_date query string false "Start date in YYYY-MM-DD format"
+// @Param end_date query string false "End date in YYYY-MM-DD format, inclusive"
+// @Param page query int false "Page number for offset pagination (default 1)"
+// @Param page_size query int false "Number of entries per page (default 10, max 100)"
+// @Param cursor query string false "Cursor from next_cursor or prev_cursor of another page, replaces page"
+// @Success 200 {object} models.ResponseTimeEntriesList "Successful response with time entries"
+// @Failure 400 {object} apperr.Problem "Invalid user ID, dates or cursor"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "User not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/users/{id}/time-entries [get]
+func (c *Controller) GetTimeEntries(ctx *gin.Context) {
+	uid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	startDate, ok := queryDate(ctx, "start_date")
+	if !ok {
+		return
+	}
+	endDate, ok := queryDate(ctx, "end_date")
+	if !ok {
+		return
+	}
+	if !endDate.IsZero() {
+		endDate = endDate.AddDate(0, 0, 1)
+	}
+
+	pagination, ok := queryPagination(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := c.Service.GetTimeEntries(ctx.Request.Context(), uid, startDate, endDate, pagination)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	setLinkHeader(ctx, page)
+	ctx.JSON(200, models.ResponseTimeEntriesList{TimeEntries: page.Items, PageInfo: page.PageInfo})
+}
+
+// GetTimeEntry godoc
+// @Summary Get a time entry of a user.
+// @Description Retrieves a single time entry.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param entryId path int true "Time entry ID"
+// @Success 200 {object} models.ResponseTimeEntry "Successful response with time entry"
+// @Failure 400 {object} apperr.Problem "Invalid user ID or entry ID"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "Time entry not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/users/{id}/time-entries/{entryId} [get]
+func (c *Controller) GetTimeEntry(ctx *gin.Context) {
+	uid, eid, ok := parseTimeEntryIDs(ctx)
+	if !ok {
+		return
+	}
+
+	entry, err := c.Service.GetTimeEntry(ctx.Request.Context(), uid, eid)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, gin.H{"time_entry": entry})
+}
+
+// GetTimeEntrySegments godoc
+// @Summary Get the segment timeline of a time entry.
+// @Description Splits the session into work and pause segments in chronological order. The last segment of a running timer has no end_time and is counted up to now.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param entryId path int true "Time entry ID"
+// @Success 200 {object} models.Timeline "Segments with worked and paused totals"
+// @Failure 400 {object} apperr.Problem "Invalid user ID or entry ID"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "Time entry not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/users/{id}/time-entries/{entryId}/segments [get]
+func (c *Controller) GetTimeEntrySegments(ctx *gin.Context) {
+	uid, eid, ok := parseTimeEntryIDs(ctx)
+	if !ok {
+		return
+	}
+
+	timeline, err := c.Service.GetTimeEntryTimeline(ctx.Request.Context(), uid, eid)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, timeline)
+}
+
+// CreateTimeEntry godoc
+// @Summary Create a time entry for a user.
+// @Description Back-fills work with explicit start and end times. Entries may not be in the future or overlap other entries of the user.
+// @Accept json
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body models.TimeEntryData true "Time entry data"
+// @Success 201 {object} models.OKresponse "Time entry created successfully"
+// @Failure 400 {object} apperr.Problem "Invalid request body or time range"
+// @Failure 403 {object} apperr.Problem "User is not a member of the task project"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 404 {object} apperr.Problem "User or task not found"
+// @Failure 409 {object} apperr.Problem "Entry overlaps another entry or task is archived"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/users/{id}/time-entries [post]
+func (c *Controller) CreateTimeEntry(ctx *gin.Context) {
+	uid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var entryData models.TimeEntryData
+	if err := ctx.ShouldBindJSON(&entryData); err != nil {
+		ctx.Error(apperr.InvalidBody(err))
+		return
+	}
+
+	entryID, err := c.Service.CreateTimeEntry(ctx.Request.Context(), uid, entryData)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(201, gin.H{"message": "Time entry created", "time_entry_id": entryID})
+}
+
+// UpdateTimeEntry godoc
+// @Summary Update a time entry of a user.
+// @Description Replaces task, start, end and note of an entry. Can be used to close a forgotten running timer.
+// @Accept json
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param entryId path int true "Time entry ID"
+// @Param request body models.TimeEntryData true "Time entry data"
+// @Success 200 {object} models.OKresponse "Time entry updated successfully"
+// @Failure 400 {object} apperr.Problem "Invalid request body or time range"
+// @Failure 403 {object} apperr.Problem "User is not a member of the task project"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 404 {object} apperr.Problem "Time entry or task not found"
+// @Failure 409 {object} apperr.Problem "Entry overlaps another entry or task is archived"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/users/{id}/time-entries/{entryId} [put]
+func (c *Controller) UpdateTimeEntry(ctx *gin.Context) {
+	uid, eid, ok := parseTimeEntryIDs(ctx)
+	if !ok {
+		return
+	}
+
+	var entryData models.TimeEntryData
+	if err := ctx.ShouldBindJSON(&entryData); err != nil {
+		ctx.Error(apperr.InvalidBody(err))
+		return
+	}
+
+	err := c.Service.UpdateTimeEntry(ctx.Request.Context(), uid, eid, entryData)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, gin.H{"message": "Time entry updated"})
+}
+
+// DeleteTimeEntry godoc
+// @Summary Delete a time entry of a user.
+// @Description Deletes a time entry.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param entryId path int true "Time entry ID"
+// @Success 200 {object} models.OKresponse "Time entry deleted successfully"
+// @Failure 400 {object} apperr.Problem "Invalid user ID or entry ID"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "Time entry not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/users/{id}/time-entries/{entryId} [delete]
+func (c *Controller) DeleteTimeEntry(ctx *gin.Context) {
+	uid, eid, ok := parseTimeEntryIDs(ctx)
+	if !ok {
+		return
+	}
+
+	err := c.Service.DeleteTimeEntry(ctx.Request.Context(), uid, eid)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, gin.H{"message": "Time entry deleted"})
+}
+
+func parseTimeEntryIDs(ctx *gin.Context) (int, int, bool) {
+	uid, ok := paramID(ctx, "id")
+	if !ok {
+		return 0, 0, false
+	}
+	eid, ok := paramID(ctx, "entryId")
+	if !ok {
+		return 0, 0, false
+	}
+	return uid, eid, true
+}
+
+// GetUserActiveTimers godoc
+// @Summary Get running timers of a user.
+// @Description Lists the user's open time entries, oldest first, with time elapsed since start and worked time without breaks.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} models.ResponseActiveTimers "Running timers"
+// @Failure 400 {object} apperr.Problem "Invalid user ID"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "User not found"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/users/{id}/active [get]
+func (c *Controller) GetUserActiveTimers(ctx *gin.Context) {
+	uid, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	timers, err := c.Service.GetUserActiveTimers(ctx.Request.Context(), uid)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, gin.H{"active_timers": timers})
+}
+
+// GetActiveTimers godoc
+// @Summary Get running timers of all users.
+// @Description Team-wide view of everyone with a running timer, oldest first, for dashboards.
+// @Description A manager sees only the timers of their own team.
+// @Produce json,application/problem+json
+// @Security BearerAuth
+// @Param task_id query int false "Only timers of this task"
+// @Param project_id query int false "Only timers of tasks in this project"
+// @Success 200 {object} models.ResponseActiveTimers "Running timers"
+// @Failure 400 {object} apperr.Problem "Invalid task or project filter"
+// @Failure 401 {object} apperr.Problem "Missing or invalid token"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 500 {object} apperr.Problem "Internal server error"
+// @Failure 504 {object} apperr.Problem "Operation timed out"
+// @Router /api/active [get]
+func (c *Controller) GetActiveTimers(ctx *gin.Context) {
+	var filter models.ActiveTimerFilter
+	var ok bool
+	if filter.TaskID, ok = queryID(ctx, "task_id"); !ok {
+		return
+	}
+	if filter.ProjectID, ok = queryID(ctx, "project_id"); !ok {
+		return
+	}
+	// руководитель видит только свою команду
+	if principal, ok := auth.PrincipalFrom(ctx); ok && principal.Role != models.RoleAdmin {
+		filter.ManagerID = principal.UserID
+	}
+
+	timers, err := c.Service.GetActiveTimers(ctx.Request.Context(), filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, gin.H{"active_timers": timers})
+}
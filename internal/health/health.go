@@ -0,0 +1,145 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Pinger — соединение с базой, *sql.DB
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Versioner сообщает версию применённых миграций, migrations.Migrator
+type Versioner interface {
+	AppliedVersion(ctx context.Context) (int64, error)
+}
+
+// Handler отвечает на проверки живости, готовности и версии сборки
+type Handler struct {
+	DB         Pinger
+	Migrations Versioner
+	// ExpectedVersion — версия последней встроенной миграции
+	ExpectedVersion int64
+	// Timeout ограничивает проверки /readyz
+	Timeout time.Duration
+}
+
+func New(db Pinger, migrations Versioner, expectedVersion int64) *Handler {
+	return &Handler{
+		DB:              db,
+		Migrations:      migrations,
+		ExpectedVersion: expectedVersion,
+		Timeout:         2 * time.Second,
+	}
+}
+
+type Status struct {
+	Status string            `json:"status" example:"ok"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+type BuildInfo struct {
+	Module       string `json:"module"`
+	Version      string `json:"version"`
+	GoVersion    string `json:"go_version"`
+	Revision     string `json:"revision,omitempty"`
+	RevisionTime string `json:"revision_time,omitempty"`
+	Modified     bool   `json:"modified"`
+}
+
+// Healthz godoc
+// @Summary Liveness probe.
+// @Description Returns 200 while the process is running. Does not check dependencies.
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Status
+// @Router /healthz [get]
+func (h *Handler) Healthz(ctx *gin.Context) {
+	ctx.JSON(200, Status{Status: "ok"})
+}
+
+// Readyz godoc
+// @Summary Readiness probe.
+// @Description Returns 200 when the database answers and its migrations are at the version built into the binary, 503 otherwise.
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Status
+// @Failure 503 {object} health.Status
+// @Router /readyz [get]
+func (h *Handler) Readyz(ctx *gin.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx.Request.Context(), h.Timeout)
+	defer cancel()
+
+	status := Status{Status: "ready", Checks: map[string]string{}}
+	ready := true
+
+	if err := h.DB.PingContext(checkCtx); err != nil {
+		slog.WarnContext(ctx.Request.Context(), "readiness: database ping failed", "error", err)
+		status.Checks["database"] = "unavailable"
+		status.Checks["migrations"] = "unknown"
+		ready = false
+	} else {
+		status.Checks["database"] = "ok"
+		version, err := h.Migrations.AppliedVersion(checkCtx)
+		switch {
+		case err != nil:
+			slog.WarnContext(ctx.Request.Context(), "readiness: migration version check failed", "error", err)
+			status.Checks["migrations"] = "unknown"
+			ready = false
+		case version != h.ExpectedVersion:
+			status.Checks["migrations"] = fmt.Sprintf("version %d, expected %d", version, h.ExpectedVersion)
+			ready = false
+		default:
+			status.Checks["migrations"] = "ok"
+		}
+	}
+
+	if !ready {
+		status.Status = "not ready"
+		ctx.JSON(503, status)
+		return
+	}
+	ctx.JSON(200, status)
+}
+
+// Version godoc
+// @Summary Build information.
+// @Description Returns the module version, Go version and VCS revision the binary was built from.
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.BuildInfo
+// @Router /version [get]
+func (h *Handler) Version(ctx *gin.Context) {
+	ctx.JSON(200, ReadBuildInfo())
+}
+
+// ReadBuildInfo собирает сведения о сборке из debug.ReadBuildInfo
+func ReadBuildInfo() BuildInfo {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return BuildInfo{Version: "unknown"}
+	}
+
+	build := BuildInfo{
+		Module:    info.Main.Path,
+		Version:   info.Main.Version,
+		GoVersion: info.GoVersion,
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			build.Revision = setting.Value
+		case "vcs.time":
+			build.RevisionTime = setting.Value
+		case "vcs.modified":
+			build.Modified = setting.Value == "true"
+		}
+	}
+	return build
+}
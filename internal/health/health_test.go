@@ -0,0 +1,85 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubDB struct{ err error }
+
+func (db stubDB) PingContext(ctx context.Context) error { return db.err }
+
+type stubMigrations struct {
+	version int64
+	err     error
+}
+
+func (m stubMigrations) AppliedVersion(ctx context.Context) (int64, error) { return m.version, m.err }
+
+func TestReadyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		db         stubDB
+		migrations stubMigrations
+		wantStatus int
+		wantChecks map[string]string
+	}{
+		{"ready", stubDB{}, stubMigrations{version: 6}, 200, map[string]string{"database": "ok", "migrations": "ok"}},
+		{"database down", stubDB{errors.New("refused")}, stubMigrations{version: 6}, 503, map[string]string{"database": "unavailable", "migrations": "unknown"}},
+		{"pending migrations", stubDB{}, stubMigrations{version: 5}, 503, map[string]string{"database": "ok", "migrations": "version 5, expected 6"}},
+		{"version query failed", stubDB{}, stubMigrations{err: errors.New("boom")}, 503, map[string]string{"database": "ok", "migrations": "unknown"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/readyz", New(tt.db, tt.migrations, 6).Readyz)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var status Status
+			if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+				t.Fatal(err)
+			}
+			for key, want := range tt.wantChecks {
+				if status.Checks[key] != want {
+					t.Errorf("check %s = %q, want %q", key, status.Checks[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthzAndVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := New(stubDB{errors.New("down")}, stubMigrations{}, 1)
+	router := gin.New()
+	router.GET("/healthz", h.Healthz)
+	router.GET("/version", h.Version)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != 200 {
+		t.Errorf("healthz status = %d, want 200 even when the database is down", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+	var info BuildInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != 200 || info.GoVersion == "" {
+		t.Errorf("version = %d %+v", rec.Code, info)
+	}
+}
@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+type Config struct {
+	// Level — debug, info, warn или error
+	Level string
+	// Format — json или text
+	Format string
+}
+
+// redactedKeys — атрибуты, значения которых никогда не попадают в лог
+var redactedKeys = map[string]bool{
+	"password":      true,
+	"secret":        true,
+	"token":         true,
+	"authorization": true,
+	"dsn":           true,
+}
+
+// New создаёт slog.Logger, который добавляет request_id из контекста к каждой записи
+func New(cfg Config, w io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(defaultString(cfg.Level, "info"))); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", cfg.Level)
+	}
+
+	options := &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if redactedKeys[strings.ToLower(attr.Key)] {
+				return slog.String(attr.Key, "[REDACTED]")
+			}
+			return attr
+		},
+	}
+
+	var handler slog.Handler
+	switch strings.ToLower(defaultString(cfg.Format, "json")) {
+	case "json":
+		handler = slog.NewJSONHandler(w, options)
+	case "text":
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return nil, fmt.Errorf("invalid log format %q, should be json or text", cfg.Format)
+	}
+
+	return slog.New(contextHandler{handler}), nil
+}
+
+func defaultString(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+type requestIDKey struct{}
+
+// WithRequestID сохраняет идентификатор запроса в контексте
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID возвращает идентификатор запроса из контекста или пустую строку
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// contextHandler добавляет request_id к записям, сделанным через *Context методы slog
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,120 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNew(t *testing.T) {
+	if _, err := New(Config{Level: "verbose"}, &bytes.Buffer{}); err == nil {
+		t.Error("invalid level: err = nil")
+	}
+	if _, err := New(Config{Format: "xml"}, &bytes.Buffer{}); err == nil {
+		t.Error("invalid format: err = nil")
+	}
+
+	var buf bytes.Buffer
+	logger, err := New(Config{Level: "warn", Format: "text"}, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("hidden")
+	logger.Warn("shown", "password", "qwerty", "token", "abc")
+	out := buf.String()
+	if strings.Contains(out, "hidden") || !strings.Contains(out, "shown") {
+		t.Errorf("level filtering, output: %s", out)
+	}
+	if strings.Contains(out, "qwerty") || strings.Contains(out, "abc") {
+		t.Errorf("secrets leaked: %s", out)
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	logger, err := New(Config{}, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), AccessLog(logger))
+	router.GET("/api/users/:id", func(ctx *gin.Context) {
+		logger.InfoContext(ctx.Request.Context(), "handler")
+		ctx.Status(200)
+	})
+
+	tests := []struct {
+		name     string
+		header   string
+		wantSame bool
+	}{
+		{"generated", "", false},
+		{"propagated", "abc-123", true},
+		{"unsafe header replaced", "bad id\nforged=1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/api/users/1?passport_number=1234", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			requestID := rec.Header().Get(RequestIDHeader)
+			if requestID == "" {
+				t.Fatal("no request id in response")
+			}
+			if (requestID == tt.header) != tt.wantSame {
+				t.Errorf("request id = %q, header = %q", requestID, tt.header)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("got %d log lines: %s", len(lines), buf.String())
+			}
+			for _, line := range lines {
+				var entry map[string]any
+				if err := json.Unmarshal([]byte(line), &entry); err != nil {
+					t.Fatal(err)
+				}
+				if entry["request_id"] != requestID {
+					t.Errorf("request_id = %v, want %q in %s", entry["request_id"], requestID, line)
+				}
+			}
+			if strings.Contains(buf.String(), "passport_number") {
+				t.Errorf("query string logged: %s", buf.String())
+			}
+		})
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	logger := slog.New(contextHandler{slog.NewJSONHandler(&buf, nil)})
+
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), Recovery(logger))
+	router.GET("/panic", func(ctx *gin.Context) { panic("boom") })
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if rec.Code != 500 {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+	if !strings.Contains(buf.String(), "boom") || !strings.Contains(buf.String(), rec.Header().Get(RequestIDHeader)) {
+		t.Errorf("panic log: %s", buf.String())
+	}
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/apperr"
+	"github.com/gin-gonic/gin"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// Входящий X-Request-ID принимается только в безопасном виде, иначе генерируется новый
+var requestIDRegexp = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestIDMiddleware берёт идентификатор из заголовка X-Request-ID или генерирует новый,
+// кладёт его в контекст запроса и возвращает в ответе
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(RequestIDHeader)
+		if !requestIDRegexp.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		ctx.Request = ctx.Request.WithContext(WithRequestID(ctx.Request.Context(), requestID))
+		ctx.Header(RequestIDHeader, requestID)
+		ctx.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// AccessLog пишет одну запись на запрос. Строка запроса и заголовки не логируются:
+// в них бывают номера паспортов и токены.
+func AccessLog(logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		logger.LogAttrs(ctx.Request.Context(), level, "request",
+			slog.String("method", ctx.Request.Method),
+			slog.String("route", ctx.FullPath()),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Int("bytes", ctx.Writer.Size()),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", ctx.ClientIP()),
+		)
+	}
+}
+
+// Recovery перехватывает панику в обработчике, логирует её и отвечает 500
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(ctx *gin.Context, recovered any) {
+		logger.ErrorContext(ctx.Request.Context(), "panic recovered", "panic", recovered)
+		apperr.Abort(ctx, apperr.ErrInternal)
+	})
+}
@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "timetracker"
+
+// Metrics хранит метрики сервиса в собственном реестре, а не в глобальном
+// prometheus.DefaultRegisterer, поэтому экземпляры в тестах не мешают друг другу.
+// Реализует service.Events.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+
+	timersStarted      prometheus.Counter
+	timersStopped      prometheus.Counter
+	usersCreated       prometheus.Counter
+	enrichmentFailures prometheus.Counter
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method and route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		timersStarted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "timers_started_total",
+			Help:      "Timers started.",
+		}),
+		timersStopped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "timers_stopped_total",
+			Help:      "Timers stopped, including timers stopped automatically.",
+		}),
+		usersCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "users_created_total",
+			Help:      "Users created.",
+		}),
+		enrichmentFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "enrichment_failures_total",
+			Help:      "Failed requests to the people info API.",
+		}),
+	}
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.duration,
+		m.timersStarted,
+		m.timersStopped,
+		m.usersCreated,
+		m.enrichmentFailures,
+	)
+	return m
+}
+
+// RegisterDB добавляет метрики пула соединений из sql.DBStats (go_sql_*)
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	m.Registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterOpenTimers добавляет gauge запущенных таймеров, count вызывается при каждом сборе
+func (m *Metrics) RegisterOpenTimers(count func(ctx context.Context) (int, error)) {
+	m.Registry.MustRegister(&openTimersCollector{
+		count:   count,
+		timeout: 2 * time.Second,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "open_timers"),
+			"Timers currently running.",
+			nil, nil,
+		),
+	})
+}
+
+// Handler отдаёт метрики в формате Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{Registry: m.Registry})
+}
+
+// Middleware считает запросы и их длительность. Маршрут берётся из шаблона gin
+// (/api/users/:id), а не из пути, чтобы число рядов не зависело от идентификаторов;
+// запросы к несуществующим маршрутам попадают в route="unmatched".
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := ctx.Request.Method
+		m.requests.WithLabelValues(method, route, strconv.Itoa(ctx.Writer.Status())).Inc()
+		m.duration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+func (m *Metrics) TimerStarted() {
+	m.timersStarted.Inc()
+}
+
+func (m *Metrics) TimersStopped(count int) {
+	m.timersStopped.Add(float64(count))
+}
+
+func (m *Metrics) UserCreated() {
+	m.usersCreated.Inc()
+}
+
+func (m *Metrics) EnrichmentFailed() {
+	m.enrichmentFailures.Inc()
+}
+
+type openTimersCollector struct {
+	count   func(ctx context.Context) (int, error)
+	timeout time.Duration
+	desc    *prometheus.Desc
+}
+
+func (c *openTimersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect не отдаёт значение, если подсчёт не удался: пропуск ряда заметнее, чем ложный ноль
+func (c *openTimersCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	count, err := c.count(ctx)
+	if err != nil {
+		slog.Warn("metrics: counting open timers failed", "error", err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count))
+}
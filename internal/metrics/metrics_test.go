@@ -0,0 +1,99 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bigxxby/effective-mobile-test/internal/metrics"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/bigxxby/effective-mobile-test/internal/repository"
+	"github.com/bigxxby/effective-mobile-test/internal/service"
+	"github.com/bigxxby/effective-mobile-test/pkg/enrichment"
+	"github.com/gin-gonic/gin"
+)
+
+type failingEnricher struct{}
+
+func (failingEnricher) Enrich(ctx context.Context, passportSerie, passportNumber string) (enrichment.Person, error) {
+	return enrichment.Person{}, errors.New("unavailable")
+}
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != 200 {
+		t.Fatalf("scrape status = %d", rec.Code)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func assertContains(t *testing.T, body string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("metrics do not contain %q", line)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	m := metrics.New()
+	router := gin.New()
+	router.Use(m.Middleware())
+	router.GET("/api/users/:id", func(ctx *gin.Context) { ctx.Status(200) })
+
+	for _, path := range []string{"/api/users/1", "/api/users/2", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assertContains(t, scrape(t, m),
+		`timetracker_http_requests_total{method="GET",route="/api/users/:id",status="200"} 2`,
+		`timetracker_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`timetracker_http_request_duration_seconds_count{method="GET",route="/api/users/:id"} 2`,
+	)
+}
+
+func TestDomainEvents(t *testing.T) {
+	ctx := context.Background()
+	m := metrics.New()
+	repo := repository.NewMemory()
+	svc := service.New(repo, failingEnricher{})
+	svc.Events = m
+	m.RegisterOpenTimers(svc.CountOpenTimers)
+
+	userID, err := svc.CreateUser(ctx, models.UserData{PassportNumber: "1234 567890"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	taskID, err := svc.CreateTask(ctx, models.TaskData{Name: "task"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.StartTask(ctx, userID, taskID); err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, scrape(t, m),
+		"timetracker_users_created_total 1",
+		"timetracker_enrichment_failures_total 1",
+		"timetracker_timers_started_total 1",
+		"timetracker_timers_stopped_total 0",
+		"timetracker_open_timers 1",
+	)
+
+	if err := svc.EndTask(ctx, userID, taskID); err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, scrape(t, m),
+		"timetracker_timers_stopped_total 1",
+		"timetracker_open_timers 0",
+	)
+}
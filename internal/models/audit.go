@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEvent — запись журнала аудита об одном изменении сущности.
+// ActorID равен nil, если изменение сделал сам сервис или запрос прошёл без аутентификации.
+// Before и After содержат только изменившиеся поля; при создании Before пуст, при удалении — After.
+type AuditEvent struct {
+	ID         int64           `json:"id"`
+	ActorID    *int            `json:"actor_id"`
+	ActorRole  string          `json:"actor_role,omitempty"`
+	Action     string          `json:"action" example:"user.update"`
+	EntityType string          `json:"entity_type" example:"user"`
+	EntityID   int             `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After      json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// Типы сущностей в журнале аудита
+const (
+	AuditEntityUser      = "user"
+	AuditEntityTask      = "task"
+	AuditEntityProject   = "project"
+	AuditEntityTimeEntry = "time_entry"
+	AuditEntityPause     = "pause"
+)
+
+// Действия в журнале аудита
+const (
+	AuditUserCreate     = "user.create"
+	AuditUserUpdate     = "user.update"
+	AuditUserUpdateRole = "user.update_role"
+	AuditUserDelete     = "user.delete"
+	AuditUserRestore    = "user.restore"
+	AuditUserPurge      = "user.purge"
+
+	AuditTaskCreate = "task.create"
+	AuditTaskUpdate = "task.update"
+	AuditTaskDelete = "task.delete"
+
+	AuditProjectCreate       = "project.create"
+	AuditProjectUpdate       = "project.update"
+	AuditProjectDelete       = "project.delete"
+	AuditProjectAddMember    = "project.add_member"
+	AuditProjectRemoveMember = "project.remove_member"
+
+	AuditTimeEntryCreate = "time_entry.create"
+	AuditTimeEntryUpdate = "time_entry.update"
+	AuditTimeEntryDelete = "time_entry.delete"
+	// AuditTimeEntryFlag — запущенный таймер помечен needs_review
+	AuditTimeEntryFlag = "time_entry.flag"
+
+	AuditTimerStart = "timer.start"
+	AuditTimerStop  = "timer.stop"
+	// AuditTimerAutoStop — таймер остановлен системой: забытый таймер, остановка сервиса, удаление пользователя
+	AuditTimerAutoStop = "timer.auto_stop"
+	AuditTimerPause    = "timer.pause"
+	AuditTimerResume   = "timer.resume"
+)
+
+// AuditFilter — отбор событий аудита, нулевое поле не ограничивает выборку
+type AuditFilter struct {
+	EntityType string
+	EntityID   int
+	ActorID    int
+	Action     string
+	From       time.Time
+	To         time.Time
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// TokenRequest — тело запроса POST /api/auth/token.
+// TTL в формате time.ParseDuration, пустое значение — срок по умолчанию, больше AUTH_MAX_TOKEN_TTL нельзя.
+type TokenRequest struct {
+	UserID int    `json:"user_id" binding:"required"`
+	TTL    string `json:"ttl" example:"24h"`
+}
+
+type TokenResponse struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type" example:"Bearer"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Me описывает пользователя, от имени которого выполняется запрос
+type Me struct {
+	UserID int    `json:"user_id"`
+	Role   string `json:"role"`
+}
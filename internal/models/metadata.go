@@ -1,17 +1,58 @@
 package models
 
-import "errors"
+import (
+	"time"
 
+	"github.com/bigxxby/effective-mobile-test/internal/apperr"
+)
+
+// Filter — отбор пользователей, нулевое поле не ограничивает выборку
 type Filter struct {
-	PassportNumber string
-	Surname        string
-	Name           string
+	PassportNumber TextMatch
+	Surname        TextMatch
+	Name           TextMatch
+	// Search — полнотекстовый поиск по фамилии, имени, отчеству и адресу:
+	// каждое слово запроса должно встретиться целым словом, без учёта регистра
+	Search    string
+	ID        IDRange
+	CreatedAt TimeRange
+	UpdatedAt TimeRange
+	// ManagerID — только команда руководителя с этим id
+	ManagerID int
+	// IncludeDeleted — показывать и мягко удалённых пользователей
+	IncludeDeleted bool
 }
-type Pagination struct {
-	Page     int
-	PageSize int
+
+// Режимы сравнения строкового поля; режимы с префиксом i не учитывают регистр
+const (
+	MatchExact     = "eq"
+	MatchIExact    = "ieq"
+	MatchPrefix    = "prefix"
+	MatchIPrefix   = "iprefix"
+	MatchContains  = "contains"
+	MatchIContains = "icontains"
+)
+
+// TextMatch — условие на строковое поле, пустой Mode — точное совпадение
+type TextMatch struct {
+	Mode  string
+	Value string
+}
+
+// IDRange — условие на ID: диапазон [Min, Max] и список допустимых значений,
+// нулевые границы и пустой список не ограничивают выборку
+type IDRange struct {
+	Min int
+	Max int
+	In  []int
+}
+
+// TimeRange — период [From, To), нулевая граница не ограничивает выборку
+type TimeRange struct {
+	From time.Time
+	To   time.Time
 }
 
 var (
-	ErrInvalidID = errors.New("invalid ID")
+	ErrInvalidID = apperr.New(400, "invalid_id", "invalid ID")
 )
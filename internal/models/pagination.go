@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/apperr"
+)
+
+// Pagination — запрос страницы списка. Без Cursor страница выбирается по номеру Page (LIMIT/OFFSET),
+// с Cursor — сразу после или перед строкой, на которой закончилась соседняя страница (keyset):
+// такие страницы не сдвигаются, когда в список добавляют или из него удаляют строки.
+type Pagination struct {
+	Page     int
+	PageSize int
+	Cursor   *Cursor
+}
+
+// Cursor — позиция в списке: ключ сортировки строки, на которой закончилась страница.
+// Value — значение столбца сортировки: строка как есть, время в виде SortTime, пустое при сортировке по id.
+// Before — страница перед этой строкой, иначе после неё.
+type Cursor struct {
+	SortBy    string `json:"s"`
+	SortOrder string `json:"o"`
+	Value     string `json:"v,omitempty"`
+	ID        int64  `json:"i"`
+	Before    bool   `json:"b,omitempty"`
+}
+
+// Encode возвращает непрозрачную для клиента строку курсора
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor разбирает строку Encode, ErrInvalidCursor если строка повреждена
+func DecodeCursor(value string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID <= 0 || cursor.SortBy == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if cursor.SortOrder != "asc" && cursor.SortOrder != "desc" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// sortTimeLayout — время в курсоре: UTC фиксированной ширины, строки сравниваются в порядке времени
+const sortTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// SortTime — значение столбца сортировки типа времени для Cursor.Value
+func SortTime(t time.Time) string {
+	return t.UTC().Format(sortTimeLayout)
+}
+
+// PageInfo — общее число элементов списка с учётом фильтров и курсоры соседних страниц,
+// пустые, если соседней страницы нет
+type PageInfo struct {
+	Total      int    `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// Page — страница списка. Pagination — запрос страницы с размером, который применил сервис.
+type Page[T any] struct {
+	Items      []T
+	Pagination Pagination
+	PageInfo
+}
+
+var (
+	ErrInvalidCursor = apperr.New(400, "invalid_cursor", "invalid cursor")
+)
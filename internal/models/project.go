@@ -0,0 +1,66 @@
+package models
+
+import (
+	"github.com/bigxxby/effective-mobile-test/internal/apperr"
+	"time"
+)
+
+type Project struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ProjectData — тело запросов POST и PUT /api/projects
+type ProjectData struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type ProjectMemberData struct {
+	UserID int `json:"user_id" binding:"required"`
+}
+
+// ProjectWorkloadEntry — суммарное время одного пользователя по одной задаче проекта
+type ProjectWorkloadEntry struct {
+	TaskID       int
+	TaskName     string
+	UserID       int
+	TotalSeconds float64
+}
+
+type ProjectTaskWorkload struct {
+	TaskID       int    `json:"task_id"`
+	TaskName     string `json:"task_name"`
+	TotalSeconds int64  `json:"total_seconds"`
+	TotalHours   int    `json:"total_hours"`
+	TotalMinutes int    `json:"total_minutes"`
+}
+
+type ProjectUserWorkload struct {
+	UserID       int   `json:"user_id"`
+	TotalSeconds int64 `json:"total_seconds"`
+	TotalHours   int   `json:"total_hours"`
+	TotalMinutes int   `json:"total_minutes"`
+}
+
+// ProjectWorkloadReport — время по проекту в разрезе задач и пользователей
+type ProjectWorkloadReport struct {
+	ProjectID    int                   `json:"project_id"`
+	Tasks        []ProjectTaskWorkload `json:"tasks"`
+	Users        []ProjectUserWorkload `json:"users"`
+	TotalSeconds int64                 `json:"total_seconds"`
+	TotalHours   int                   `json:"total_hours"`
+	TotalMinutes int                   `json:"total_minutes"`
+}
+
+var (
+	ErrProjectNotFound      = apperr.New(404, "project_not_found", "project not found")
+	ErrProjectHasTasks      = apperr.New(409, "project_has_tasks", "project has tasks, move or delete them first")
+	ErrInvalidProjectName   = apperr.New(400, "invalid_project_name", "invalid project name").WithDetail("max_length", 255)
+	ErrUserNotProjectMember = apperr.New(403, "not_project_member", "user is not a member of the task project")
+	ErrUserNotFound         = apperr.New(404, "user_not_found", "user not found")
+	ErrMemberAlreadyExists  = apperr.New(409, "member_already_exists", "user is already a project member")
+	ErrMemberNotFound       = apperr.New(404, "member_not_found", "user is not a project member")
+)
@@ -3,20 +3,68 @@ package models
 // ResponseUsersList описывает структуру ответа на запрос получения пользователей.
 type ResponseUsersList struct {
 	Users []User `json:"users"`
-}
-
-// ErrorResponse описывает структуру ответа на ошибку.
-type ErrorResponse struct {
-	Error string `json:"error"`
+	PageInfo
 }
 
 type OKresponse struct {
 	Message string `json:"message"`
 }
+
+// UserUpdate — тело PUT /api/users/{id}. Пустые или не переданные patronymic и address
+// не меняются, чтобы обновление не стирало данные, заполненные обогащением.
 type UserUpdate struct {
-	Surname string `json:"surname" binding:"required"`
-	Name    string `json:"name" binding:"required"`
+	Surname    string `json:"surname" binding:"required"`
+	Name       string `json:"name" binding:"required"`
+	Patronymic string `json:"patronymic"`
+	Address    string `json:"address"`
 }
 type ResponseTasksList struct {
 	Tasks []Task `json:"tasks"`
+	PageInfo
+}
+
+type ResponseTask struct {
+	Task Task `json:"task"`
+}
+
+type ResponseProjectsList struct {
+	Projects []Project `json:"projects"`
+	PageInfo
+}
+
+type ResponseProject struct {
+	Project Project `json:"project"`
+}
+
+type ResponseTimeEntriesList struct {
+	TimeEntries []TimeEntry `json:"time_entries"`
+	PageInfo
+}
+
+type ResponseAuditEvents struct {
+	Events []AuditEvent `json:"events"`
+	PageInfo
+}
+
+type ResponseTimeEntry struct {
+	TimeEntry TimeEntry `json:"time_entry"`
+}
+
+type ResponseUser struct {
+	User User `json:"user"`
+}
+
+type ResponseTimerStart struct {
+	Message   string      `json:"message"`
+	TimeEntry TimeEntry   `json:"time_entry"`
+	Stopped   []TimeEntry `json:"stopped"`
+}
+
+type ResponsePause struct {
+	Message string `json:"message"`
+	Pause   Pause  `json:"pause"`
+}
+
+type ResponseActiveTimers struct {
+	ActiveTimers []ActiveTimer `json:"active_timers"`
 }
@@ -1,18 +1,60 @@
 package models
 
-import "errors"
+import (
+	"github.com/bigxxby/effective-mobile-test/internal/apperr"
+	"time"
+)
 
 type Task struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	Archived    bool      `json:"archived"`
+	ProjectID   *int      `json:"project_id"`
+}
+
+const (
+	TaskStatusTodo       = "todo"
+	TaskStatusInProgress = "in_progress"
+	TaskStatusDone       = "done"
+)
+
+// TaskData — тело запросов POST и PUT /api/tasks
+type TaskData struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Status      string `json:"status" enums:"todo,in_progress,done"`
+	Archived    bool   `json:"archived"`
+	ProjectID   *int   `json:"project_id"`
+}
+
+// TaskPatch — тело запроса PATCH /api/tasks/{id}, меняются только переданные поля
+type TaskPatch struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Status      *string `json:"status" enums:"todo,in_progress,done"`
+	Archived    *bool   `json:"archived"`
+	ProjectID   *int    `json:"project_id"`
+}
+
+type TaskFilter struct {
+	Name            string
+	Status          string
+	ProjectID       int
+	IncludeArchived bool
 }
 
 var (
-	ErrTaskNotStarted        = errors.New("start date is after end date")
-	ErrTaskAlreadyStarted    = errors.New("task already started")
-	ErrStartDateAfterEndDate = errors.New("start date is after end date")
-	ErrStartDateInFuture     = errors.New("start date is in the future")
-	ErrEndDateInFuture       = errors.New("end date is in the future")
-	ErrTaskNotEnded          = errors.New("task not ended")
-	ErrTaskNotFound          = errors.New("task not found")
+	ErrTaskNotStarted        = apperr.New(409, "task_not_started", "task not started")
+	ErrTaskAlreadyStarted    = apperr.New(409, "task_already_started", "task already started")
+	ErrStartDateAfterEndDate = apperr.New(400, "start_after_end", "start date is after end date")
+	ErrStartDateInFuture     = apperr.New(400, "start_in_future", "start date is in the future")
+	ErrEndDateInFuture       = apperr.New(400, "end_in_future", "end date is in the future")
+	ErrTaskNotFound          = apperr.New(404, "task_not_found", "task not found")
+	ErrTaskArchived          = apperr.New(409, "task_archived", "task is archived")
+	ErrTaskHasLogs           = apperr.New(409, "task_has_logs", "task has time logs, archive it instead")
+	ErrInvalidTaskStatus     = apperr.New(400, "invalid_task_status", "invalid task status").WithDetail("allowed", []string{TaskStatusTodo, TaskStatusInProgress, TaskStatusDone})
+	ErrInvalidTaskName       = apperr.New(400, "invalid_task_name", "invalid task name").WithDetail("max_length", 255)
 )
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"github.com/bigxxby/effective-mobile-test/internal/apperr"
+	"time"
+)
+
+// TimeEntry — запись task_logs. EndTime равен nil, пока таймер запущен.
+// NeedsReview — запись требует проверки (например, таймер шёл во время остановки сервиса),
+// AutoClosed — таймер остановлен системой, а не пользователем.
+type TimeEntry struct {
+	ID          int        `json:"id"`
+	UserID      int        `json:"user_id"`
+	TaskID      int        `json:"task_id"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     *time.Time `json:"end_time"`
+	Note        string     `json:"note"`
+	NeedsReview bool       `json:"needs_review"`
+	AutoClosed  bool       `json:"auto_closed"`
+}
+
+// TimeEntryData — тело запросов POST и PUT /api/users/{id}/time-entries
+type TimeEntryData struct {
+	TaskID    int       `json:"task_id" binding:"required"`
+	StartTime time.Time `json:"start_time" binding:"required" example:"2024-07-01T10:00:00Z"`
+	EndTime   time.Time `json:"end_time" binding:"required" example:"2024-07-01T12:30:00Z"`
+	Note      string    `json:"note"`
+}
+
+// ActiveTimer — запущенный таймер с длительностью на момент запроса: ElapsedSeconds — с запуска,
+// WorkedSeconds — без перерывов. Paused — таймер сейчас на паузе.
+type ActiveTimer struct {
+	TimeEntry      TimeEntry `json:"time_entry"`
+	UserSurname    string    `json:"user_surname"`
+	UserName       string    `json:"user_name"`
+	TaskName       string    `json:"task_name"`
+	ProjectID      *int      `json:"project_id"`
+	Paused         bool      `json:"paused"`
+	ElapsedSeconds int64     `json:"elapsed_seconds"`
+	WorkedSeconds  int64     `json:"worked_seconds"`
+}
+
+// ActiveTimerFilter — отбор запущенных таймеров, нулевое поле не ограничивает выборку
+type ActiveTimerFilter struct {
+	UserID    int
+	TaskID    int
+	ProjectID int
+	// ManagerID — только таймеры команды руководителя с этим id
+	ManagerID int
+}
+
+// Pause — перерыв внутри записи времени. EndTime равен nil, пока перерыв идёт.
+type Pause struct {
+	ID          int        `json:"id"`
+	TimeEntryID int        `json:"time_entry_id"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     *time.Time `json:"end_time"`
+}
+
+// Виды отрезков сессии
+const (
+	SegmentWork  = "work"
+	SegmentPause = "pause"
+)
+
+// Segment — отрезок сессии между паузами. EndTime равен nil у идущего отрезка,
+// его длительность считается до текущего момента.
+type Segment struct {
+	Kind      string     `json:"kind" enums:"work,pause"`
+	StartTime time.Time  `json:"start_time"`
+	EndTime   *time.Time `json:"end_time"`
+	Seconds   int64      `json:"seconds"`
+}
+
+// Timeline — запись времени, разбитая паузами на отрезки работы и перерывов
+type Timeline struct {
+	TimeEntry     TimeEntry `json:"time_entry"`
+	Segments      []Segment `json:"segments"`
+	WorkedSeconds int64     `json:"worked_seconds"`
+	PausedSeconds int64     `json:"paused_seconds"`
+}
+
+// Что делать при запуске таймера, если у пользователя уже идёт таймер по другой задаче
+const (
+	// TimerPolicyParallel — запустить ещё один таймер
+	TimerPolicyParallel = "parallel"
+	// TimerPolicyReject — отказать в запуске
+	TimerPolicyReject = "reject"
+	// TimerPolicySwitch — остановить идущие таймеры и запустить новый в одной транзакции
+	TimerPolicySwitch = "switch"
+)
+
+// TimerStart — ответ на запуск таймера: запущенная запись и таймеры, остановленные политикой switch
+type TimerStart struct {
+	TimeEntry TimeEntry   `json:"time_entry"`
+	Stopped   []TimeEntry `json:"stopped"`
+}
+
+var (
+	ErrTimeEntryNotFound   = apperr.New(404, "time_entry_not_found", "time entry not found")
+	ErrTimeEntryOverlap    = apperr.New(409, "time_entry_overlap", "time entry overlaps another entry")
+	ErrAnotherTimerRunning = apperr.New(409, "another_timer_running", "another task timer is already running")
+	ErrTaskAlreadyPaused   = apperr.New(409, "task_already_paused", "task already paused")
+	ErrTaskNotPaused       = apperr.New(409, "task_not_paused", "task not paused")
+)
@@ -1,18 +1,47 @@
 package models
 
-import "errors"
+import (
+	"time"
 
+	"github.com/bigxxby/effective-mobile-test/internal/apperr"
+)
+
+// User — пользователь. DeletedAt задан у мягко удалённого пользователя: его история времени
+// сохраняется, а сам он скрыт из списков и не может учитывать время.
 type User struct {
-	ID             int    `json:"id"`
-	Surname        string `json:"surname" binding:"required"`
-	Name           string `json:"name" binding:"required"`
-	PassportNumber string `json:"passport_number"`
+	ID             int        `json:"id"`
+	Surname        string     `json:"surname" binding:"required"`
+	Name           string     `json:"name" binding:"required"`
+	Patronymic     string     `json:"patronymic"`
+	Address        string     `json:"address"`
+	PassportNumber string     `json:"passport_number"`
+	Role           string     `json:"role"`
+	ManagerID      *int       `json:"manager_id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	DeletedAt      *time.Time `json:"deleted_at"`
+}
+
+const (
+	RoleAdmin    = "admin"
+	RoleManager  = "manager"
+	RoleEmployee = "employee"
+)
+
+// UserRoleData — тело запроса PUT /api/users/{id}/role
+type UserRoleData struct {
+	Role      string `json:"role" binding:"required" enums:"admin,manager,employee"`
+	ManagerID *int   `json:"manager_id"`
 }
 
 type UserData struct {
-	PassportNumber string `json:"passport_number" binding:"required"`
+	PassportNumber string `json:"passport_number" binding:"required" example:"1234 567890"`
 }
 
 var (
-	ErrUserAlreadyExists = errors.New("user already exists")
+	ErrUserAlreadyExists     = apperr.New(409, "user_already_exists", "user already exists")
+	ErrInvalidPassportNumber = apperr.New(400, "invalid_passport_number", "invalid passport number").WithDetail("format", "1234 567890")
+	ErrInvalidRole           = apperr.New(400, "invalid_role", "invalid role").WithDetail("allowed", []string{RoleAdmin, RoleManager, RoleEmployee})
+	ErrInvalidManager        = apperr.New(400, "invalid_manager", "manager should be another existing user with the manager or admin role")
+	ErrUserNotDeleted        = apperr.New(409, "user_not_deleted", "user is not deleted")
 )
@@ -1,8 +1,23 @@
 package models
 
+import "time"
+
+// UserWorkload — суммарное время пользователя по одной задаче
 type UserWorkload struct {
-	TaskID       int
-	TaskName     string
-	TotalHours   int
-	TotalMinutes int
+	TaskID       int    `json:"task_id"`
+	TaskName     string `json:"task_name"`
+	TotalSeconds int64  `json:"total_seconds"`
+	TotalHours   int    `json:"total_hours"`
+	TotalMinutes int    `json:"total_minutes"`
+}
+
+// UserWorkloadReport — трудозатраты пользователя за период по убыванию времени
+type UserWorkloadReport struct {
+	UserID        int            `json:"user_id"`
+	StartDate     time.Time      `json:"start_date"`
+	EndDate       time.Time      `json:"end_date"`
+	UserWorkloads []UserWorkload `json:"user_workloads"`
+	TotalSeconds  int64          `json:"total_seconds"`
+	TotalHours    int            `json:"total_hours"`
+	TotalMinutes  int            `json:"total_minutes"`
 }
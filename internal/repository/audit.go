@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/audit"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/lib/pq"
+)
+
+// Тип сущности аудита для таблиц, изменения которых записываются в журнал
+var auditEntities = map[string]string{
+	"users":           models.AuditEntityUser,
+	"tasks":           models.AuditEntityTask,
+	"projects":        models.AuditEntityProject,
+	"task_logs":       models.AuditEntityTimeEntry,
+	"task_log_pauses": models.AuditEntityPause,
+}
+
+// auditRows — строки таблицы, которые меняет транзакция, с их состоянием до изменения
+type auditRows struct {
+	table  string
+	ids    []int
+	before map[int][]byte
+}
+
+// lockForAudit блокирует до конца транзакции строки table, подходящие под where,
+// и запоминает их состояние до изменения. Аргументы where нумеруются с $1.
+func lockForAudit(ctx context.Context, tx *sql.Tx, table, where string, args ...any) (auditRows, error) {
+	rows := auditRows{table: table, before: make(map[int][]byte)}
+	query := "SELECT id, to_jsonb(t) FROM " + table + " t WHERE " + where + " ORDER BY id FOR UPDATE"
+	result, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return auditRows{}, err
+	}
+	defer result.Close()
+	for result.Next() {
+		var id int
+		var row []byte
+		if err := result.Scan(&id, &row); err != nil {
+			return auditRows{}, err
+		}
+		rows.ids = append(rows.ids, id)
+		rows.before[id] = row
+	}
+	return rows, result.Err()
+}
+
+// lockOneForAudit — lockForAudit для изменения одной строки, sql.ErrNoRows если подходящей строки нет
+func lockOneForAudit(ctx context.Context, tx *sql.Tx, table, where string, args ...any) (auditRows, error) {
+	rows, err := lockForAudit(ctx, tx, table, where, args...)
+	if err != nil {
+		return auditRows{}, err
+	}
+	if len(rows.ids) == 0 {
+		return auditRows{}, sql.ErrNoRows
+	}
+	return rows, nil
+}
+
+// createdForAudit — только что вставленная строка, у которой нет состояния до изменения
+func createdForAudit(table string, id int) auditRows {
+	return auditRows{table: table, ids: []int{id}}
+}
+
+// write записывает событие action для каждой строки с её состоянием после изменения;
+// удалённые строки записываются без состояния после
+func (a auditRows) write(ctx context.Context, tx *sql.Tx, action string) error {
+	if len(a.ids) == 0 {
+		return nil
+	}
+	query := "SELECT id, to_jsonb(t) FROM " + a.table + " t WHERE id = ANY($1)"
+	result, err := tx.QueryContext(ctx, query, pq.Array(a.ids))
+	if err != nil {
+		return err
+	}
+	after := make(map[int][]byte)
+	for result.Next() {
+		var id int
+		var row []byte
+		if err := result.Scan(&id, &row); err != nil {
+			result.Close()
+			return err
+		}
+		after[id] = row
+	}
+	result.Close()
+	if err := result.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range a.ids {
+		if err := writeAuditEvent(ctx, tx, action, auditEntities[a.table], id, a.before[id], after[id]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeAuditEvent записывает событие от имени автора из ctx, оставляя в before и after только различия
+func writeAuditEvent(ctx context.Context, tx *sql.Tx, action, entity string, entityID int, before, after []byte) error {
+	before, after, err := audit.Diff(before, after)
+	if err != nil {
+		return err
+	}
+	var actorID sql.NullInt64
+	var actorRole sql.NullString
+	if actor, ok := audit.ActorFrom(ctx); ok {
+		actorID = sql.NullInt64{Int64: int64(actor.UserID), Valid: true}
+		actorRole = sql.NullString{String: actor.Role, Valid: actor.Role != ""}
+	}
+	query := `
+		INSERT INTO audit_events (actor_id, actor_role, action, entity_type, entity_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err = tx.ExecContext(ctx, query, actorID, actorRole, action, entity, entityID, nullJSON(before), nullJSON(after))
+	return err
+}
+
+// memberJSON — состояние участия пользователя в проекте для событий аудита проекта
+func memberJSON(userID int) []byte {
+	return []byte(`{"user_id": ` + strconv.Itoa(userID) + `}`)
+}
+
+// nullJSON передаёт пустое состояние как NULL
+func nullJSON(value []byte) any {
+	if value == nil {
+		return nil
+	}
+	return string(value)
+}
+
+// События аудита от новых к старым
+func (r *Repository) GetAuditEvents(ctx context.Context, filter models.AuditFilter, pagination models.Pagination) (models.Page[models.AuditEvent], error) {
+	where := ""
+	var args []interface{}
+	argCount := 1
+
+	if filter.EntityType != "" {
+		where += " AND entity_type = $" + strconv.Itoa(argCount)
+		args = append(args, filter.EntityType)
+		argCount++
+	}
+	if filter.EntityID != 0 {
+		where += " AND entity_id = $" + strconv.Itoa(argCount)
+		args = append(args, filter.EntityID)
+		argCount++
+	}
+	if filter.ActorID != 0 {
+		where += " AND actor_id = $" + strconv.Itoa(argCount)
+		args = append(args, filter.ActorID)
+		argCount++
+	}
+	if filter.Action != "" {
+		where += " AND action = $" + strconv.Itoa(argCount)
+		args = append(args, filter.Action)
+		argCount++
+	}
+	if !filter.From.IsZero() {
+		where += " AND created_at >= $" + strconv.Itoa(argCount)
+		args = append(args, filter.From)
+		argCount++
+	}
+	if !filter.To.IsZero() {
+		where += " AND created_at < $" + strconv.Itoa(argCount)
+		args = append(args, filter.To)
+		argCount++
+	}
+
+	var total int
+	if err := r.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_events WHERE 1=1"+where, args...).Scan(&total); err != nil {
+		return models.Page[models.AuditEvent]{}, err
+	}
+
+	cursorWhere, tail, pageArgs := pageClause("id", "desc", pagination, argCount)
+	query := `
+		SELECT id, actor_id, COALESCE(actor_role, ''), action, entity_type, entity_id, before, after, created_at
+		FROM audit_events
+		WHERE 1=1` + where + cursorWhere + tail
+	args = append(args, pageArgs...)
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return models.Page[models.AuditEvent]{}, err
+	}
+	defer rows.Close()
+
+	var events []models.AuditEvent
+	for rows.Next() {
+		var event models.AuditEvent
+		var before, after []byte
+		err := rows.Scan(&event.ID, &event.ActorID, &event.ActorRole, &event.Action, &event.EntityType, &event.EntityID, &before, &after, &event.CreatedAt)
+		if err != nil {
+			return models.Page[models.AuditEvent]{}, err
+		}
+		if before != nil {
+			event.Before = json.RawMessage(before)
+		}
+		if after != nil {
+			event.After = json.RawMessage(after)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Page[models.AuditEvent]{}, err
+	}
+
+	return newPage(events, total, pagination, "id", "desc", auditSortKey), nil
+}
+
+// auditSortKey — ключ сортировки журнала: события идут по id от новых к старым
+func auditSortKey(event models.AuditEvent) (string, int64) {
+	return "", event.ID
+}
+
+// Удаляет события аудита старше olderThan, возвращает число удалённых
+func (r *Repository) DeleteAuditEvents(ctx context.Context, olderThan time.Duration) (int, error) {
+	query := "DELETE FROM audit_events WHERE created_at < LOCALTIMESTAMP - $1::float8 * INTERVAL '1 second'"
+	res, err := r.DB.ExecContext(ctx, query, olderThan.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	return int(count), err
+}
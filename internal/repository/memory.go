@@ -0,0 +1,1191 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/bigxxby/effective-mobile-test/internal/audit"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+)
+
+// Memory — потокобезопасная реализация хранилища в памяти для тестов.
+// Повторяет поведение Repository: уникальные номера паспортов,
+// каскадное удаление логов вместе с пользователем и проверку открытого лога.
+type Memory struct {
+	mu sync.RWMutex
+
+	users    map[int]models.User
+	tasks    map[int]models.Task
+	logs     map[int]memoryTaskLog
+	projects map[int]models.Project
+	members  map[int]map[int]bool
+	// перерывы по id записи времени
+	pauses map[int][]models.Pause
+
+	lastUserID    int
+	lastTaskID    int
+	lastLogID     int
+	lastProjectID int
+	lastPauseID   int
+
+	auditEvents []models.AuditEvent
+	lastAuditID int64
+
+	// Now возвращает текущее время, в тестах его можно подменить
+	Now func() time.Time
+}
+
+// Аналог нарушения внешнего ключа в PostgreSQL
+var errForeignKey = errors.New("memory: foreign key violation")
+
+type memoryTaskLog struct {
+	ID          int
+	UserID      int
+	TaskID      int
+	StartTime   time.Time
+	EndTime     *time.Time
+	Note        string
+	NeedsReview bool
+	AutoClosed  bool
+}
+
+func (l memoryTaskLog) toTimeEntry() models.TimeEntry {
+	return models.TimeEntry{
+		ID:          l.ID,
+		UserID:      l.UserID,
+		TaskID:      l.TaskID,
+		StartTime:   l.StartTime,
+		EndTime:     l.EndTime,
+		Note:        l.Note,
+		NeedsReview: l.NeedsReview,
+		AutoClosed:  l.AutoClosed,
+	}
+}
+
+// Конец интервала; запущенный таймер идёт до now
+func (l memoryTaskLog) end(now time.Time) time.Time {
+	if l.EndTime == nil {
+		return now
+	}
+	return *l.EndTime
+}
+
+// Длительность части интервала, попадающей в [from, to)
+func (l memoryTaskLog) clip(from, to, now time.Time) time.Duration {
+	start, end := l.StartTime, l.end(now)
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// Отработанное время записи внутри [from, to): длительность без перерывов.
+// Незакрытый перерыв длится до конца записи.
+func (m *Memory) worked(log memoryTaskLog, from, to, now time.Time) time.Duration {
+	total := log.clip(from, to, now)
+	if total <= 0 {
+		return 0
+	}
+	logEnd := log.end(now)
+	for _, pause := range m.pauses[log.ID] {
+		start, end := pause.StartTime, logEnd
+		if pause.EndTime != nil && pause.EndTime.Before(end) {
+			end = *pause.EndTime
+		}
+		if start.Before(log.StartTime) {
+			start = log.StartTime
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			total -= end.Sub(start)
+		}
+	}
+	return total
+}
+
+// Удаление записи вместе с её перерывами, как ON DELETE CASCADE
+func (m *Memory) deleteLog(logID int) {
+	delete(m.logs, logID)
+	delete(m.pauses, logID)
+}
+
+// Закрытие идущего перерыва записи временем at, но не раньше его начала; false — перерыв не идёт
+func (m *Memory) closePause(logID int, at time.Time) (models.Pause, bool) {
+	for i, pause := range m.pauses[logID] {
+		if pause.EndTime == nil {
+			if at.Before(pause.StartTime) {
+				at = pause.StartTime
+			}
+			pause.EndTime = &at
+			m.pauses[logID][i] = pause
+			return pause, true
+		}
+	}
+	return models.Pause{}, false
+}
+
+// audit записывает событие, как Repository: before и after — состояния сущности до и после изменения,
+// nil если сущности нет. Состояния — структуры, поэтому audit.Diff не возвращает ошибку.
+func (m *Memory) audit(ctx context.Context, action, entity string, id int, before, after any) {
+	beforeJSON, afterJSON := stateJSON(before), stateJSON(after)
+	beforeJSON, afterJSON, _ = audit.Diff(beforeJSON, afterJSON)
+	m.lastAuditID++
+	event := models.AuditEvent{
+		ID:         m.lastAuditID,
+		Action:     action,
+		EntityType: entity,
+		EntityID:   id,
+		Before:     beforeJSON,
+		After:      afterJSON,
+		CreatedAt:  m.Now(),
+	}
+	if actor, ok := audit.ActorFrom(ctx); ok {
+		event.ActorID = &actor.UserID
+		event.ActorRole = actor.Role
+	}
+	m.auditEvents = append(m.auditEvents, event)
+}
+
+// memberState — участие пользователя в проекте, как memberJSON
+type memberState struct {
+	UserID int `json:"user_id"`
+}
+
+func stateJSON(state any) []byte {
+	if state == nil {
+		return nil
+	}
+	data, _ := json.Marshal(state)
+	return data
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		users:    make(map[int]models.User),
+		tasks:    make(map[int]models.Task),
+		logs:     make(map[int]memoryTaskLog),
+		projects: make(map[int]models.Project),
+		members:  make(map[int]map[int]bool),
+		pauses:   make(map[int][]models.Pause),
+		Now:      time.Now,
+	}
+}
+
+// Получение всех пользователей
+func (m *Memory) GetUsers(ctx context.Context, filter models.Filter, pagination models.Pagination, sortBy, sortOrder string) (models.Page[models.User], error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var users []models.User
+	for _, user := range m.users {
+		if !filter.IncludeDeleted && user.DeletedAt != nil {
+			continue
+		}
+		if !matchUser(user, filter) {
+			continue
+		}
+		users = append(users, user)
+	}
+
+	return memoryPage(users, pagination, sortBy, sortOrder, userSortKey(sortBy)), nil
+}
+
+// matchUser проверяет пользователя на условия фильтра, как GetUsers репозитория
+func matchUser(user models.User, filter models.Filter) bool {
+	switch {
+	case !matchText(user.PassportNumber, filter.PassportNumber),
+		!matchText(user.Surname, filter.Surname),
+		!matchText(user.Name, filter.Name),
+		filter.Search != "" && !matchSearch(filter.Search, user.Surname, user.Name, user.Patronymic, user.Address),
+		filter.ID.Min != 0 && user.ID < filter.ID.Min,
+		filter.ID.Max != 0 && user.ID > filter.ID.Max,
+		len(filter.ID.In) > 0 && !slices.Contains(filter.ID.In, user.ID),
+		filter.ManagerID != 0 && (user.ManagerID == nil || *user.ManagerID != filter.ManagerID),
+		!matchPeriod(user.CreatedAt, filter.CreatedAt),
+		!matchPeriod(user.UpdatedAt, filter.UpdatedAt):
+		return false
+	}
+	return true
+}
+
+// matchText сравнивает строку с условием, как textCondition; пустое значение условия подходит всем
+func matchText(value string, match models.TextMatch) bool {
+	if match.Value == "" {
+		return true
+	}
+	switch match.Mode {
+	case models.MatchIExact:
+		return strings.ToLower(value) == strings.ToLower(match.Value)
+	case models.MatchPrefix:
+		return strings.HasPrefix(value, match.Value)
+	case models.MatchIPrefix:
+		return strings.HasPrefix(strings.ToLower(value), strings.ToLower(match.Value))
+	case models.MatchContains:
+		return strings.Contains(value, match.Value)
+	case models.MatchIContains:
+		return strings.Contains(strings.ToLower(value), strings.ToLower(match.Value))
+	default:
+		return value == match.Value
+	}
+}
+
+// matchSearch — упрощённый plainto_tsquery('simple'): каждое слово запроса есть среди слов полей
+func matchSearch(query string, fields ...string) bool {
+	words := searchWords(query)
+	if len(words) == 0 {
+		return false
+	}
+	document := searchWords(strings.Join(fields, " "))
+	for _, word := range words {
+		if !slices.Contains(document, word) {
+			return false
+		}
+	}
+	return true
+}
+
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func matchPeriod(t time.Time, period models.TimeRange) bool {
+	return (period.From.IsZero() || !t.Before(period.From)) && (period.To.IsZero() || t.Before(period.To))
+}
+
+func (m *Memory) GetUser(ctx context.Context, userID int) (models.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	user, ok := m.users[userID]
+	if !ok {
+		return models.User{}, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (m *Memory) UserExistsByPassportNumber(ctx context.Context, passportNumber string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.userExistsByPassportNumber(passportNumber), nil
+}
+
+func (m *Memory) userExistsByPassportNumber(passportNumber string) bool {
+	for _, user := range m.users {
+		if user.PassportNumber == passportNumber {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Memory) CreateUser(ctx context.Context, user models.User) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.userExistsByPassportNumber(user.PassportNumber) {
+		return 0, models.ErrUserAlreadyExists
+	}
+	m.lastUserID++
+	user.ID = m.lastUserID
+	user.Role = models.RoleEmployee
+	user.ManagerID = nil
+	user.CreatedAt = m.Now()
+	user.UpdatedAt = user.CreatedAt
+	m.users[user.ID] = user
+	m.audit(ctx, models.AuditUserCreate, models.AuditEntityUser, user.ID, nil, user)
+	return user.ID, nil
+}
+
+func (m *Memory) UpdateUserRole(ctx context.Context, userID int, role string, managerID *int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[userID]
+	if !ok || user.DeletedAt != nil {
+		return sql.ErrNoRows
+	}
+	if managerID != nil {
+		if _, ok := m.users[*managerID]; !ok {
+			return errForeignKey
+		}
+	}
+	before := user
+	user.Role = role
+	user.ManagerID = managerID
+	user.UpdatedAt = m.Now()
+	m.users[userID] = user
+	m.audit(ctx, models.AuditUserUpdateRole, models.AuditEntityUser, userID, before, user)
+	return nil
+}
+
+func (m *Memory) UpdateUser(ctx context.Context, userID int, user models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.users[userID]
+	if !ok || existing.DeletedAt != nil {
+		return sql.ErrNoRows
+	}
+	before := existing
+	existing.Surname = user.Surname
+	existing.Name = user.Name
+	if user.Patronymic != "" {
+		existing.Patronymic = user.Patronymic
+	}
+	if user.Address != "" {
+		existing.Address = user.Address
+	}
+	existing.UpdatedAt = m.Now()
+	m.users[userID] = existing
+	m.audit(ctx, models.AuditUserUpdate, models.AuditEntityUser, userID, before, existing)
+	return nil
+}
+
+// Мягкое удаление пользователя с остановкой его запущенных таймеров
+func (m *Memory) DeleteUser(ctx context.Context, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[userID]
+	if !ok || user.DeletedAt != nil {
+		return sql.ErrNoRows
+	}
+	before := user
+	now := m.Now()
+	user.DeletedAt = &now
+	user.UpdatedAt = now
+	m.users[userID] = user
+	m.audit(ctx, models.AuditUserDelete, models.AuditEntityUser, userID, before, user)
+	for id, log := range m.logs {
+		if log.UserID != userID || log.EndTime != nil {
+			continue
+		}
+		stopped := log
+		end := now
+		stopped.EndTime = &end
+		stopped.AutoClosed = true
+		stopped.NeedsReview = true
+		m.logs[id] = stopped
+		m.closePause(id, now)
+		m.audit(ctx, models.AuditTimerAutoStop, models.AuditEntityTimeEntry, id, log.toTimeEntry(), stopped.toTimeEntry())
+	}
+	return nil
+}
+
+func (m *Memory) RestoreUser(ctx context.Context, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[userID]
+	if !ok || user.DeletedAt == nil {
+		return sql.ErrNoRows
+	}
+	before := user
+	user.DeletedAt = nil
+	user.UpdatedAt = m.Now()
+	m.users[userID] = user
+	m.audit(ctx, models.AuditUserRestore, models.AuditEntityUser, userID, before, user)
+	return nil
+}
+
+// Окончательное удаление пользователя вместе с его логами, как ON DELETE CASCADE
+func (m *Memory) PurgeUser(ctx context.Context, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[userID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	delete(m.users, userID)
+	for id, user := range m.users {
+		if user.ManagerID != nil && *user.ManagerID == userID {
+			user.ManagerID = nil
+			m.users[id] = user
+		}
+	}
+	for id, log := range m.logs {
+		if log.UserID == userID {
+			m.deleteLog(id)
+		}
+	}
+	for _, members := range m.members {
+		delete(members, userID)
+	}
+	m.audit(ctx, models.AuditUserPurge, models.AuditEntityUser, userID, user, nil)
+	return nil
+}
+
+// Получение рабочей нагрузки пользователя по задачам за период, с обрезкой по границам
+func (m *Memory) GetUserWorkloadsByUserID(ctx context.Context, userID int, startDate, endDate time.Time) ([]models.UserWorkload, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := m.Now()
+	totals := make(map[int]time.Duration)
+	for _, log := range m.logs {
+		if log.UserID != userID {
+			continue
+		}
+		if d := m.worked(log, startDate, endDate, now); d > 0 {
+			totals[log.TaskID] += d
+		}
+	}
+
+	var userWorkloads []models.UserWorkload
+	for taskID, total := range totals {
+		userWorkloads = append(userWorkloads, models.UserWorkload{
+			TaskID:       taskID,
+			TaskName:     m.tasks[taskID].Name,
+			TotalSeconds: int64(total.Seconds()),
+		})
+	}
+	sort.Slice(userWorkloads, func(i, j int) bool {
+		if userWorkloads[i].TotalSeconds != userWorkloads[j].TotalSeconds {
+			return userWorkloads[i].TotalSeconds > userWorkloads[j].TotalSeconds
+		}
+		return userWorkloads[i].TaskID < userWorkloads[j].TaskID
+	})
+	return userWorkloads, nil
+}
+
+// Создание задачи
+func (m *Memory) CreateTask(ctx context.Context, task models.Task) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if task.Status == "" {
+		task.Status = models.TaskStatusTodo
+	}
+	if task.ProjectID != nil {
+		if _, ok := m.projects[*task.ProjectID]; !ok {
+			return 0, errForeignKey
+		}
+	}
+	m.lastTaskID++
+	task.ID = m.lastTaskID
+	task.CreatedAt = m.Now()
+	m.tasks[task.ID] = task
+	m.audit(ctx, models.AuditTaskCreate, models.AuditEntityTask, task.ID, nil, task)
+	return task.ID, nil
+}
+
+// Получение всех задач
+func (m *Memory) GetTasks(ctx context.Context, filter models.TaskFilter, pagination models.Pagination, sortBy, sortOrder string) (models.Page[models.Task], error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var tasks []models.Task
+	for _, task := range m.tasks {
+		if filter.Name != "" && task.Name != filter.Name {
+			continue
+		}
+		if filter.Status != "" && task.Status != filter.Status {
+			continue
+		}
+		if filter.ProjectID != 0 && (task.ProjectID == nil || *task.ProjectID != filter.ProjectID) {
+			continue
+		}
+		if !filter.IncludeArchived && task.Archived {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+
+	return memoryPage(tasks, pagination, sortBy, sortOrder, taskSortKey(sortBy)), nil
+}
+
+func (m *Memory) GetTask(ctx context.Context, taskID int) (models.Task, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	task, ok := m.tasks[taskID]
+	if !ok {
+		return models.Task{}, sql.ErrNoRows
+	}
+	return task, nil
+}
+
+func (m *Memory) UpdateTask(ctx context.Context, taskID int, task models.Task) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.tasks[taskID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if task.ProjectID != nil {
+		if _, ok := m.projects[*task.ProjectID]; !ok {
+			return errForeignKey
+		}
+	}
+	before := existing
+	existing.ProjectID = task.ProjectID
+	existing.Name = task.Name
+	existing.Description = task.Description
+	existing.Status = task.Status
+	existing.Archived = task.Archived
+	m.tasks[taskID] = existing
+	m.audit(ctx, models.AuditTaskUpdate, models.AuditEntityTask, taskID, before, existing)
+	return nil
+}
+
+// Удаление задачи вместе с её логами, как ON DELETE CASCADE
+func (m *Memory) DeleteTask(ctx context.Context, taskID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[taskID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	for _, log := range m.logs {
+		if log.TaskID == taskID {
+			return models.ErrTaskHasLogs
+		}
+	}
+	delete(m.tasks, taskID)
+	m.audit(ctx, models.AuditTaskDelete, models.AuditEntityTask, taskID, task, nil)
+	return nil
+}
+
+func (m *Memory) openLog(userID, taskID int) (memoryTaskLog, bool) {
+	for _, log := range m.logs {
+		if log.UserID == userID && log.TaskID == taskID && log.EndTime == nil {
+			return log, true
+		}
+	}
+	return memoryTaskLog{}, false
+}
+
+// Запуск задачи. Как и Postgres-реализация, требует существующих пользователя и задачу,
+// не в архиве, отклоняет второй открытый таймер по той же задаче и применяет policy
+// к таймерам пользователя по другим задачам.
+func (m *Memory) StartTask(ctx context.Context, userID, taskID int, policy string) (models.TimerStart, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[userID]; !ok {
+		return models.TimerStart{}, models.ErrUserNotFound
+	}
+	task, ok := m.tasks[taskID]
+	if !ok {
+		return models.TimerStart{}, models.ErrTaskNotFound
+	}
+	if task.Archived {
+		return models.TimerStart{}, models.ErrTaskArchived
+	}
+	if _, ok := m.openLog(userID, taskID); ok {
+		return models.TimerStart{}, models.ErrTaskAlreadyStarted
+	}
+
+	result := models.TimerStart{Stopped: []models.TimeEntry{}}
+	now := m.Now()
+	if policy == models.TimerPolicyReject || policy == models.TimerPolicySwitch {
+		var running []int
+		for id, log := range m.logs {
+			if log.UserID == userID && log.EndTime == nil {
+				running = append(running, id)
+			}
+		}
+		sort.Ints(running)
+		if len(running) > 0 && policy == models.TimerPolicyReject {
+			return models.TimerStart{}, models.ErrAnotherTimerRunning.WithDetail("task_id", m.logs[running[0]].TaskID)
+		}
+		for _, id := range running {
+			before := m.logs[id]
+			log := before
+			log.EndTime = &now
+			m.logs[id] = log
+			m.closePause(id, now)
+			result.Stopped = append(result.Stopped, log.toTimeEntry())
+			m.audit(ctx, models.AuditTimerStop, models.AuditEntityTimeEntry, id, before.toTimeEntry(), log.toTimeEntry())
+		}
+	}
+
+	m.lastLogID++
+	log := memoryTaskLog{
+		ID:        m.lastLogID,
+		UserID:    userID,
+		TaskID:    taskID,
+		StartTime: now,
+	}
+	m.logs[log.ID] = log
+	result.TimeEntry = log.toTimeEntry()
+	m.audit(ctx, models.AuditTimerStart, models.AuditEntityTimeEntry, log.ID, nil, result.TimeEntry)
+	return result, nil
+}
+
+// Завершение задачи
+func (m *Memory) EndTask(ctx context.Context, userID, taskID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	log, ok := m.openLog(userID, taskID)
+	if !ok {
+		return models.ErrTaskNotStarted
+	}
+	before := log.toTimeEntry()
+	now := m.Now()
+	log.EndTime = &now
+	m.logs[log.ID] = log
+	m.closePause(log.ID, now)
+	m.audit(ctx, models.AuditTimerStop, models.AuditEntityTimeEntry, log.ID, before, log.toTimeEntry())
+	return nil
+}
+
+// Пауза запущенного таймера
+func (m *Memory) PauseTask(ctx context.Context, userID, taskID int) (models.Pause, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	log, ok := m.openLog(userID, taskID)
+	if !ok {
+		return models.Pause{}, models.ErrTaskNotStarted
+	}
+	for _, pause := range m.pauses[log.ID] {
+		if pause.EndTime == nil {
+			return models.Pause{}, models.ErrTaskAlreadyPaused
+		}
+	}
+	m.lastPauseID++
+	pause := models.Pause{ID: m.lastPauseID, TimeEntryID: log.ID, StartTime: m.Now()}
+	m.pauses[log.ID] = append(m.pauses[log.ID], pause)
+	m.audit(ctx, models.AuditTimerPause, models.AuditEntityPause, pause.ID, nil, pause)
+	return pause, nil
+}
+
+// Продолжение таймера: закрывает идущий перерыв
+func (m *Memory) ResumeTask(ctx context.Context, userID, taskID int) (models.Pause, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	log, ok := m.openLog(userID, taskID)
+	if !ok {
+		return models.Pause{}, models.ErrTaskNotStarted
+	}
+	pause, ok := m.closePause(log.ID, m.Now())
+	if !ok {
+		return models.Pause{}, models.ErrTaskNotPaused
+	}
+	before := pause
+	before.EndTime = nil
+	m.audit(ctx, models.AuditTimerResume, models.AuditEntityPause, pause.ID, before, pause)
+	return pause, nil
+}
+
+// Перерывы записи времени в порядке начала
+func (m *Memory) GetPauses(ctx context.Context, entryID int) ([]models.Pause, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	pauses := append([]models.Pause{}, m.pauses[entryID]...)
+	sort.Slice(pauses, func(i, j int) bool { return pauses[i].StartTime.Before(pauses[j].StartTime) })
+	return pauses, nil
+}
+
+// Получение всех проектов
+func (m *Memory) GetProjects(ctx context.Context, pagination models.Pagination) (models.Page[models.Project], error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var projects []models.Project
+	for _, project := range m.projects {
+		projects = append(projects, project)
+	}
+	return memoryPage(projects, pagination, "id", "asc", projectSortKey), nil
+}
+
+func (m *Memory) GetProject(ctx context.Context, projectID int) (models.Project, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	project, ok := m.projects[projectID]
+	if !ok {
+		return models.Project{}, sql.ErrNoRows
+	}
+	return project, nil
+}
+
+// Создание проекта
+func (m *Memory) CreateProject(ctx context.Context, project models.Project) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastProjectID++
+	project.ID = m.lastProjectID
+	project.CreatedAt = m.Now()
+	m.projects[project.ID] = project
+	m.audit(ctx, models.AuditProjectCreate, models.AuditEntityProject, project.ID, nil, project)
+	return project.ID, nil
+}
+
+func (m *Memory) UpdateProject(ctx context.Context, projectID int, project models.Project) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.projects[projectID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	before := existing
+	existing.Name = project.Name
+	existing.Description = project.Description
+	m.projects[projectID] = existing
+	m.audit(ctx, models.AuditProjectUpdate, models.AuditEntityProject, projectID, before, existing)
+	return nil
+}
+
+// Удаление проекта. Как и внешний ключ tasks.project_id, запрещено при наличии задач.
+func (m *Memory) DeleteProject(ctx context.Context, projectID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	project, ok := m.projects[projectID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	for _, task := range m.tasks {
+		if task.ProjectID != nil && *task.ProjectID == projectID {
+			return models.ErrProjectHasTasks
+		}
+	}
+	delete(m.projects, projectID)
+	delete(m.members, projectID)
+	m.audit(ctx, models.AuditProjectDelete, models.AuditEntityProject, projectID, project, nil)
+	return nil
+}
+
+// Получение участников проекта
+func (m *Memory) GetProjectMembers(ctx context.Context, projectID int) ([]models.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var users []models.User
+	for userID := range m.members[projectID] {
+		users = append(users, m.users[userID])
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (m *Memory) AddProjectMember(ctx context.Context, projectID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.projects[projectID]; !ok {
+		return errForeignKey
+	}
+	if _, ok := m.users[userID]; !ok {
+		return errForeignKey
+	}
+	if m.members[projectID][userID] {
+		return models.ErrMemberAlreadyExists
+	}
+	if m.members[projectID] == nil {
+		m.members[projectID] = make(map[int]bool)
+	}
+	m.members[projectID][userID] = true
+	m.audit(ctx, models.AuditProjectAddMember, models.AuditEntityProject, projectID, nil, memberState{userID})
+	return nil
+}
+
+func (m *Memory) RemoveProjectMember(ctx context.Context, projectID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.members[projectID][userID] {
+		return sql.ErrNoRows
+	}
+	delete(m.members[projectID], userID)
+	m.audit(ctx, models.AuditProjectRemoveMember, models.AuditEntityProject, projectID, memberState{userID}, nil)
+	return nil
+}
+
+func (m *Memory) IsProjectMember(ctx context.Context, projectID, userID int) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.members[projectID][userID], nil
+}
+
+// Суммарное время по задачам проекта в разрезе пользователей, с обрезкой по границам периода.
+// Ненулевой managerID оставляет только время команды этого руководителя.
+func (m *Memory) GetProjectWorkloads(ctx context.Context, projectID, managerID int, startDate, endDate time.Time) ([]models.ProjectWorkloadEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := m.Now()
+	type key struct{ taskID, userID int }
+	totals := make(map[key]float64)
+	for _, log := range m.logs {
+		task := m.tasks[log.TaskID]
+		if task.ProjectID == nil || *task.ProjectID != projectID {
+			continue
+		}
+		if managerID != 0 {
+			if user := m.users[log.UserID]; user.ManagerID == nil || *user.ManagerID != managerID {
+				continue
+			}
+		}
+		if d := m.worked(log, startDate, endDate, now); d > 0 {
+			totals[key{log.TaskID, log.UserID}] += d.Seconds()
+		}
+	}
+
+	var entries []models.ProjectWorkloadEntry
+	for k, total := range totals {
+		entries = append(entries, models.ProjectWorkloadEntry{
+			TaskID:       k.taskID,
+			TaskName:     m.tasks[k.taskID].Name,
+			UserID:       k.userID,
+			TotalSeconds: total,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].TaskID != entries[j].TaskID {
+			return entries[i].TaskID < entries[j].TaskID
+		}
+		return entries[i].UserID < entries[j].UserID
+	})
+	return entries, nil
+}
+
+// Получение записей времени пользователя, пересекающих период
+func (m *Memory) GetTimeEntries(ctx context.Context, userID int, startDate, endDate time.Time, pagination models.Pagination) (models.Page[models.TimeEntry], error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := m.Now()
+	var entries []models.TimeEntry
+	for _, log := range m.logs {
+		if log.UserID != userID {
+			continue
+		}
+		if !startDate.IsZero() && !log.end(now).After(startDate) {
+			continue
+		}
+		if !endDate.IsZero() && !log.StartTime.Before(endDate) {
+			continue
+		}
+		entries = append(entries, log.toTimeEntry())
+	}
+	return memoryPage(entries, pagination, "start_time", "desc", timeEntrySortKey), nil
+}
+
+func (m *Memory) GetTimeEntry(ctx context.Context, entryID int) (models.TimeEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	log, ok := m.logs[entryID]
+	if !ok {
+		return models.TimeEntry{}, sql.ErrNoRows
+	}
+	return log.toTimeEntry(), nil
+}
+
+// Создание записи времени с явными началом и концом
+func (m *Memory) CreateTimeEntry(ctx context.Context, entry models.TimeEntry) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[entry.UserID]; !ok {
+		return 0, errForeignKey
+	}
+	if _, ok := m.tasks[entry.TaskID]; !ok {
+		return 0, errForeignKey
+	}
+	if m.hasOverlappingTimeEntry(entry, 0) {
+		return 0, models.ErrTimeEntryOverlap
+	}
+	m.lastLogID++
+	log := memoryTaskLog{
+		ID:        m.lastLogID,
+		UserID:    entry.UserID,
+		TaskID:    entry.TaskID,
+		StartTime: entry.StartTime,
+		EndTime:   entry.EndTime,
+		Note:      entry.Note,
+	}
+	m.logs[log.ID] = log
+	m.audit(ctx, models.AuditTimeEntryCreate, models.AuditEntityTimeEntry, log.ID, nil, log.toTimeEntry())
+	return log.ID, nil
+}
+
+func (m *Memory) UpdateTimeEntry(ctx context.Context, entryID int, entry models.TimeEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	log, ok := m.logs[entryID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if _, ok := m.tasks[entry.TaskID]; !ok {
+		return errForeignKey
+	}
+	if m.hasOverlappingTimeEntry(entry, entryID) {
+		return models.ErrTimeEntryOverlap
+	}
+	before := log.toTimeEntry()
+	log.TaskID = entry.TaskID
+	log.StartTime = entry.StartTime
+	log.EndTime = entry.EndTime
+	log.Note = entry.Note
+	log.NeedsReview = false
+	m.logs[entryID] = log
+	if entry.EndTime != nil {
+		m.closePause(entryID, *entry.EndTime)
+	}
+	m.audit(ctx, models.AuditTimeEntryUpdate, models.AuditEntityTimeEntry, entryID, before, log.toTimeEntry())
+	return nil
+}
+
+func (m *Memory) DeleteTimeEntry(ctx context.Context, entryID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	log, ok := m.logs[entryID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	m.deleteLog(entryID)
+	m.audit(ctx, models.AuditTimeEntryDelete, models.AuditEntityTimeEntry, entryID, log.toTimeEntry(), nil)
+	return nil
+}
+
+// hasOverlappingTimeEntry — пересекает ли запись другую запись пользователя, как checkTimeEntryOverlap.
+// Вызывается под m.mu вместе с записью.
+func (m *Memory) hasOverlappingTimeEntry(entry models.TimeEntry, excludeID int) bool {
+	now := m.Now()
+	for _, log := range m.logs {
+		if log.UserID != entry.UserID || log.ID == excludeID {
+			continue
+		}
+		if log.StartTime.Before(*entry.EndTime) && log.end(now).After(entry.StartTime) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Memory) StopOpenTimers(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.Now()
+	count := 0
+	for id, log := range m.logs {
+		if log.EndTime != nil {
+			continue
+		}
+		stopped := log
+		end := now
+		stopped.EndTime = &end
+		stopped.AutoClosed = true
+		stopped.NeedsReview = true
+		m.logs[id] = stopped
+		m.closePause(id, end)
+		m.audit(ctx, models.AuditTimerAutoStop, models.AuditEntityTimeEntry, id, log.toTimeEntry(), stopped.toTimeEntry())
+		count++
+	}
+	return count, nil
+}
+
+func (m *Memory) CountOpenTimers(ctx context.Context) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := 0
+	for _, log := range m.logs {
+		if log.EndTime == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *Memory) FlagOpenTimers(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	for id, log := range m.logs {
+		if log.EndTime != nil || log.NeedsReview {
+			continue
+		}
+		flagged := log
+		flagged.NeedsReview = true
+		m.logs[id] = flagged
+		m.audit(ctx, models.AuditTimeEntryFlag, models.AuditEntityTimeEntry, id, log.toTimeEntry(), flagged.toTimeEntry())
+		count++
+	}
+	return count, nil
+}
+
+// Запущенные таймеры в порядке запуска
+func (m *Memory) GetActiveTimers(ctx context.Context, filter models.ActiveTimerFilter) ([]models.ActiveTimer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := m.Now()
+	timers := []models.ActiveTimer{}
+	for _, log := range m.logs {
+		task := m.tasks[log.TaskID]
+		user := m.users[log.UserID]
+		switch {
+		case log.EndTime != nil,
+			filter.UserID != 0 && log.UserID != filter.UserID,
+			filter.TaskID != 0 && log.TaskID != filter.TaskID,
+			filter.ProjectID != 0 && (task.ProjectID == nil || *task.ProjectID != filter.ProjectID),
+			filter.ManagerID != 0 && (user.ManagerID == nil || *user.ManagerID != filter.ManagerID):
+			continue
+		}
+		timer := models.ActiveTimer{
+			TimeEntry:      log.toTimeEntry(),
+			UserSurname:    user.Surname,
+			UserName:       user.Name,
+			TaskName:       task.Name,
+			ProjectID:      task.ProjectID,
+			ElapsedSeconds: int64(now.Sub(log.StartTime).Seconds()),
+			WorkedSeconds:  int64(m.worked(log, log.StartTime, now, now).Seconds()),
+		}
+		for _, pause := range m.pauses[log.ID] {
+			if pause.EndTime == nil {
+				timer.Paused = true
+			}
+		}
+		timers = append(timers, timer)
+	}
+	sort.Slice(timers, func(i, j int) bool {
+		if !timers[i].TimeEntry.StartTime.Equal(timers[j].TimeEntry.StartTime) {
+			return timers[i].TimeEntry.StartTime.Before(timers[j].TimeEntry.StartTime)
+		}
+		return timers[i].TimeEntry.ID < timers[j].TimeEntry.ID
+	})
+	return timers, nil
+}
+
+// Останавливает таймеры, идущие дольше olderThan, через limit после запуска
+func (m *Memory) CapStaleTimers(ctx context.Context, olderThan, limit time.Duration) (int, error) {
+	return m.closeStaleTimers(ctx, olderThan, func(start time.Time) time.Time {
+		return start.Add(limit)
+	})
+}
+
+// Останавливает таймеры, идущие дольше olderThan, в конце рабочего дня запуска
+func (m *Memory) CloseStaleTimersAtDayEnd(ctx context.Context, olderThan, dayEnd time.Duration) (int, error) {
+	return m.closeStaleTimers(ctx, olderThan, func(start time.Time) time.Time {
+		day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+		if end := day.Add(dayEnd); end.After(start) {
+			return end
+		}
+		return start
+	})
+}
+
+func (m *Memory) closeStaleTimers(ctx context.Context, olderThan time.Duration, endOf func(start time.Time) time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.Now()
+	count := 0
+	for id, log := range m.logs {
+		if log.EndTime != nil || !log.StartTime.Before(now.Add(-olderThan)) {
+			continue
+		}
+		end := endOf(log.StartTime)
+		if end.After(now) {
+			end = now
+		}
+		stopped := log
+		stopped.EndTime = &end
+		stopped.AutoClosed = true
+		stopped.NeedsReview = true
+		m.logs[id] = stopped
+		m.audit(ctx, models.AuditTimerAutoStop, models.AuditEntityTimeEntry, id, log.toTimeEntry(), stopped.toTimeEntry())
+		m.closePause(id, end)
+		count++
+	}
+	return count, nil
+}
+
+// Помечает таймеры, идущие дольше olderThan, как требующие проверки
+func (m *Memory) FlagStaleTimers(ctx context.Context, olderThan time.Duration) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.Now()
+	count := 0
+	for id, log := range m.logs {
+		if log.EndTime != nil || log.NeedsReview || !log.StartTime.Before(now.Add(-olderThan)) {
+			continue
+		}
+		flagged := log
+		flagged.NeedsReview = true
+		m.logs[id] = flagged
+		m.audit(ctx, models.AuditTimeEntryFlag, models.AuditEntityTimeEntry, id, log.toTimeEntry(), flagged.toTimeEntry())
+		count++
+	}
+	return count, nil
+}
+
+// События аудита от новых к старым
+func (m *Memory) GetAuditEvents(ctx context.Context, filter models.AuditFilter, pagination models.Pagination) (models.Page[models.AuditEvent], error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var events []models.AuditEvent
+	for _, event := range m.auditEvents {
+		switch {
+		case filter.EntityType != "" && event.EntityType != filter.EntityType,
+			filter.EntityID != 0 && event.EntityID != filter.EntityID,
+			filter.ActorID != 0 && (event.ActorID == nil || *event.ActorID != filter.ActorID),
+			filter.Action != "" && event.Action != filter.Action,
+			!filter.From.IsZero() && event.CreatedAt.Before(filter.From),
+			!filter.To.IsZero() && !event.CreatedAt.Before(filter.To):
+			continue
+		}
+		events = append(events, event)
+	}
+	return memoryPage(events, pagination, "id", "desc", auditSortKey), nil
+}
+
+// Удаляет события аудита старше olderThan
+func (m *Memory) DeleteAuditEvents(ctx context.Context, olderThan time.Duration) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := m.Now().Add(-olderThan)
+	kept := m.auditEvents[:0]
+	for _, event := range m.auditEvents {
+		if event.CreatedAt.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, event)
+	}
+	count := len(m.auditEvents) - len(kept)
+	m.auditEvents = kept
+	return count, nil
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+)
+
+// sortKey возвращает значение столбца сортировки строки в виде models.Cursor.Value и её id
+type sortKey[T any] func(row T) (string, int64)
+
+// pageClause возвращает условие курсора (пустое без курсора) и ORDER BY ... LIMIT ... для списка,
+// упорядоченного по column и затем по id. Выбирается на строку больше страницы: по лишней строке
+// newPage узнаёт, есть ли следующая. Перед курсором Before строки выбираются в обратном порядке.
+// column — выражение столбца сортировки, "id" — сортировка только по id. Аргументы нумеруются с argCount.
+func pageClause(column, sortOrder string, pagination models.Pagination, argCount int) (string, string, []interface{}) {
+	cursor := pagination.Cursor
+	order := sortOrder
+	if cursor != nil && cursor.Before {
+		order = reverseOrder(order)
+	}
+	op := ">"
+	if order == "desc" {
+		op = "<"
+	}
+
+	var where string
+	var args []interface{}
+	if cursor != nil {
+		if column == "id" {
+			where = " AND id " + op + " $" + strconv.Itoa(argCount)
+			args = append(args, cursor.ID)
+			argCount++
+		} else {
+			where = " AND (" + column + ", id) " + op + " ($" + strconv.Itoa(argCount) + ", $" + strconv.Itoa(argCount+1) + ")"
+			args = append(args, cursor.Value, cursor.ID)
+			argCount += 2
+		}
+	}
+
+	tail := " ORDER BY "
+	if column != "id" {
+		tail += column + " " + order + ", "
+	}
+	tail += "id " + order + " LIMIT $" + strconv.Itoa(argCount)
+	args = append(args, pagination.PageSize+1)
+	if cursor == nil {
+		tail += " OFFSET $" + strconv.Itoa(argCount+1)
+		args = append(args, (pagination.Page-1)*pagination.PageSize)
+	}
+	return where, tail, args
+}
+
+// newPage собирает страницу из строк, выбранных по pageClause: отбрасывает лишнюю строку,
+// возвращает странице перед курсором прямой порядок и строит курсоры соседних страниц
+func newPage[T any](rows []T, total int, pagination models.Pagination, sortBy, sortOrder string, key sortKey[T]) models.Page[T] {
+	more := len(rows) > pagination.PageSize
+	if more {
+		rows = rows[:pagination.PageSize]
+	}
+	hasNext, hasPrev := more, pagination.Page > 1
+	if cursor := pagination.Cursor; cursor != nil {
+		hasNext, hasPrev = more, true
+		if cursor.Before {
+			slices.Reverse(rows)
+			hasNext, hasPrev = true, more
+		}
+	}
+
+	page := models.Page[T]{Items: rows, Pagination: pagination, PageInfo: models.PageInfo{Total: total}}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if len(rows) == 0 {
+		return page
+	}
+	cursorAt := func(row T, before bool) string {
+		value, id := key(row)
+		return models.Cursor{SortBy: sortBy, SortOrder: sortOrder, Value: value, ID: id, Before: before}.Encode()
+	}
+	if hasNext {
+		page.NextCursor = cursorAt(rows[len(rows)-1], false)
+	}
+	if hasPrev {
+		page.PrevCursor = cursorAt(rows[0], true)
+	}
+	return page
+}
+
+// memoryPage выбирает из всех строк списка те же строки, что pageClause в Repository, и собирает страницу.
+// Строки сортируются по ключу key в порядке sortOrder.
+func memoryPage[T any](items []T, pagination models.Pagination, sortBy, sortOrder string, key sortKey[T]) models.Page[T] {
+	slices.SortFunc(items, func(a, b T) int {
+		return compareKeys(a, b, key, sortOrder)
+	})
+
+	var rows []T
+	switch cursor := pagination.Cursor; {
+	case cursor == nil:
+		offset := (pagination.Page - 1) * pagination.PageSize
+		if offset < len(items) {
+			rows = items[offset:min(offset+pagination.PageSize+1, len(items))]
+		}
+	case !cursor.Before:
+		for _, item := range items {
+			if len(rows) > pagination.PageSize {
+				break
+			}
+			if compareCursor(item, *cursor, key, sortOrder) > 0 {
+				rows = append(rows, item)
+			}
+		}
+	default:
+		for i := len(items) - 1; i >= 0 && len(rows) <= pagination.PageSize; i-- {
+			if compareCursor(items[i], *cursor, key, sortOrder) < 0 {
+				rows = append(rows, items[i])
+			}
+		}
+	}
+	return newPage(rows, len(items), pagination, sortBy, sortOrder, key)
+}
+
+// compareKeys сравнивает строки в порядке списка: по значению столбца сортировки, затем по id
+func compareKeys[T any](a, b T, key sortKey[T], sortOrder string) int {
+	aValue, aID := key(a)
+	bValue, bID := key(b)
+	return orderedCompare(aValue, aID, bValue, bID, sortOrder)
+}
+
+// compareCursor сравнивает строку с позицией курсора в порядке списка, > 0 — строка после курсора
+func compareCursor[T any](row T, cursor models.Cursor, key sortKey[T], sortOrder string) int {
+	value, id := key(row)
+	return orderedCompare(value, id, cursor.Value, cursor.ID, sortOrder)
+}
+
+func orderedCompare(aValue string, aID int64, bValue string, bID int64, sortOrder string) int {
+	result := strings.Compare(aValue, bValue)
+	if result == 0 {
+		result = cmp.Compare(aID, bID)
+	}
+	if sortOrder == "desc" {
+		return -result
+	}
+	return result
+}
+
+func reverseOrder(order string) string {
+	if order == "desc" {
+		return "asc"
+	}
+	return "desc"
+}
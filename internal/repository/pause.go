@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+)
+
+// openLogForUpdate возвращает id запущенного таймера и блокирует его строку до конца транзакции,
+// чтобы пауза, продолжение и остановка одной сессии выполнялись по очереди
+func openLogForUpdate(ctx context.Context, tx *sql.Tx, userID, taskID int) (int, error) {
+	query := "SELECT id FROM task_logs WHERE user_id = $1 AND task_id = $2 AND end_time IS NULL FOR UPDATE"
+	var logID int
+	err := tx.QueryRowContext(ctx, query, userID, taskID).Scan(&logID)
+	if err == sql.ErrNoRows {
+		return 0, models.ErrTaskNotStarted
+	}
+	return logID, err
+}
+
+// Пауза запущенного таймера. Второй идущий перерыв отклоняет индекс task_log_pauses_open_idx.
+func (r *Repository) PauseTask(ctx context.Context, userID, taskID int) (models.Pause, error) {
+	var pause models.Pause
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		logID, err := openLogForUpdate(ctx, tx, userID, taskID)
+		if err != nil {
+			return err
+		}
+		query := `
+			INSERT INTO task_log_pauses (task_log_id, start_time)
+			VALUES ($1, NOW())
+			RETURNING id, task_log_id, start_time, end_time
+		`
+		err = tx.QueryRowContext(ctx, query, logID).Scan(&pause.ID, &pause.TimeEntryID, &pause.StartTime, &pause.EndTime)
+		if err != nil {
+			return translateError(err)
+		}
+		return createdForAudit("task_log_pauses", pause.ID).write(ctx, tx, models.AuditTimerPause)
+	})
+	if err != nil {
+		return models.Pause{}, err
+	}
+	return pause, nil
+}
+
+// Продолжение таймера: закрывает идущий перерыв
+func (r *Repository) ResumeTask(ctx context.Context, userID, taskID int) (models.Pause, error) {
+	var pause models.Pause
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		logID, err := openLogForUpdate(ctx, tx, userID, taskID)
+		if err != nil {
+			return err
+		}
+		rows, err := lockOneForAudit(ctx, tx, "task_log_pauses", "task_log_id = $1 AND end_time IS NULL", logID)
+		if err == sql.ErrNoRows {
+			return models.ErrTaskNotPaused
+		}
+		if err != nil {
+			return err
+		}
+		query := `
+			UPDATE task_log_pauses
+			SET end_time = NOW()
+			WHERE id = $1
+			RETURNING id, task_log_id, start_time, end_time
+		`
+		err = tx.QueryRowContext(ctx, query, rows.ids[0]).Scan(&pause.ID, &pause.TimeEntryID, &pause.StartTime, &pause.EndTime)
+		if err != nil {
+			return err
+		}
+		return rows.write(ctx, tx, models.AuditTimerResume)
+	})
+	if err != nil {
+		return models.Pause{}, err
+	}
+	return pause, nil
+}
+
+// Перерывы записи времени в порядке начала
+func (r *Repository) GetPauses(ctx context.Context, entryID int) ([]models.Pause, error) {
+	query := `
+		SELECT id, task_log_id, start_time, end_time
+		FROM task_log_pauses
+		WHERE task_log_id = $1
+		ORDER BY start_time, id
+	`
+	rows, err := r.DB.QueryContext(ctx, query, entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pauses := []models.Pause{}
+	for rows.Next() {
+		var pause models.Pause
+		if err := rows.Scan(&pause.ID, &pause.TimeEntryID, &pause.StartTime, &pause.EndTime); err != nil {
+			return nil, err
+		}
+		pauses = append(pauses, pause)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pauses, nil
+}
+
+// pausedSecondsSQL — подзапрос для LEFT JOIN LATERAL: секунды перерывов записи l внутри периода [$2, $3).
+// Незакрытый перерыв длится до конца записи, у запущенного таймера — до текущего момента.
+const pausedSecondsSQL = `
+	SELECT COALESCE(SUM(GREATEST(EXTRACT(EPOCH FROM (
+	           LEAST(COALESCE(p.end_time, l.end_time, LOCALTIMESTAMP), COALESCE(l.end_time, LOCALTIMESTAMP), $3)
+	           - GREATEST(p.start_time, l.start_time, $2)
+	       )), 0)), 0) AS paused_seconds
+	FROM task_log_pauses p
+	WHERE p.task_log_id = l.id
+`
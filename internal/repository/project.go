@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+)
+
+// Получение проектов: страница по номеру или по курсору и общее число
+func (r *Repository) GetProjects(ctx context.Context, pagination models.Pagination) (models.Page[models.Project], error) {
+	var total int
+	if err := r.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM projects").Scan(&total); err != nil {
+		return models.Page[models.Project]{}, err
+	}
+
+	cursorWhere, tail, args := pageClause("id", "asc", pagination, 1)
+	query := "SELECT id, name, description, created_at FROM projects WHERE 1=1" + cursorWhere + tail
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return models.Page[models.Project]{}, err
+	}
+	defer rows.Close()
+
+	var projects []models.Project
+	for rows.Next() {
+		var project models.Project
+		err := rows.Scan(&project.ID, &project.Name, &project.Description, &project.CreatedAt)
+		if err != nil {
+			return models.Page[models.Project]{}, err
+		}
+		projects = append(projects, project)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Page[models.Project]{}, err
+	}
+
+	return newPage(projects, total, pagination, "id", "asc", projectSortKey), nil
+}
+
+// projectSortKey — ключ сортировки проектов: список идёт по id
+func projectSortKey(project models.Project) (string, int64) {
+	return "", int64(project.ID)
+}
+
+func (r *Repository) GetProject(ctx context.Context, projectID int) (models.Project, error) {
+	query := `
+		SELECT id, name, description, created_at
+		FROM projects
+		WHERE id = $1
+	`
+	var project models.Project
+	err := r.DB.QueryRowContext(ctx, query, projectID).Scan(&project.ID, &project.Name, &project.Description, &project.CreatedAt)
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	return project, nil
+}
+
+// Создание проекта
+func (r *Repository) CreateProject(ctx context.Context, project models.Project) (int, error) {
+	var id int
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO projects (name, description)
+			VALUES ($1, $2)
+			RETURNING id
+		`
+		err := tx.QueryRowContext(ctx, query, project.Name, project.Description).Scan(&id)
+		if err != nil {
+			return err
+		}
+		return createdForAudit("projects", id).write(ctx, tx, models.AuditProjectCreate)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Обновление проекта, sql.ErrNoRows если проекта нет
+func (r *Repository) UpdateProject(ctx context.Context, projectID int, project models.Project) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		rows, err := lockOneForAudit(ctx, tx, "projects", "id = $1", projectID)
+		if err != nil {
+			return err
+		}
+		query := `
+			UPDATE projects
+			SET name = $2, description = $3
+			WHERE id = $1
+		`
+		if _, err := tx.ExecContext(ctx, query, projectID, project.Name, project.Description); err != nil {
+			return err
+		}
+		return rows.write(ctx, tx, models.AuditProjectUpdate)
+	})
+}
+
+// Удаление проекта вместе с участниками, sql.ErrNoRows если проекта нет, ErrProjectHasTasks если
+// в нём есть задачи. Строка проекта блокируется до проверки, и задача не добавится между ними.
+func (r *Repository) DeleteProject(ctx context.Context, projectID int) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		rows, err := lockOneForAudit(ctx, tx, "projects", "id = $1", projectID)
+		if err != nil {
+			return err
+		}
+		var hasTasks bool
+		if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM tasks WHERE project_id = $1)", projectID).Scan(&hasTasks); err != nil {
+			return err
+		}
+		if hasTasks {
+			return models.ErrProjectHasTasks
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM projects WHERE id = $1", projectID); err != nil {
+			return err
+		}
+		return rows.write(ctx, tx, models.AuditProjectDelete)
+	})
+}
+
+// Получение участников проекта
+func (r *Repository) GetProjectMembers(ctx context.Context, projectID int) ([]models.User, error) {
+	query := `
+		SELECT u.id, u.passport_number, COALESCE(u.surname, ''), COALESCE(u.name, ''),
+		       COALESCE(u.patronymic, ''), COALESCE(u.address, ''), u.role, u.manager_id,
+		       u.created_at, u.updated_at, u.deleted_at
+		FROM project_members m
+		INNER JOIN users u ON m.user_id = u.id
+		WHERE m.project_id = $1
+		ORDER BY u.id
+	`
+	rows, err := r.DB.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(&user.ID, &user.PassportNumber, &user.Surname, &user.Name, &user.Patronymic, &user.Address, &user.Role, &user.ManagerID, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// Добавление участника, models.ErrMemberAlreadyExists если он уже в проекте
+func (r *Repository) AddProjectMember(ctx context.Context, projectID, userID int) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO project_members (project_id, user_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`
+		res, err := tx.ExecContext(ctx, query, projectID, userID)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return models.ErrMemberAlreadyExists
+		}
+		return writeAuditEvent(ctx, tx, models.AuditProjectAddMember, models.AuditEntityProject, projectID, nil, memberJSON(userID))
+	})
+}
+
+// Удаление участника, sql.ErrNoRows если его нет в проекте
+func (r *Repository) RemoveProjectMember(ctx context.Context, projectID, userID int) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, "DELETE FROM project_members WHERE project_id = $1 AND user_id = $2", projectID, userID)
+		if err != nil {
+			return err
+		}
+		if err := checkRowsAffected(res); err != nil {
+			return err
+		}
+		return writeAuditEvent(ctx, tx, models.AuditProjectRemoveMember, models.AuditEntityProject, projectID, memberJSON(userID), nil)
+	})
+}
+
+func (r *Repository) IsProjectMember(ctx context.Context, projectID, userID int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM project_members
+			WHERE project_id = $1 AND user_id = $2
+		)
+	`
+	var exists bool
+	err := r.DB.QueryRowContext(ctx, query, projectID, userID).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// Суммарное время по задачам проекта в разрезе пользователей, с обрезкой по границам периода
+// и без перерывов. Ненулевой managerID оставляет только время команды этого руководителя.
+func (r *Repository) GetProjectWorkloads(ctx context.Context, projectID, managerID int, startDate, endDate time.Time) ([]models.ProjectWorkloadEntry, error) {
+	team := ""
+	args := []interface{}{projectID, startDate, endDate}
+	if managerID != 0 {
+		team = " AND l.user_id IN (SELECT id FROM users WHERE manager_id = $4)"
+		args = append(args, managerID)
+	}
+	query := `
+		SELECT l.task_id, t.task_name, l.user_id,
+		       SUM(EXTRACT(EPOCH FROM (
+		           LEAST(COALESCE(l.end_time, LOCALTIMESTAMP), $3) - GREATEST(l.start_time, $2)
+		       )) - p.paused_seconds) AS total_seconds
+		FROM task_logs l
+		INNER JOIN tasks t ON l.task_id = t.id
+		LEFT JOIN LATERAL (` + pausedSecondsSQL + `) p ON TRUE
+		WHERE t.project_id = $1 AND l.start_time < $3 AND COALESCE(l.end_time, LOCALTIMESTAMP) > $2` + team + `
+		GROUP BY l.task_id, t.task_name, l.user_id
+		ORDER BY l.task_id, l.user_id
+	`
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.ProjectWorkloadEntry
+	for rows.Next() {
+		var entry models.ProjectWorkloadEntry
+		err := rows.Scan(&entry.TaskID, &entry.TaskName, &entry.UserID, &entry.TotalSeconds)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// sql.ErrNoRows, если запрос не затронул ни одной строки
+func checkRowsAffected(res sql.Result) error {
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
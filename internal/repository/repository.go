@@ -1,11 +1,14 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/lib/pq"
 )
 
 type Repository struct {
@@ -18,66 +21,184 @@ func New(db *sql.DB) Repository {
 	}
 }
 
+// userSearchVector — документ полнотекстового поиска пользователя, совпадает с индексом users_search_idx
+const userSearchVector = `to_tsvector('simple', COALESCE(surname, '') || ' ' || COALESCE(name, '') || ' ' ||
+	COALESCE(patronymic, '') || ' ' || COALESCE(address, ''))`
+
 // Получение всех пользователей
-func (r *Repository) GetUsers(filter models.Filter, pagination models.Pagination, sortBy, sortOrder string) ([]models.User, error) {
-	query := "SELECT id, passport_number, surname, name FROM users WHERE 1=1"
+func (r *Repository) GetUsers(ctx context.Context, filter models.Filter, pagination models.Pagination, sortBy, sortOrder string) (models.Page[models.User], error) {
+	where := ""
 	var args []interface{}
 	argCount := 1
 
-	if filter.PassportNumber != "" {
-		query += " AND passport_number = $" + strconv.Itoa(argCount)
-		args = append(args, filter.PassportNumber)
+	if !filter.IncludeDeleted {
+		where += " AND deleted_at IS NULL"
+	}
+
+	textFilters := []struct {
+		column string
+		match  models.TextMatch
+	}{
+		{"passport_number", filter.PassportNumber},
+		{"surname", filter.Surname},
+		{"name", filter.Name},
+	}
+	for _, f := range textFilters {
+		if f.match.Value == "" {
+			continue
+		}
+		condition, value := textCondition(f.column, f.match, "$"+strconv.Itoa(argCount))
+		where += " AND " + condition
+		args = append(args, value)
 		argCount++
 	}
-	if filter.Surname != "" {
-		query += " AND surname = $" + strconv.Itoa(argCount)
-		args = append(args, filter.Surname)
+	if filter.Search != "" {
+		where += " AND " + userSearchVector + " @@ plainto_tsquery('simple', $" + strconv.Itoa(argCount) + ")"
+		args = append(args, filter.Search)
 		argCount++
 	}
-	if filter.Name != "" {
-		query += " AND name = $" + strconv.Itoa(argCount)
-		args = append(args, filter.Name)
+
+	if filter.ID.Min != 0 {
+		where += " AND id >= $" + strconv.Itoa(argCount)
+		args = append(args, filter.ID.Min)
+		argCount++
+	}
+	if filter.ID.Max != 0 {
+		where += " AND id <= $" + strconv.Itoa(argCount)
+		args = append(args, filter.ID.Max)
+		argCount++
+	}
+	if len(filter.ID.In) > 0 {
+		where += " AND id = ANY($" + strconv.Itoa(argCount) + ")"
+		args = append(args, pq.Array(filter.ID.In))
+		argCount++
+	}
+	if filter.ManagerID != 0 {
+		where += " AND manager_id = $" + strconv.Itoa(argCount)
+		args = append(args, filter.ManagerID)
 		argCount++
 	}
 
-	query += " ORDER BY " + sortBy + " " + sortOrder
-	query += " LIMIT $" + strconv.Itoa(argCount) + " OFFSET $" + strconv.Itoa(argCount+1)
-	args = append(args, pagination.PageSize, (pagination.Page-1)*pagination.PageSize)
+	timeFilters := []struct {
+		column string
+		period models.TimeRange
+	}{
+		{"created_at", filter.CreatedAt},
+		{"updated_at", filter.UpdatedAt},
+	}
+	for _, f := range timeFilters {
+		if !f.period.From.IsZero() {
+			where += " AND " + f.column + " >= $" + strconv.Itoa(argCount)
+			args = append(args, f.period.From)
+			argCount++
+		}
+		if !f.period.To.IsZero() {
+			where += " AND " + f.column + " < $" + strconv.Itoa(argCount)
+			args = append(args, f.period.To)
+			argCount++
+		}
+	}
+
+	var total int
+	if err := r.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE 1=1"+where, args...).Scan(&total); err != nil {
+		return models.Page[models.User]{}, err
+	}
 
-	rows, err := r.DB.Query(query, args...)
+	cursorWhere, tail, pageArgs := pageClause(userSortColumns[sortBy], sortOrder, pagination, argCount)
+	query := `SELECT id, passport_number, COALESCE(surname, ''), COALESCE(name, ''),
+		COALESCE(patronymic, ''), COALESCE(address, ''), role, manager_id,
+		created_at, updated_at, deleted_at FROM users WHERE 1=1` + where + cursorWhere + tail
+	args = append(args, pageArgs...)
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return models.Page[models.User]{}, err
 	}
 	defer rows.Close()
 
 	var users []models.User
 	for rows.Next() {
 		var user models.User
-		err := rows.Scan(&user.ID, &user.PassportNumber, &user.Surname, &user.Name)
+		err := rows.Scan(&user.ID, &user.PassportNumber, &user.Surname, &user.Name, &user.Patronymic, &user.Address, &user.Role, &user.ManagerID, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt)
 		if err != nil {
-			return nil, err
+			return models.Page[models.User]{}, err
 		}
 		users = append(users, user)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return models.Page[models.User]{}, err
+	}
+
+	return newPage(users, total, pagination, sortBy, sortOrder, userSortKey(sortBy)), nil
+}
+
+// userSortColumns — выражения столбцов сортировки пользователей; пустые имя и фамилия хранятся как NULL
+var userSortColumns = map[string]string{
+	"id":              "id",
+	"passport_number": "passport_number",
+	"surname":         "COALESCE(surname, '')",
+	"name":            "COALESCE(name, '')",
+}
+
+// userSortKey — ключ сортировки пользователей по столбцу sortBy для курсора
+func userSortKey(sortBy string) sortKey[models.User] {
+	return func(user models.User) (string, int64) {
+		switch sortBy {
+		case "passport_number":
+			return user.PassportNumber, int64(user.ID)
+		case "surname":
+			return user.Surname, int64(user.ID)
+		case "name":
+			return user.Name, int64(user.ID)
+		default:
+			return "", int64(user.ID)
+		}
+	}
+}
+
+// textCondition возвращает условие на строковый столбец с параметром placeholder и значение параметра
+func textCondition(column string, match models.TextMatch, placeholder string) (string, string) {
+	switch match.Mode {
+	case models.MatchIExact:
+		return "LOWER(" + column + ") = LOWER(" + placeholder + ")", match.Value
+	case models.MatchPrefix:
+		return column + " LIKE " + placeholder, escapeLike(match.Value) + "%"
+	case models.MatchIPrefix:
+		return column + " ILIKE " + placeholder, escapeLike(match.Value) + "%"
+	case models.MatchContains:
+		return column + " LIKE " + placeholder, "%" + escapeLike(match.Value) + "%"
+	case models.MatchIContains:
+		return column + " ILIKE " + placeholder, "%" + escapeLike(match.Value) + "%"
+	default:
+		return column + " = " + placeholder, match.Value
 	}
+}
 
-	return users, nil
+// escapeLike экранирует спецсимволы шаблона LIKE, чтобы значение искалось буквально
+func escapeLike(value string) string {
+	return likeReplacer.Replace(value)
 }
 
-// Получение рабочей нагрузки пользователей по их ID
-func (r *Repository) GetUserWorkloadsByUserID(userID int, startDate, endDate time.Time) ([]models.UserWorkload, error) {
+var likeReplacer = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// Получение рабочей нагрузки пользователя по задачам за период [startDate, endDate).
+// Запущенные таймеры считаются до текущего момента, интервалы обрезаются границами периода,
+// время перерывов вычитается.
+func (r *Repository) GetUserWorkloadsByUserID(ctx context.Context, userID int, startDate, endDate time.Time) ([]models.UserWorkload, error) {
 	query := `
-		SELECT l.task_id, t.task_name, 
-		       EXTRACT(EPOCH FROM (l.end_time - l.start_time)) AS total_seconds
+		SELECT l.task_id, t.task_name,
+		       SUM(EXTRACT(EPOCH FROM (
+		           LEAST(COALESCE(l.end_time, LOCALTIMESTAMP), $3) - GREATEST(l.start_time, $2)
+		       )) - p.paused_seconds) AS total_seconds
 		FROM task_logs l
 		INNER JOIN tasks t ON l.task_id = t.id
-		WHERE l.user_id = $1 AND l.start_time >= $2 AND l.end_time <= $3
-		ORDER BY l.task_id, l.start_time
+		LEFT JOIN LATERAL (` + pausedSecondsSQL + `) p ON TRUE
+		WHERE l.user_id = $1 AND l.start_time < $3 AND COALESCE(l.end_time, LOCALTIMESTAMP) > $2
+		GROUP BY l.task_id, t.task_name
+		ORDER BY total_seconds DESC, l.task_id
 	`
 
-	rows, err := r.DB.Query(query, userID, startDate, endDate)
+	rows, err := r.DB.QueryContext(ctx, query, userID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -91,8 +212,7 @@ func (r *Repository) GetUserWorkloadsByUserID(userID int, startDate, endDate tim
 		if err != nil {
 			return nil, err
 		}
-		userWorkload.TotalHours = int(totalTimeSeconds / 3600)
-		userWorkload.TotalMinutes = int((totalTimeSeconds - float64(userWorkload.TotalHours)*3600) / 60)
+		userWorkload.TotalSeconds = int64(totalTimeSeconds)
 		userWorkloads = append(userWorkloads, userWorkload)
 	}
 	if err := rows.Err(); err != nil {
@@ -102,107 +222,297 @@ func (r *Repository) GetUserWorkloadsByUserID(userID int, startDate, endDate tim
 	return userWorkloads, nil
 }
 
-// Запуск задачи
-func (r *Repository) StartTask(userID, taskID int) error {
-	query := `
-		INSERT INTO task_logs (user_id, task_id, start_time)
-		VALUES ($1, $2, NOW())
-	`
-	_, err := r.DB.Exec(query, userID, taskID)
-	if err != nil {
-		return err
-	}
+// Запуск задачи в транзакции: строка задачи блокируется FOR SHARE, чтобы её не удалили
+// и не архивировали между проверкой и вставкой. Второй открытый таймер того же пользователя
+// по той же задаче отклоняет частичный уникальный индекс task_logs_open_timer_idx,
+// поэтому из одновременных запросов успешен только один, остальные получают ErrTaskAlreadyStarted.
+//
+// При политиках reject и switch блокируется строка пользователя, чтобы одновременные запуски
+// разных задач одного пользователя выполнялись по очереди и видели таймеры друг друга.
+func (r *Repository) StartTask(ctx context.Context, userID, taskID int, policy string) (models.TimerStart, error) {
+	result := models.TimerStart{Stopped: []models.TimeEntry{}}
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var archived bool
+		err := tx.QueryRowContext(ctx, "SELECT archived FROM tasks WHERE id = $1 FOR SHARE", taskID).Scan(&archived)
+		if err == sql.ErrNoRows {
+			return models.ErrTaskNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if archived {
+			return models.ErrTaskArchived
+		}
 
-	return nil
-}
-func (r *Repository) IsTaskInProgress(userID, taskID int) (bool, error) {
-	query := `
-		SELECT COUNT(*)
-		FROM task_logs
-		WHERE user_id = $1 AND task_id = $2 AND end_time IS NULL
-	`
-	var count int
-	err := r.DB.QueryRow(query, userID, taskID).Scan(&count)
-	if err != nil {
-		return false, err
-	}
+		if policy == models.TimerPolicyReject || policy == models.TimerPolicySwitch {
+			err := tx.QueryRowContext(ctx, "SELECT id FROM users WHERE id = $1 FOR UPDATE", userID).Scan(new(int))
+			if err == sql.ErrNoRows {
+				return models.ErrUserNotFound
+			}
+			if err != nil {
+				return err
+			}
+		}
 
-	return count > 0, nil
-}
+		switch policy {
+		case models.TimerPolicyReject:
+			var runningTaskID int
+			query := "SELECT task_id FROM task_logs WHERE user_id = $1 AND task_id <> $2 AND end_time IS NULL LIMIT 1"
+			err := tx.QueryRowContext(ctx, query, userID, taskID).Scan(&runningTaskID)
+			if err == nil {
+				return models.ErrAnotherTimerRunning.WithDetail("task_id", runningTaskID)
+			}
+			if err != sql.ErrNoRows {
+				return err
+			}
+		case models.TimerPolicySwitch:
+			others, err := lockForAudit(ctx, tx, "task_logs", "user_id = $1 AND task_id <> $2 AND end_time IS NULL", userID, taskID)
+			if err != nil {
+				return err
+			}
+			pausesQuery := `
+				UPDATE task_log_pauses
+				SET end_time = NOW()
+				WHERE task_log_id = ANY($1) AND end_time IS NULL
+			`
+			if _, err := tx.ExecContext(ctx, pausesQuery, pq.Array(others.ids)); err != nil {
+				return err
+			}
+			query := `
+				UPDATE task_logs
+				SET end_time = NOW()
+				WHERE id = ANY($1)
+				RETURNING id, user_id, task_id, start_time, end_time, note, needs_review, auto_closed
+			`
+			rows, err := tx.QueryContext(ctx, query, pq.Array(others.ids))
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var entry models.TimeEntry
+				err := rows.Scan(&entry.ID, &entry.UserID, &entry.TaskID, &entry.StartTime, &entry.EndTime, &entry.Note, &entry.NeedsReview, &entry.AutoClosed)
+				if err != nil {
+					return err
+				}
+				result.Stopped = append(result.Stopped, entry)
+			}
+			if err := rows.Err(); err != nil {
+				return err
+			}
+			if err := others.write(ctx, tx, models.AuditTimerStop); err != nil {
+				return err
+			}
+		}
 
-func (r *Repository) IsTaskExists(taskID int) (bool, error) {
-	query := `
-		SELECT EXISTS (
-			SELECT 1
-			FROM tasks
-			WHERE id = $1
-		)
-	`
-	var exists bool
-	err := r.DB.QueryRow(query, taskID).Scan(&exists)
+		query := `
+			INSERT INTO task_logs (user_id, task_id, start_time)
+			VALUES ($1, $2, NOW())
+			RETURNING id, user_id, task_id, start_time, end_time, note, needs_review, auto_closed
+		`
+		entry := &result.TimeEntry
+		err = tx.QueryRowContext(ctx, query, userID, taskID).Scan(&entry.ID, &entry.UserID, &entry.TaskID, &entry.StartTime, &entry.EndTime, &entry.Note, &entry.NeedsReview, &entry.AutoClosed)
+		if err != nil {
+			return translateError(err)
+		}
+		return createdForAudit("task_logs", entry.ID).write(ctx, tx, models.AuditTimerStart)
+	})
 	if err != nil {
-		return false, err
+		return models.TimerStart{}, err
 	}
-	return exists, nil
+	return result, nil
 }
 
-// Завершение задачи
-func (r *Repository) EndTask(userID, taskID int) error {
-	query := `
-		UPDATE task_logs
-		SET end_time = NOW()
-		WHERE user_id = $1 AND task_id = $2 AND end_time IS NULL
-	`
-	res, err := r.DB.Exec(query, userID, taskID)
-	if err != nil {
-		return err
+// Завершение задачи в транзакции вместе с закрытием идущего перерыва:
+// остановка на паузе заканчивает и сессию, и перерыв одним моментом.
+func (r *Repository) EndTask(ctx context.Context, userID, taskID int) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		rows, err := lockOneForAudit(ctx, tx, "task_logs", "user_id = $1 AND task_id = $2 AND end_time IS NULL", userID, taskID)
+		if err == sql.ErrNoRows {
+			return models.ErrTaskNotStarted
+		}
+		if err != nil {
+			return err
+		}
+
+		logID := rows.ids[0]
+		if _, err := tx.ExecContext(ctx, "UPDATE task_logs SET end_time = NOW() WHERE id = $1", logID); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE task_log_pauses SET end_time = NOW() WHERE task_log_id = $1 AND end_time IS NULL", logID)
+		if err != nil {
+			return err
+		}
+		return rows.write(ctx, tx, models.AuditTimerStop)
+	})
+}
+
+// Получение всех задач
+func (r *Repository) GetTasks(ctx context.Context, filter models.TaskFilter, pagination models.Pagination, sortBy, sortOrder string) (models.Page[models.Task], error) {
+	where := ""
+	var args []interface{}
+	argCount := 1
+
+	if filter.Name != "" {
+		where += " AND task_name = $" + strconv.Itoa(argCount)
+		args = append(args, filter.Name)
+		argCount++
 	}
-	rowsAffected, err := res.RowsAffected()
-	if err != nil {
-		return err
+	if filter.Status != "" {
+		where += " AND status = $" + strconv.Itoa(argCount)
+		args = append(args, filter.Status)
+		argCount++
 	}
-	if rowsAffected == 0 {
-		return models.ErrTaskNotStarted
+	if filter.ProjectID != 0 {
+		where += " AND project_id = $" + strconv.Itoa(argCount)
+		args = append(args, filter.ProjectID)
+		argCount++
+	}
+	if !filter.IncludeArchived {
+		where += " AND NOT archived"
 	}
-	return nil
-}
 
-// Получение всех задач
-func (r *Repository) GetTasks() ([]models.Task, error) {
-	query := `
-		SELECT id, task_name
-		FROM tasks
-	`
-	rows, err := r.DB.Query(query)
+	var total int
+	if err := r.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM tasks WHERE 1=1"+where, args...).Scan(&total); err != nil {
+		return models.Page[models.Task]{}, err
+	}
+
+	column := sortBy
+	if sortBy == "name" {
+		column = "task_name"
+	}
+	cursorWhere, tail, pageArgs := pageClause(column, sortOrder, pagination, argCount)
+	query := "SELECT id, task_name, description, status, created_at, archived, project_id FROM tasks WHERE 1=1" + where + cursorWhere + tail
+	args = append(args, pageArgs...)
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return models.Page[models.Task]{}, err
 	}
 	defer rows.Close()
 
 	var tasks []models.Task
 	for rows.Next() {
 		var task models.Task
-		err := rows.Scan(&task.ID, &task.Name)
+		err := rows.Scan(&task.ID, &task.Name, &task.Description, &task.Status, &task.CreatedAt, &task.Archived, &task.ProjectID)
 		if err != nil {
-			return nil, err
+			return models.Page[models.Task]{}, err
 		}
 		tasks = append(tasks, task)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return models.Page[models.Task]{}, err
 	}
 
-	return tasks, nil
+	return newPage(tasks, total, pagination, sortBy, sortOrder, taskSortKey(sortBy)), nil
+}
+
+// taskSortKey — ключ сортировки задач по столбцу sortBy для курсора
+func taskSortKey(sortBy string) sortKey[models.Task] {
+	return func(task models.Task) (string, int64) {
+		switch sortBy {
+		case "name":
+			return task.Name, int64(task.ID)
+		case "status":
+			return task.Status, int64(task.ID)
+		case "created_at":
+			return models.SortTime(task.CreatedAt), int64(task.ID)
+		default:
+			return "", int64(task.ID)
+		}
+	}
 }
 
-func (r *Repository) GetUser(userID int) (models.User, error) {
+func (r *Repository) GetTask(ctx context.Context, taskID int) (models.Task, error) {
 	query := `
-		SELECT id, passport_number, surname, name
+		SELECT id, task_name, description, status, created_at, archived, project_id
+		FROM tasks
+		WHERE id = $1
+	`
+	var task models.Task
+	err := r.DB.QueryRowContext(ctx, query, taskID).Scan(&task.ID, &task.Name, &task.Description, &task.Status, &task.CreatedAt, &task.Archived, &task.ProjectID)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	return task, nil
+}
+
+// Создание задачи
+func (r *Repository) CreateTask(ctx context.Context, task models.Task) (int, error) {
+	var id int
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO tasks (task_name, description, status, archived, project_id)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`
+		err := tx.QueryRowContext(ctx, query, task.Name, task.Description, task.Status, task.Archived, task.ProjectID).Scan(&id)
+		if err != nil {
+			return err
+		}
+		return createdForAudit("tasks", id).write(ctx, tx, models.AuditTaskCreate)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Обновление задачи, sql.ErrNoRows если задачи нет
+func (r *Repository) UpdateTask(ctx context.Context, taskID int, task models.Task) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		rows, err := lockOneForAudit(ctx, tx, "tasks", "id = $1", taskID)
+		if err != nil {
+			return err
+		}
+		query := `
+			UPDATE tasks
+			SET task_name = $2, description = $3, status = $4, archived = $5, project_id = $6
+			WHERE id = $1
+		`
+		if _, err := tx.ExecContext(ctx, query, taskID, task.Name, task.Description, task.Status, task.Archived, task.ProjectID); err != nil {
+			return err
+		}
+		return rows.write(ctx, tx, models.AuditTaskUpdate)
+	})
+}
+
+// Удаление задачи, sql.ErrNoRows если задачи нет, ErrTaskHasLogs если по ней учитывали время.
+// Строка задачи блокируется FOR UPDATE до проверки: запуск таймера (FOR SHARE) и вставка записи
+// времени (внешний ключ) ждут удаления, поэтому каскад не сотрёт запись, появившуюся после проверки.
+func (r *Repository) DeleteTask(ctx context.Context, taskID int) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		rows, err := lockOneForAudit(ctx, tx, "tasks", "id = $1", taskID)
+		if err != nil {
+			return err
+		}
+		var hasLogs bool
+		if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM task_logs WHERE task_id = $1)", taskID).Scan(&hasLogs); err != nil {
+			return err
+		}
+		if hasLogs {
+			return models.ErrTaskHasLogs
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM tasks WHERE id = $1", taskID); err != nil {
+			return err
+		}
+		return rows.write(ctx, tx, models.AuditTaskDelete)
+	})
+}
+
+// Получение пользователя, в том числе мягко удалённого
+func (r *Repository) GetUser(ctx context.Context, userID int) (models.User, error) {
+	query := `
+		SELECT id, passport_number, COALESCE(surname, ''), COALESCE(name, ''),
+		       COALESCE(patronymic, ''), COALESCE(address, ''), role, manager_id,
+		       created_at, updated_at, deleted_at
 		FROM users
 		WHERE id = $1
 	`
 	var user models.User
-	err := r.DB.QueryRow(query, userID).Scan(&user.ID, &user.PassportNumber, &user.Surname, &user.Name)
+	err := r.DB.QueryRowContext(ctx, query, userID).Scan(&user.ID, &user.PassportNumber, &user.Surname, &user.Name, &user.Patronymic, &user.Address, &user.Role, &user.ManagerID, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt)
 	if err != nil {
 		return models.User{}, err
 	}
@@ -210,7 +520,7 @@ func (r *Repository) GetUser(userID int) (models.User, error) {
 	return user, nil
 }
 
-func (r *Repository) UserExistsByPassportNumber(passportNumber string) (bool, error) {
+func (r *Repository) UserExistsByPassportNumber(ctx context.Context, passportNumber string) (bool, error) {
 	query := `
         SELECT EXISTS (
             SELECT 1
@@ -219,50 +529,138 @@ func (r *Repository) UserExistsByPassportNumber(passportNumber string) (bool, er
         )
     `
 	var exists bool
-	err := r.DB.QueryRow(query, passportNumber).Scan(&exists)
+	err := r.DB.QueryRowContext(ctx, query, passportNumber).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
 	return exists, nil
 }
 
-func (r *Repository) CreateUser(user models.UserData) (int, error) {
-	query := `
-		INSERT INTO users (passport_number)
-		VALUES ($1)
-		RETURNING id
-	`
+func (r *Repository) CreateUser(ctx context.Context, user models.User) (int, error) {
 	var id int
-	err := r.DB.QueryRow(query, user.PassportNumber).Scan(&id)
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO users (passport_number, surname, name, patronymic, address)
+			VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''))
+			RETURNING id
+		`
+		err := tx.QueryRowContext(ctx, query, user.PassportNumber, user.Surname, user.Name, user.Patronymic, user.Address).Scan(&id)
+		if err != nil {
+			return err
+		}
+		return createdForAudit("users", id).write(ctx, tx, models.AuditUserCreate)
+	})
 	if err != nil {
 		return 0, err
 	}
 
 	return id, nil
 }
-func (r *Repository) DeleteUser(userID int) error {
-	query := `
-		DELETE FROM users
-		WHERE id = $1
-	`
-	_, err := r.DB.Exec(query, userID)
-	if err != nil {
-		return err
-	}
 
-	return nil
+// Мягкое удаление пользователя: строка и история времени остаются, запущенные таймеры
+// и их перерывы останавливаются в той же транзакции. sql.ErrNoRows, если активного пользователя нет.
+func (r *Repository) DeleteUser(ctx context.Context, userID int) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		user, err := lockOneForAudit(ctx, tx, "users", "id = $1 AND deleted_at IS NULL", userID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1", userID); err != nil {
+			return err
+		}
+		if err := user.write(ctx, tx, models.AuditUserDelete); err != nil {
+			return err
+		}
+
+		logs, err := lockForAudit(ctx, tx, "task_logs", "user_id = $1 AND end_time IS NULL", userID)
+		if err != nil {
+			return err
+		}
+		query := `
+			UPDATE task_log_pauses
+			SET end_time = NOW()
+			WHERE task_log_id = ANY($1) AND end_time IS NULL
+		`
+		if _, err := tx.ExecContext(ctx, query, pq.Array(logs.ids)); err != nil {
+			return err
+		}
+		query = `
+			UPDATE task_logs
+			SET end_time = NOW(), auto_closed = TRUE, needs_review = TRUE
+			WHERE id = ANY($1)
+		`
+		if _, err := tx.ExecContext(ctx, query, pq.Array(logs.ids)); err != nil {
+			return err
+		}
+		return logs.write(ctx, tx, models.AuditTimerAutoStop)
+	})
 }
 
-func (r *Repository) UpdateUser(userID int, user models.User) error {
-	query := `
-		UPDATE users
-		SET  surname = $2, name = $3
-		WHERE id = $1
-	`
-	_, err := r.DB.Exec(query, userID, user.Surname, user.Name)
-	if err != nil {
-		return err
-	}
+// Восстановление мягко удалённого пользователя, sql.ErrNoRows если удалённого пользователя нет
+func (r *Repository) RestoreUser(ctx context.Context, userID int) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		user, err := lockOneForAudit(ctx, tx, "users", "id = $1 AND deleted_at IS NOT NULL", userID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET deleted_at = NULL, updated_at = NOW() WHERE id = $1", userID); err != nil {
+			return err
+		}
+		return user.write(ctx, tx, models.AuditUserRestore)
+	})
+}
+
+// Окончательное удаление пользователя: записи времени удаляются каскадно,
+// у его команды сбрасывается руководитель. sql.ErrNoRows, если пользователя нет.
+func (r *Repository) PurgeUser(ctx context.Context, userID int) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		user, err := lockOneForAudit(ctx, tx, "users", "id = $1", userID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = $1", userID); err != nil {
+			return err
+		}
+		return user.write(ctx, tx, models.AuditUserPurge)
+	})
+}
 
-	return nil
+// Изменение данных активного пользователя, sql.ErrNoRows если его нет или он удалён.
+// Пустые отчество и адрес не меняются.
+func (r *Repository) UpdateUser(ctx context.Context, userID int, user models.User) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		rows, err := lockOneForAudit(ctx, tx, "users", "id = $1 AND deleted_at IS NULL", userID)
+		if err != nil {
+			return err
+		}
+		query := `
+			UPDATE users
+			SET surname = $2, name = $3, patronymic = COALESCE(NULLIF($4, ''), patronymic),
+			    address = COALESCE(NULLIF($5, ''), address), updated_at = NOW()
+			WHERE id = $1
+		`
+		if _, err := tx.ExecContext(ctx, query, userID, user.Surname, user.Name, user.Patronymic, user.Address); err != nil {
+			return err
+		}
+		return rows.write(ctx, tx, models.AuditUserUpdate)
+	})
+}
+
+// Изменение роли и руководителя пользователя, sql.ErrNoRows если его нет или он удалён
+func (r *Repository) UpdateUserRole(ctx context.Context, userID int, role string, managerID *int) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		rows, err := lockOneForAudit(ctx, tx, "users", "id = $1 AND deleted_at IS NULL", userID)
+		if err != nil {
+			return err
+		}
+		query := `
+			UPDATE users
+			SET role = $2, manager_id = $3, updated_at = NOW()
+			WHERE id = $1
+		`
+		if _, err := tx.ExecContext(ctx, query, userID, role, managerID); err != nil {
+			return err
+		}
+		return rows.write(ctx, tx, models.AuditUserUpdateRole)
+	})
 }
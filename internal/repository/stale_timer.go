@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/lib/pq"
+)
+
+// Запущенные таймеры, идущие дольше $1 секунд
+const staleTimersWhere = "end_time IS NULL AND start_time < LOCALTIMESTAMP - $1::float8 * INTERVAL '1 second'"
+
+// Останавливает таймеры, идущие дольше olderThan, через limit после запуска и помечает их
+// auto_closed и needs_review. Возвращает число остановленных таймеров.
+func (r *Repository) CapStaleTimers(ctx context.Context, olderThan, limit time.Duration) (int, error) {
+	return r.closeStaleTimers(ctx, olderThan, "l.start_time + $2::float8 * INTERVAL '1 second'", limit.Seconds())
+}
+
+// Останавливает таймеры, идущие дольше olderThan, в конце рабочего дня запуска (dayEnd от полуночи).
+// Таймер, запущенный после конца рабочего дня, останавливается временем запуска.
+func (r *Repository) CloseStaleTimersAtDayEnd(ctx context.Context, olderThan, dayEnd time.Duration) (int, error) {
+	end := "GREATEST(l.start_time, date_trunc('day', l.start_time) + $2::float8 * INTERVAL '1 second')"
+	return r.closeStaleTimers(ctx, olderThan, end, dayEnd.Seconds())
+}
+
+// closeStaleTimers останавливает забытые таймеры временем endExpr, но не позже текущего момента,
+// и тем же запросом закрывает их идущие перерывы
+func (r *Repository) closeStaleTimers(ctx context.Context, olderThan time.Duration, endExpr string, arg float64) (int, error) {
+	var count int
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		stale, err := lockForAudit(ctx, tx, "task_logs", staleTimersWhere, olderThan.Seconds())
+		if err != nil {
+			return err
+		}
+		query := `
+			WITH closed AS (
+				UPDATE task_logs l
+				SET end_time = LEAST(` + endExpr + `, LOCALTIMESTAMP), auto_closed = TRUE, needs_review = TRUE
+				WHERE l.id = ANY($1)
+				RETURNING l.id, l.end_time
+			)
+			UPDATE task_log_pauses p
+			SET end_time = GREATEST(p.start_time, c.end_time)
+			FROM closed c
+			WHERE p.task_log_id = c.id AND p.end_time IS NULL
+		`
+		if _, err := tx.ExecContext(ctx, query, pq.Array(stale.ids), arg); err != nil {
+			return err
+		}
+		count = len(stale.ids)
+		return stale.write(ctx, tx, models.AuditTimerAutoStop)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Помечает таймеры, идущие дольше olderThan, как требующие проверки, не останавливая их
+func (r *Repository) FlagStaleTimers(ctx context.Context, olderThan time.Duration) (int, error) {
+	return r.updateTimers(ctx, staleTimersWhere+" AND NOT needs_review", []any{olderThan.Seconds()}, "needs_review = TRUE", models.AuditTimeEntryFlag)
+}
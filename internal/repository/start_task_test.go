@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/bigxxby/effective-mobile-test/pkg/migrations"
+	"github.com/lib/pq"
+)
+
+// Одновременные запуски одного таймера в PostgreSQL: успешен ровно один, остальные упираются
+// в проверку или в индекс task_logs_open_timer_idx и получают ErrTaskAlreadyStarted.
+// Нужен PostgreSQL: TEST_DATABASE_DSN в формате lib/pq, иначе тест пропускается.
+// Тест работает в отдельной временной схеме.
+func TestStartTaskConcurrent(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		var err error
+		if dsn, err = pq.ParseURL(dsn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+	schema := fmt.Sprintf("repository_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+
+	db, err := sql.Open("postgres", dsn+" search_path="+schema+" timezone=UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	repo := New(db)
+	userID, err := repo.CreateUser(ctx, models.User{PassportNumber: "1234 567890"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	taskID, err := repo.CreateTask(ctx, models.Task{Name: "Task", Status: models.TaskStatusTodo})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const starts = 50
+	errs := make(chan error, starts)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < starts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := repo.StartTask(ctx, userID, taskID, models.TimerPolicyParallel)
+			errs <- err
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	started := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			started++
+		case !errors.Is(err, models.ErrTaskAlreadyStarted):
+			t.Errorf("StartTask err = %v, want nil or %v", err, models.ErrTaskAlreadyStarted)
+		}
+	}
+	if started != 1 {
+		t.Errorf("started %d timers, want 1", started)
+	}
+
+	var open int
+	if err := db.QueryRow("SELECT COUNT(*) FROM task_logs WHERE user_id = $1 AND task_id = $2 AND end_time IS NULL", userID, taskID).Scan(&open); err != nil {
+		t.Fatal(err)
+	}
+	if open != 1 {
+		t.Errorf("open timers = %d, want 1", open)
+	}
+
+	// второй открытый таймер не пропускает сам индекс, даже в обход проверки StartTask
+	_, err = db.Exec("INSERT INTO task_logs (user_id, task_id, start_time) VALUES ($1, $2, NOW())", userID, taskID)
+	if err := translateError(err); !errors.Is(err, models.ErrTaskAlreadyStarted) {
+		t.Errorf("duplicate open timer err = %v, want %v", err, models.ErrTaskAlreadyStarted)
+	}
+}
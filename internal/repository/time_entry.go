@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/lib/pq"
+)
+
+// Получение записей времени пользователя, пересекающих период.
+// Нулевые даты не ограничивают период. Записи идут от новых к старым.
+func (r *Repository) GetTimeEntries(ctx context.Context, userID int, startDate, endDate time.Time, pagination models.Pagination) (models.Page[models.TimeEntry], error) {
+	where := ""
+	args := []interface{}{userID}
+	argCount := 2
+
+	if !startDate.IsZero() {
+		where += " AND COALESCE(end_time, LOCALTIMESTAMP) > $" + strconv.Itoa(argCount)
+		args = append(args, startDate)
+		argCount++
+	}
+	if !endDate.IsZero() {
+		where += " AND start_time < $" + strconv.Itoa(argCount)
+		args = append(args, endDate)
+		argCount++
+	}
+
+	var total int
+	if err := r.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM task_logs WHERE user_id = $1"+where, args...).Scan(&total); err != nil {
+		return models.Page[models.TimeEntry]{}, err
+	}
+
+	cursorWhere, tail, pageArgs := pageClause("start_time", "desc", pagination, argCount)
+	query := "SELECT id, user_id, task_id, start_time, end_time, note, needs_review, auto_closed FROM task_logs WHERE user_id = $1" + where + cursorWhere + tail
+	args = append(args, pageArgs...)
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return models.Page[models.TimeEntry]{}, err
+	}
+	defer rows.Close()
+
+	var entries []models.TimeEntry
+	for rows.Next() {
+		var entry models.TimeEntry
+		err := rows.Scan(&entry.ID, &entry.UserID, &entry.TaskID, &entry.StartTime, &entry.EndTime, &entry.Note, &entry.NeedsReview, &entry.AutoClosed)
+		if err != nil {
+			return models.Page[models.TimeEntry]{}, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Page[models.TimeEntry]{}, err
+	}
+
+	return newPage(entries, total, pagination, "start_time", "desc", timeEntrySortKey), nil
+}
+
+// timeEntrySortKey — ключ сортировки записей времени: от новых к старым по началу
+func timeEntrySortKey(entry models.TimeEntry) (string, int64) {
+	return models.SortTime(entry.StartTime), int64(entry.ID)
+}
+
+func (r *Repository) GetTimeEntry(ctx context.Context, entryID int) (models.TimeEntry, error) {
+	query := `
+		SELECT id, user_id, task_id, start_time, end_time, note, needs_review, auto_closed
+		FROM task_logs
+		WHERE id = $1
+	`
+	var entry models.TimeEntry
+	err := r.DB.QueryRowContext(ctx, query, entryID).Scan(&entry.ID, &entry.UserID, &entry.TaskID, &entry.StartTime, &entry.EndTime, &entry.Note, &entry.NeedsReview, &entry.AutoClosed)
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// Создание записи времени с явными началом и концом, ErrTimeEntryOverlap если она пересекает
+// другую запись пользователя
+func (r *Repository) CreateTimeEntry(ctx context.Context, entry models.TimeEntry) (int, error) {
+	var id int
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := checkTimeEntryOverlap(ctx, tx, entry, 0); err != nil {
+			return err
+		}
+		query := `
+			INSERT INTO task_logs (user_id, task_id, start_time, end_time, note)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`
+		err := tx.QueryRowContext(ctx, query, entry.UserID, entry.TaskID, entry.StartTime, entry.EndTime, entry.Note).Scan(&id)
+		if err != nil {
+			return err
+		}
+		return createdForAudit("task_logs", id).write(ctx, tx, models.AuditTimeEntryCreate)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Обновление записи времени, sql.ErrNoRows если записи нет, ErrTimeEntryOverlap если она пересечёт
+// другую запись пользователя. Исправленная вручную запись больше не требует проверки,
+// а её идущий перерыв закрывается новым концом записи.
+func (r *Repository) UpdateTimeEntry(ctx context.Context, entryID int, entry models.TimeEntry) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := checkTimeEntryOverlap(ctx, tx, entry, entryID); err != nil {
+			return err
+		}
+		rows, err := lockOneForAudit(ctx, tx, "task_logs", "id = $1", entryID)
+		if err != nil {
+			return err
+		}
+		query := `
+			UPDATE task_logs
+			SET task_id = $2, start_time = $3, end_time = $4, note = $5, needs_review = FALSE
+			WHERE id = $1
+		`
+		if _, err := tx.ExecContext(ctx, query, entryID, entry.TaskID, entry.StartTime, entry.EndTime, entry.Note); err != nil {
+			return err
+		}
+		if entry.EndTime != nil {
+			query := `
+				UPDATE task_log_pauses
+				SET end_time = GREATEST(start_time, $2)
+				WHERE task_log_id = $1 AND end_time IS NULL
+			`
+			if _, err := tx.ExecContext(ctx, query, entryID, *entry.EndTime); err != nil {
+				return err
+			}
+		}
+		return rows.write(ctx, tx, models.AuditTimeEntryUpdate)
+	})
+}
+
+// Удаление записи времени, sql.ErrNoRows если записи нет
+func (r *Repository) DeleteTimeEntry(ctx context.Context, entryID int) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		rows, err := lockOneForAudit(ctx, tx, "task_logs", "id = $1", entryID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM task_logs WHERE id = $1", entryID); err != nil {
+			return err
+		}
+		return rows.write(ctx, tx, models.AuditTimeEntryDelete)
+	})
+}
+
+// checkTimeEntryOverlap возвращает ErrTimeEntryOverlap, если у пользователя есть другая запись,
+// пересекающая [entry.StartTime, entry.EndTime); запущенные таймеры идут до текущего момента.
+// Строка пользователя блокируется до конца транзакции, чтобы одновременные записи одного
+// пользователя проверялись по очереди и видели друг друга.
+func checkTimeEntryOverlap(ctx context.Context, tx *sql.Tx, entry models.TimeEntry, excludeID int) error {
+	err := tx.QueryRowContext(ctx, "SELECT id FROM users WHERE id = $1 FOR UPDATE", entry.UserID).Scan(new(int))
+	if err == sql.ErrNoRows {
+		return models.ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM task_logs
+			WHERE user_id = $1 AND id <> $4
+			  AND start_time < $3 AND COALESCE(end_time, LOCALTIMESTAMP) > $2
+		)
+	`
+	var exists bool
+	if err := tx.QueryRowContext(ctx, query, entry.UserID, entry.StartTime, entry.EndTime, excludeID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return models.ErrTimeEntryOverlap
+	}
+	return nil
+}
+
+// Останавливает все запущенные таймеры текущим временем и помечает их auto_closed.
+// Возвращает число остановленных таймеров.
+func (r *Repository) StopOpenTimers(ctx context.Context) (int, error) {
+	return r.updateTimers(ctx, "end_time IS NULL", nil, "end_time = LOCALTIMESTAMP, auto_closed = TRUE, needs_review = TRUE", models.AuditTimerAutoStop)
+}
+
+// Число запущенных таймеров
+func (r *Repository) CountOpenTimers(ctx context.Context) (int, error) {
+	var count int
+	err := r.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM task_logs WHERE end_time IS NULL").Scan(&count)
+	return count, err
+}
+
+// Помечает все запущенные таймеры как требующие проверки
+func (r *Repository) FlagOpenTimers(ctx context.Context) (int, error) {
+	return r.updateTimers(ctx, "end_time IS NULL AND NOT needs_review", nil, "needs_review = TRUE", models.AuditTimeEntryFlag)
+}
+
+// updateTimers применяет set к записям task_logs, подходящим под where с аргументами args,
+// и записывает событие action по каждой. Если set останавливает записи, их идущие перерывы
+// закрываются тем же временем. Возвращает число изменённых записей.
+func (r *Repository) updateTimers(ctx context.Context, where string, args []any, set, action string) (int, error) {
+	var count int
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		rows, err := lockForAudit(ctx, tx, "task_logs", where, args...)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE task_logs SET "+set+" WHERE id = ANY($1)", pq.Array(rows.ids)); err != nil {
+			return err
+		}
+		query := `
+			UPDATE task_log_pauses p
+			SET end_time = GREATEST(p.start_time, l.end_time)
+			FROM task_logs l
+			WHERE p.task_log_id = l.id AND l.id = ANY($1) AND l.end_time IS NOT NULL AND p.end_time IS NULL
+		`
+		if _, err := tx.ExecContext(ctx, query, pq.Array(rows.ids)); err != nil {
+			return err
+		}
+		count = len(rows.ids)
+		return rows.write(ctx, tx, action)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Запущенные таймеры в порядке запуска. Длительности считаются базой на момент запроса.
+func (r *Repository) GetActiveTimers(ctx context.Context, filter models.ActiveTimerFilter) ([]models.ActiveTimer, error) {
+	query := `
+		SELECT l.id, l.user_id, l.task_id, l.start_time, l.end_time, l.note, l.needs_review, l.auto_closed,
+		       COALESCE(u.surname, ''), COALESCE(u.name, ''), t.task_name, t.project_id,
+		       p.paused, EXTRACT(EPOCH FROM LOCALTIMESTAMP - l.start_time), p.paused_seconds
+		FROM task_logs l
+		INNER JOIN users u ON l.user_id = u.id
+		INNER JOIN tasks t ON l.task_id = t.id
+		LEFT JOIN LATERAL (
+			SELECT COALESCE(BOOL_OR(p.end_time IS NULL), FALSE) AS paused,
+			       COALESCE(SUM(EXTRACT(EPOCH FROM COALESCE(p.end_time, LOCALTIMESTAMP) - p.start_time)), 0) AS paused_seconds
+			FROM task_log_pauses p
+			WHERE p.task_log_id = l.id
+		) p ON TRUE
+		WHERE l.end_time IS NULL`
+	var args []interface{}
+	argCount := 1
+
+	if filter.UserID != 0 {
+		query += " AND l.user_id = $" + strconv.Itoa(argCount)
+		args = append(args, filter.UserID)
+		argCount++
+	}
+	if filter.TaskID != 0 {
+		query += " AND l.task_id = $" + strconv.Itoa(argCount)
+		args = append(args, filter.TaskID)
+		argCount++
+	}
+	if filter.ProjectID != 0 {
+		query += " AND t.project_id = $" + strconv.Itoa(argCount)
+		args = append(args, filter.ProjectID)
+		argCount++
+	}
+	if filter.ManagerID != 0 {
+		query += " AND u.manager_id = $" + strconv.Itoa(argCount)
+		args = append(args, filter.ManagerID)
+		argCount++
+	}
+	query += " ORDER BY l.start_time, l.id"
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	timers := []models.ActiveTimer{}
+	for rows.Next() {
+		var timer models.ActiveTimer
+		var elapsed, paused float64
+		entry := &timer.TimeEntry
+		err := rows.Scan(&entry.ID, &entry.UserID, &entry.TaskID, &entry.StartTime, &entry.EndTime, &entry.Note, &entry.NeedsReview, &entry.AutoClosed,
+			&timer.UserSurname, &timer.UserName, &timer.TaskName, &timer.ProjectID,
+			&timer.Paused, &elapsed, &paused)
+		if err != nil {
+			return nil, err
+		}
+		timer.ElapsedSeconds = int64(elapsed)
+		timer.WorkedSeconds = int64(elapsed - paused)
+		timers = append(timers, timer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return timers, nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/lib/pq"
+)
+
+// Коды ошибок PostgreSQL
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// withTx выполняет fn в транзакции: ошибка fn откатывает её, иначе транзакция фиксируется
+func (r *Repository) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// translateError превращает нарушения ограничений task_logs и task_log_pauses в доменные ошибки
+func translateError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch {
+	case pqErr.Code == pgUniqueViolation && pqErr.Constraint == "task_logs_open_timer_idx":
+		return models.ErrTaskAlreadyStarted.Wrap(err)
+	case pqErr.Code == pgUniqueViolation && pqErr.Constraint == "task_log_pauses_open_idx":
+		return models.ErrTaskAlreadyPaused.Wrap(err)
+	case pqErr.Code == pgForeignKeyViolation && pqErr.Constraint == "task_logs_user_id_fkey":
+		return models.ErrUserNotFound.Wrap(err)
+	case pqErr.Code == pgForeignKeyViolation && pqErr.Constraint == "task_logs_task_id_fkey":
+		return models.ErrTaskNotFound.Wrap(err)
+	}
+	return err
+}
@@ -0,0 +1,198 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/auth"
+	"github.com/bigxxby/effective-mobile-test/internal/controller"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/bigxxby/effective-mobile-test/internal/repository"
+	"github.com/bigxxby/effective-mobile-test/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// authRouter — маршруты API с включённой авторизацией. Пользователи: 1 — admin, 2 — manager,
+// 3 — сотрудник из команды 2, 4 — сотрудник без руководителя.
+func authRouter(t *testing.T) (*gin.Engine, *repository.Memory, *auth.Authenticator) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	repo := repository.NewMemory()
+	for _, passport := range []string{"1111 111111", "2222 222222", "3333 333333", "4444 444444"} {
+		if _, err := repo.CreateUser(ctx, models.User{PassportNumber: passport}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	managerID := 2
+	for userID, role := range map[int]string{1: models.RoleAdmin, 2: models.RoleManager} {
+		if err := repo.UpdateUserRole(ctx, userID, role, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.UpdateUserRole(ctx, 3, models.RoleEmployee, &managerID); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, active, err := auth.ParseKeys("k1:0123456789abcdef0123456789abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator, err := auth.New(auth.Config{Keys: keys, ActiveKeyID: active, TokenTTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := service.New(repo, nil)
+	c := controller.New(s, authenticator)
+	router := gin.New()
+	RegisterRoutes(router, &c, auth.NewGuard(authenticator, c.Service), Timeouts{Read: time.Second, Write: time.Second})
+	return router, repo, authenticator
+}
+
+// bearer выпускает токен пользователя с ролью role
+func bearer(t *testing.T, authenticator *auth.Authenticator, userID int, role string) string {
+	t.Helper()
+	token, _, err := authenticator.IssueToken(auth.Principal{UserID: userID, Role: role}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
+
+// Руководитель видит в списке пользователей только свою команду, администратор — всех
+func TestGetUsersManagerSeesOwnTeam(t *testing.T) {
+	router, _, authenticator := authRouter(t)
+
+	tests := []struct {
+		name   string
+		header string
+		want   []int
+	}{
+		{"admin", bearer(t, authenticator, 1, models.RoleAdmin), []int{1, 2, 3, 4}},
+		{"manager", bearer(t, authenticator, 2, models.RoleManager), []int{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+			req.Header.Set("Authorization", tt.header)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != 200 {
+				t.Fatalf("status = %d, want 200 (%s)", rec.Code, rec.Body.String())
+			}
+			var body models.ResponseUsersList
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			var ids []int
+			for _, user := range body.Users {
+				ids = append(ids, user.ID)
+			}
+			if !slices.Equal(ids, tt.want) || body.Total != len(tt.want) {
+				t.Errorf("users = %v, total %d, want %v", ids, body.Total, tt.want)
+			}
+		})
+	}
+}
+
+// Токен перестаёт действовать сразу после удаления пользователя или смены его роли
+func TestTokenRevokedByUserChange(t *testing.T) {
+	router, repo, authenticator := authRouter(t)
+	ctx := context.Background()
+
+	status := func(method, path, header, body string) int {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", header)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	manager := bearer(t, authenticator, 2, models.RoleManager)
+	employee := bearer(t, authenticator, 3, models.RoleEmployee)
+	if got := status(http.MethodGet, "/api/users", manager, ""); got != 200 {
+		t.Fatalf("manager before demotion status = %d, want 200", got)
+	}
+	if err := repo.UpdateUserRole(ctx, 2, models.RoleEmployee, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := status(http.MethodGet, "/api/users", manager, ""); got != 401 {
+		t.Errorf("manager after demotion status = %d, want 401", got)
+	}
+
+	if got := status(http.MethodGet, "/api/users/3", employee, ""); got != 200 {
+		t.Fatalf("employee before deletion status = %d, want 200", got)
+	}
+	if err := repo.DeleteUser(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if got := status(http.MethodGet, "/api/users/3", employee, ""); got != 401 {
+		t.Errorf("deleted employee status = %d, want 401", got)
+	}
+
+	admin := bearer(t, authenticator, 1, models.RoleAdmin)
+	if got := status(http.MethodPost, "/api/auth/token", admin, `{"user_id": 4, "ttl": "87600h"}`); got != 400 {
+		t.Errorf("token with ttl above the maximum status = %d, want 400", got)
+	}
+	if got := status(http.MethodPost, "/api/auth/token", admin, `{"user_id": 4, "ttl": "1h"}`); got != 201 {
+		t.Errorf("token status = %d, want 201", got)
+	}
+}
+
+// Руководитель видит запущенные таймеры только своей команды, администратор — все
+func TestGetActiveTimersManagerSeesOwnTeam(t *testing.T) {
+	router, repo, authenticator := authRouter(t)
+	ctx := context.Background()
+
+	taskID, err := repo.CreateTask(ctx, models.Task{Name: "Design", Status: models.TaskStatusTodo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, userID := range []int{3, 4} {
+		if _, err := repo.StartTask(ctx, userID, taskID, models.TimerPolicyParallel); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		want   []int
+	}{
+		{"admin", bearer(t, authenticator, 1, models.RoleAdmin), []int{3, 4}},
+		{"manager", bearer(t, authenticator, 2, models.RoleManager), []int{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/active", nil)
+			req.Header.Set("Authorization", tt.header)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != 200 {
+				t.Fatalf("status = %d, want 200 (%s)", rec.Code, rec.Body.String())
+			}
+			var body models.ResponseActiveTimers
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			var ids []int
+			for _, timer := range body.ActiveTimers {
+				ids = append(ids, timer.TimeEntry.UserID)
+			}
+			slices.Sort(ids)
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("timer users = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
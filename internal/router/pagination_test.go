@@ -0,0 +1,103 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/auth"
+	"github.com/bigxxby/effective-mobile-test/internal/controller"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/bigxxby/effective-mobile-test/internal/repository"
+	"github.com/bigxxby/effective-mobile-test/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Список отдаёт total, курсоры соседних страниц и заголовок Link в обоих режимах пагинации
+func TestListPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	repo := repository.NewMemory()
+	for _, passport := range []string{"1111 111111", "2222 222222", "3333 333333", "4444 444444", "5555 555555"} {
+		if _, err := repo.CreateUser(ctx, models.User{PassportNumber: passport, Surname: "Smith"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := controller.New(service.New(repo, nil), nil)
+	router := gin.New()
+	RegisterRoutes(router, &c, auth.NewGuard(nil, nil), Timeouts{Read: time.Second, Write: time.Second})
+
+	get := func(target string) (models.ResponseUsersList, map[string]string, int) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		var body models.ResponseUsersList
+		if w.Code == 200 {
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return body, parseLinks(t, w.Header().Get("Link")), w.Code
+	}
+
+	body, links, status := get("/api/users?surname=Smith&page=2&page_size=2")
+	if status != 200 || len(body.Users) != 2 || body.Users[0].ID != 3 || body.Total != 5 || body.NextCursor == "" || body.PrevCursor == "" {
+		t.Fatalf("offset page = %d %+v", status, body)
+	}
+	wantLinks := map[string]string{
+		"first": "/api/users?page=1&page_size=2&surname=Smith",
+		"prev":  "/api/users?page=1&page_size=2&surname=Smith",
+		"next":  "/api/users?page=3&page_size=2&surname=Smith",
+		"last":  "/api/users?page=3&page_size=2&surname=Smith",
+	}
+	for rel, want := range wantLinks {
+		if links[rel] != want {
+			t.Errorf("offset Link %s = %q, want %q", rel, links[rel], want)
+		}
+	}
+
+	// переход по ссылке next в режиме курсора
+	body, links, status = get("/api/users?surname=Smith&page_size=2&cursor=" + url.QueryEscape(body.NextCursor))
+	if status != 200 || len(body.Users) != 1 || body.Users[0].ID != 5 || body.Total != 5 || body.NextCursor != "" {
+		t.Fatalf("cursor page = %d %+v", status, body)
+	}
+	if links["next"] != "" || links["first"] != "/api/users?page_size=2&surname=Smith" ||
+		!strings.Contains(links["prev"], "cursor="+url.QueryEscape(body.PrevCursor)) {
+		t.Errorf("cursor Link = %v", links)
+	}
+	body, _, status = get(links["prev"])
+	if status != 200 || len(body.Users) != 2 || body.Users[0].ID != 3 || body.Users[1].ID != 4 {
+		t.Errorf("prev page = %d %+v, want users 3 and 4", status, body)
+	}
+
+	if _, _, status := get("/api/users?cursor=not-a-cursor"); status != 400 {
+		t.Errorf("invalid cursor status = %d, want 400", status)
+	}
+	if _, _, status := get("/api/users?sort_by=surname&cursor=" + url.QueryEscape(body.NextCursor)); status != 400 {
+		t.Errorf("cursor for another sort status = %d, want 400", status)
+	}
+}
+
+// parseLinks разбирает заголовок Link вида <uri>; rel="name", ... в отображение rel -> uri
+func parseLinks(t *testing.T, header string) map[string]string {
+	t.Helper()
+	links := make(map[string]string)
+	if header == "" {
+		return links
+	}
+	for _, part := range strings.Split(header, ", ") {
+		target, params, ok := strings.Cut(part, "; ")
+		if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") || !strings.HasPrefix(params, `rel="`) {
+			t.Fatalf("malformed Link %q", header)
+		}
+		links[strings.TrimSuffix(strings.TrimPrefix(params, `rel="`), `"`)] = target[1 : len(target)-1]
+	}
+	return links
+}
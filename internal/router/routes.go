@@ -1,26 +1,90 @@
 package router
 
 import (
+	"net/http"
+
 	_ "github.com/bigxxby/effective-mobile-test/docs"
+	"github.com/bigxxby/effective-mobile-test/internal/apperr"
+	"github.com/bigxxby/effective-mobile-test/internal/auth"
 	"github.com/bigxxby/effective-mobile-test/internal/controller"
+	"github.com/bigxxby/effective-mobile-test/internal/health"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func RegisterRoutes(router *gin.Engine, controller *controller.Controller) {
+// RegisterRoutes регистрирует маршруты API. Ошибки /api отдаются как application/problem+json.
+// apperr.Middleware стоит внутри Deadline: ошибка пишется, пока срок операции ещё не отменён,
+// и статус 504 или 499 зависит от того, истёк ли срок или клиент ушёл сам.
+// Все маршруты /api требуют токен:
+// admin управляет пользователями и ролями, manager — задачами и проектами и видит
+// свою команду, employee работает только со своим временем.
+func RegisterRoutes(router *gin.Engine, controller *controller.Controller, guard auth.Guard, timeouts Timeouts) {
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	router.GET("/api/users", controller.GetUsers)
-	router.GET("/api/users/:id", controller.GetUser)
-	router.POST("/api/users", controller.CreateUser)
-	router.PUT("/api/users/:id", controller.UpdateUser)
+	api := router.Group("/api", timeouts.Deadline(), apperr.Middleware(), guard.Authenticate())
+	admin := guard.RequireRole(models.RoleAdmin)
+	staff := guard.RequireRole(models.RoleAdmin, models.RoleManager)
+	self := guard.RequireUserAccess("id")
+
+	api.GET("/auth/me", controller.Me)
+	api.POST("/auth/token", admin, controller.IssueToken)
+
+	api.GET("/users", staff, controller.GetUsers)
+	api.GET("/users/:id", self, controller.GetUser)
+	api.POST("/users", admin, controller.CreateUser)
+	api.PUT("/users/:id", admin, controller.UpdateUser)
+	api.PUT("/users/:id/role", admin, controller.UpdateUserRole)
+	api.GET("/audit", admin, controller.GetAuditEvents)
+
+	api.GET("/users/:id/workloads", self, controller.GetUserWorkloadsByUserID)
+	api.GET("/users/:id/active", self, controller.GetUserActiveTimers)
+	api.GET("/active", staff, controller.GetActiveTimers)
+
+	api.GET("/users/:id/time-entries", self, controller.GetTimeEntries)
+	api.POST("/users/:id/time-entries", self, controller.CreateTimeEntry)
+	api.GET("/users/:id/time-entries/:entryId", self, controller.GetTimeEntry)
+	api.GET("/users/:id/time-entries/:entryId/segments", self, controller.GetTimeEntrySegments)
+	api.PUT("/users/:id/time-entries/:entryId", self, controller.UpdateTimeEntry)
+	api.DELETE("/users/:id/time-entries/:entryId", self, controller.DeleteTimeEntry)
 
-	router.GET("/api/users/:id/workloads", controller.GetUserWorkloadsByUserID)
+	api.POST("/users/:id/tasks/:taskId/start", self, controller.StartTask)
+	api.POST("/users/:id/tasks/:taskId/stop", self, controller.EndTask)
+	api.POST("/users/:id/tasks/:taskId/pause", self, controller.PauseTask)
+	api.POST("/users/:id/tasks/:taskId/resume", self, controller.ResumeTask)
+	api.GET("/tasks", controller.GetTasks)
+	api.GET("/tasks/:id", controller.GetTask)
+	api.POST("/tasks", staff, controller.CreateTask)
+	api.PUT("/tasks/:id", staff, controller.UpdateTask)
+	api.PATCH("/tasks/:id", staff, controller.PatchTask)
+	api.DELETE("/tasks/:id", staff, controller.DeleteTask)
 
-	router.POST("/api/users/:id/tasks/:taskId/start", controller.StartTask)
-	router.POST("/api/users/:id/tasks/:taskId/stop", controller.EndTask)
-	router.GET("/api/tasks", controller.GetTasks)
+	api.GET("/projects", controller.GetProjects)
+	api.GET("/projects/:id", controller.GetProject)
+	api.POST("/projects", staff, controller.CreateProject)
+	api.PUT("/projects/:id", staff, controller.UpdateProject)
+	api.DELETE("/projects/:id", staff, controller.DeleteProject)
+	api.GET("/projects/:id/members", controller.GetProjectMembers)
+	api.POST("/projects/:id/members", staff, controller.AddProjectMember)
+	api.DELETE("/projects/:id/members/:userId", staff, controller.RemoveProjectMember)
+	api.GET("/projects/:id/workloads", staff, controller.GetProjectWorkloads)
+
+	api.DELETE("/users/:id", admin, controller.DeleteUser)
+	api.POST("/users/:id/restore", admin, controller.RestoreUser)
+	api.POST("/users/:id/purge", admin, controller.PurgeUser)
+}
+
+// RegisterHealthRoutes регистрирует проверки для оркестратора и балансировщика.
+// Они вне /api: без токена и без сроков операций.
+func RegisterHealthRoutes(router *gin.Engine, handler *health.Handler) {
+	router.GET("/healthz", handler.Healthz)
+	router.GET("/readyz", handler.Readyz)
+	router.GET("/version", handler.Version)
+}
 
-	router.DELETE("/api/users/:id", controller.DeleteUser)
+// RegisterMetricsRoute отдаёт метрики Prometheus на /metrics без токена;
+// закрывать его от внешней сети нужно на уровне сети или прокси
+func RegisterMetricsRoute(router *gin.Engine, handler http.Handler) {
+	router.GET("/metrics", gin.WrapH(handler))
 }
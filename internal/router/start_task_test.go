@@ -0,0 +1,79 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/auth"
+	"github.com/bigxxby/effective-mobile-test/internal/controller"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/bigxxby/effective-mobile-test/internal/repository"
+	"github.com/bigxxby/effective-mobile-test/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Одновременные запросы на запуск одного таймера: успешен ровно один, остальные получают 409
+func TestStartTaskConcurrent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	repo := repository.NewMemory()
+	userID, err := repo.CreateUser(ctx, models.User{PassportNumber: "1234 567890"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	taskID, err := repo.CreateTask(ctx, models.Task{Name: "Task", Status: models.TaskStatusTodo})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := controller.New(service.New(repo, nil), nil)
+	router := gin.New()
+	RegisterRoutes(router, &c, auth.NewGuard(nil, nil), Timeouts{Read: time.Second, Write: time.Second})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	const requests = 50
+	url := fmt.Sprintf("%s/api/users/%d/tasks/%d/start", server.URL, userID, taskID)
+	statuses := make(chan int, requests)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			resp, err := http.Post(url, "application/json", nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(statuses)
+
+	counts := make(map[int]int)
+	for status := range statuses {
+		counts[status]++
+	}
+	if counts[200] != 1 || counts[409] != requests-1 {
+		t.Errorf("statuses = %v, want one 200 and %d 409", counts, requests-1)
+	}
+
+	entries, err := repo.GetTimeEntries(ctx, userID, time.Time{}, time.Time{}, models.Pagination{Page: 1, PageSize: requests})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries.Total != 1 {
+		t.Errorf("time entries = %d, want 1", entries.Total)
+	}
+}
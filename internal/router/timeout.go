@@ -0,0 +1,48 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeouts — сроки выполнения операций API. Срок кладётся в контекст запроса и через
+// сервис доходит до запросов к базе и внешнему API; 0 — без ограничения.
+type Timeouts struct {
+	// Read — чтение (GET)
+	Read time.Duration
+	// Write — изменения (POST, PUT, PATCH, DELETE)
+	Write time.Duration
+	// Report — отчёты о трудозатратах, самые тяжёлые запросы
+	Report time.Duration
+}
+
+func (t Timeouts) forRequest(ctx *gin.Context) time.Duration {
+	switch {
+	case strings.HasSuffix(ctx.FullPath(), "/workloads"):
+		return t.Report
+	case ctx.Request.Method == http.MethodGet || ctx.Request.Method == http.MethodHead:
+		return t.Read
+	default:
+		return t.Write
+	}
+}
+
+// Deadline ограничивает время обработки запроса сроком операции
+func (t Timeouts) Deadline() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		timeout := t.forRequest(ctx)
+		if timeout <= 0 {
+			ctx.Next()
+			return
+		}
+
+		requestCtx, cancel := context.WithTimeout(ctx.Request.Context(), timeout)
+		defer cancel()
+		ctx.Request = ctx.Request.WithContext(requestCtx)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,115 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/apperr"
+	"github.com/bigxxby/effective-mobile-test/internal/auth"
+	"github.com/bigxxby/effective-mobile-test/internal/controller"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/bigxxby/effective-mobile-test/internal/repository"
+	"github.com/bigxxby/effective-mobile-test/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// slowRepository ждёт отмены контекста в GetUsers, как зависший запрос к базе
+type slowRepository struct {
+	*repository.Memory
+}
+
+func (r slowRepository) GetUsers(ctx context.Context, filter models.Filter, pagination models.Pagination, sortBy, sortOrder string) (models.Page[models.User], error) {
+	<-ctx.Done()
+	return models.Page[models.User]{}, ctx.Err()
+}
+
+func TestDeadline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c := controller.New(service.New(slowRepository{repository.NewMemory()}, nil), nil)
+	router := gin.New()
+	RegisterRoutes(router, &c, auth.NewGuard(nil, nil), Timeouts{Read: 20 * time.Millisecond, Write: time.Minute})
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	if rec.Code != 504 {
+		t.Errorf("status = %d, want 504", rec.Code)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("request took %v, want about 20ms", elapsed)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tasks", nil))
+	if rec.Code != 200 {
+		t.Errorf("fast request status = %d, want 200", rec.Code)
+	}
+}
+
+// failingRepository отвечает на GetUsers ошибкой базы, не связанной со сроком запроса
+type failingRepository struct {
+	*repository.Memory
+}
+
+func (r failingRepository) GetUsers(ctx context.Context, filter models.Filter, pagination models.Pagination, sortBy, sortOrder string) (models.Page[models.User], error) {
+	return models.Page[models.User]{}, errors.New("pq: connection reset by peer")
+}
+
+// Ошибка внутри срока операции остаётся 500: срок отменяется только после ответа
+func TestDeadlineKeepsInternalError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c := controller.New(service.New(failingRepository{repository.NewMemory()}, nil), nil)
+	router := gin.New()
+	RegisterRoutes(router, &c, auth.NewGuard(nil, nil), Timeouts{Read: 5 * time.Second, Write: time.Minute})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	if rec.Code != 500 {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != apperr.ContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, apperr.ContentType)
+	}
+	var problem apperr.Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Status != 500 || problem.Code != "internal" {
+		t.Errorf("problem = %+v, want 500 internal", problem)
+	}
+}
+
+func TestTimeoutsForRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	timeouts := Timeouts{Read: 1 * time.Second, Write: 2 * time.Second, Report: 3 * time.Second}
+
+	tests := []struct {
+		method, path string
+		want         time.Duration
+	}{
+		{http.MethodGet, "/api/users/1", timeouts.Read},
+		{http.MethodPost, "/api/users/1/tasks/1/start", timeouts.Write},
+		{http.MethodDelete, "/api/users/1", timeouts.Write},
+		{http.MethodGet, "/api/users/1/workloads", timeouts.Report},
+		{http.MethodGet, "/api/projects/1/workloads", timeouts.Report},
+	}
+	for _, tt := range tests {
+		var got time.Duration
+		router := gin.New()
+		router.Handle(tt.method, "/api/users/:id", func(ctx *gin.Context) { got = timeouts.forRequest(ctx) })
+		router.Handle(tt.method, "/api/users/:id/tasks/:taskId/start", func(ctx *gin.Context) { got = timeouts.forRequest(ctx) })
+		router.Handle(tt.method, "/api/users/:id/workloads", func(ctx *gin.Context) { got = timeouts.forRequest(ctx) })
+		router.Handle(tt.method, "/api/projects/:id/workloads", func(ctx *gin.Context) { got = timeouts.forRequest(ctx) })
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+		if got != tt.want {
+			t.Errorf("%s %s: timeout = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
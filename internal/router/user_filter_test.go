@@ -0,0 +1,90 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/auth"
+	"github.com/bigxxby/effective-mobile-test/internal/controller"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/bigxxby/effective-mobile-test/internal/repository"
+	"github.com/bigxxby/effective-mobile-test/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Параметры filter[field][op]=value разбираются и проверяются контроллером
+func TestGetUsersFilterQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	repo := repository.NewMemory()
+	for _, user := range []models.User{
+		{PassportNumber: "1111 111111", Surname: "Smith", Name: "John"},
+		{PassportNumber: "2222 222222", Surname: "Smithson", Name: "Anna"},
+		{PassportNumber: "3333 333333", Surname: "Blacksmith", Name: "John"},
+	} {
+		if _, err := repo.CreateUser(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := controller.New(service.New(repo, nil), nil)
+	router := gin.New()
+	RegisterRoutes(router, &c, auth.NewGuard(nil, nil), Timeouts{Read: time.Second, Write: time.Second})
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantIDs    []int
+	}{
+		{name: "legacy exact", query: "surname=Smith", wantStatus: 200, wantIDs: []int{1}},
+		{name: "default operation", query: "filter[name]=John", wantStatus: 200, wantIDs: []int{1, 3}},
+		{name: "case-insensitive prefix", query: "filter[surname][iprefix]=smi", wantStatus: 200, wantIDs: []int{1, 2}},
+		{name: "contains", query: "filter[surname][icontains]=SMITH&filter[name][eq]=John", wantStatus: 200, wantIDs: []int{1, 3}},
+		{name: "id list", query: "filter[id][in]=1,3", wantStatus: 200, wantIDs: []int{1, 3}},
+		{name: "id range", query: "filter[id][gt]=1&filter[id][lte]=2", wantStatus: 200, wantIDs: []int{2}},
+		{name: "full-text", query: "filter[search]=john+smith", wantStatus: 200, wantIDs: []int{1}},
+		{name: "created today", query: "filter[created_at][gte]=" + url.QueryEscape(time.Now().Add(-time.Hour).Format(time.RFC3339)), wantStatus: 200, wantIDs: []int{1, 2, 3}},
+		{name: "unknown field", query: "filter[password]=x", wantStatus: 400},
+		{name: "unknown operation", query: "filter[surname][like]=x", wantStatus: 400},
+		{name: "operation not allowed for field", query: "filter[created_at][prefix]=2024", wantStatus: 400},
+		{name: "malformed key", query: "filter[surname]]=x", wantStatus: 400},
+		{name: "empty value", query: "filter[surname][prefix]=", wantStatus: 400},
+		{name: "two conditions on a text field", query: "filter[surname][prefix]=S&filter[surname][contains]=m", wantStatus: 400},
+		{name: "invalid id list", query: "filter[id][in]=1,x", wantStatus: 400},
+		{name: "invalid time", query: "filter[updated_at][lt]=yesterday", wantStatus: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users?"+tt.query, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != 200 {
+				return
+			}
+			var body struct {
+				Users []models.User `json:"users"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			var ids []int
+			for _, user := range body.Users {
+				ids = append(ids, user.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
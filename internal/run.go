@@ -1,15 +1,26 @@
 package internal
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/bigxxby/effective-mobile-test/internal/auth"
 	"github.com/bigxxby/effective-mobile-test/internal/controller"
+	"github.com/bigxxby/effective-mobile-test/internal/health"
+	"github.com/bigxxby/effective-mobile-test/internal/logging"
+	"github.com/bigxxby/effective-mobile-test/internal/metrics"
 	"github.com/bigxxby/effective-mobile-test/internal/repository"
 	routes "github.com/bigxxby/effective-mobile-test/internal/router"
 	"github.com/bigxxby/effective-mobile-test/internal/service"
 	config "github.com/bigxxby/effective-mobile-test/pkg/config"
+	"github.com/bigxxby/effective-mobile-test/pkg/enrichment"
 	"github.com/bigxxby/effective-mobile-test/pkg/migrations"
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
@@ -30,48 +41,195 @@ import (
 // POST /api/users/{id}/tasks/{taskId}/start
 // POST /api/users/{id}/tasks/{taskId}/stop
 // DELETE /api/users/{id}
-func Run() {
-	config.LoadEnv()
-	host := config.GetEnv("DB_HOST")
-	port := config.GetEnv("DB_PORT")
-	user := config.GetEnv("DB_USER")
-	password := config.GetEnv("DB_PASSWORD")
-	dbname := config.GetEnv("DB_NAME")
-	fmt.Println(host, port, user, password, dbname)
-
-	psqlInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		host, port, user, password, dbname)
+func Run(cfg config.Config) error {
+	if err := SetupLogger(cfg.Log); err != nil {
+		return err
+	}
 
-	db, err := sql.Open("postgres", psqlInfo)
+	// пока база недоступна, подключение повторяется; SIGINT/SIGTERM прерывает ожидание
+	connectCtx, stopConnect := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	db, err := OpenDB(connectCtx, cfg.DB)
+	stopConnect()
 	if err != nil {
-		log.Fatalf("Unable to connect to database: %v\n", err)
+		return err
 	}
+	defer db.Close()
 
-	err = db.Ping()
+	migrator, err := migrations.New(db)
 	if err != nil {
-		log.Fatalf("Unable to ping database: %v\n", err)
+		return err
 	}
-
-	if err := migrations.ApplyMigrations(db); err != nil {
-		log.Fatal(err)
+	// DB_AUTO_MIGRATE=false отключает миграции при старте, тогда их применяют командой migrate up,
+	// а /readyz отвечает 503, пока версия базы не совпадёт с ожидаемой
+	if cfg.DB.AutoMigrate {
+		if _, err := migrator.Up(); err != nil {
+			return err
+		}
+		if cfg.DB.Seed {
+			if err := migrator.Seed(); err != nil {
+				return err
+			}
+		}
 	}
 
 	repo := repository.New(db)
+	service := service.New(&repo, newEnricher(cfg.PeopleInfo))
+	service.TimerPolicy = cfg.Timer.StartPolicy
+
+	metrics := metrics.New()
+	metrics.RegisterDB(db, cfg.DB.Name)
+	metrics.RegisterOpenTimers(service.CountOpenTimers)
+	service.Events = metrics
+
+	authenticator, err := NewAuthenticator(cfg.Auth)
+	if err != nil {
+		return err
+	}
+	if authenticator == nil {
+		slog.Warn("authentication is disabled (AUTH_ENABLED=false)")
+	}
+
+	controller := controller.New(service, authenticator)
+
+	router := gin.New()
+	router.Use(
+		logging.RequestIDMiddleware(),
+		logging.AccessLog(slog.Default()),
+		metrics.Middleware(),
+		logging.Recovery(slog.Default()),
+	)
+	routes.RegisterMetricsRoute(router, metrics.Handler())
+	routes.RegisterHealthRoutes(router, health.New(db, migrator, migrator.Latest()))
+	routes.RegisterRoutes(router, &controller, auth.NewGuard(authenticator, &service), routes.Timeouts{
+		Read:   cfg.Operation.ReadTimeout,
+		Write:  cfg.Operation.WriteTimeout,
+		Report: cfg.Operation.ReportTimeout,
+	})
+
+	// пул соединений закрывается отложенным db.Close уже после остановки сервера
+	staleTimers := newStaleTimers(cfg.StaleTimer)
+	auditRetention := newAuditRetention(cfg.Audit)
+	return serve(newHTTPServer(cfg.HTTP, router), &service, cfg.HTTP.ShutdownTimeout, cfg.Shutdown.OpenTimers,
+		func(ctx context.Context) { service.RunStaleTimers(ctx, staleTimers) },
+		func(ctx context.Context) { service.RunAuditRetention(ctx, auditRetention) })
+}
+
+// SetupLogger настраивает slog по LOG_LEVEL (debug, info, warn, error) и LOG_FORMAT (json, text)
+// и делает его логгером по умолчанию, в том числе для пакета log
+func SetupLogger(cfg config.Log) error {
+	logger, err := logging.New(logging.Config{
+		Level:  cfg.Level,
+		Format: cfg.Format,
+	}, os.Stderr)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// OpenDB подключается к PostgreSQL и настраивает пул соединений. Если база ещё не
+// принимает соединения, ping повторяется с экспоненциальной задержкой от DB_CONNECT_RETRY_DELAY
+// до maxConnectRetryDelay, пока не истечёт DB_CONNECT_TIMEOUT или ctx.
+func OpenDB(ctx context.Context, cfg config.DB) (*sql.DB, error) {
+	// Время хранится в столбцах TIMESTAMP без часового пояса в UTC: сервис пишет время в UTC,
+	// а NOW() и LOCALTIMESTAMP в сессии с timezone=UTC дают то же UTC при любой настройке сервера базы
+	psqlInfo := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s timezone=UTC",
+		quoteDSN(cfg.Host), cfg.Port, quoteDSN(cfg.User), quoteDSN(cfg.Password), quoteDSN(cfg.Name), cfg.SSLMode)
+
+	db, err := sql.Open("postgres", psqlInfo)
 	if err != nil {
-		log.Println(err)
-		return
+		return nil, fmt.Errorf("unable to connect to database: %v", err)
 	}
-	service := service.New(repo)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
-	controller := controller.New(service)
+	if err := pingWithRetry(ctx, db, cfg.ConnectTimeout, cfg.ConnectRetryDelay); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to ping database: %v", err)
+	}
+	return db, nil
+}
+
+const maxConnectRetryDelay = 10 * time.Second
+
+func pingWithRetry(ctx context.Context, db *sql.DB, timeout, delay time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		slog.Warn("database is not available, retrying", "attempt", attempt, "retry_in", delay, "error", err)
 
-	router := gin.Default()
-	router.Use(gin.Logger())
-	routes.RegisterRoutes(router, &controller)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxConnectRetryDelay)
+	}
+}
 
-	log.Println("Server started on http://localhost:8080")
-	err = router.Run(":8080")
+// quoteDSN экранирует значение для строки подключения lib/pq: пароль с пробелом
+// или кавычкой иначе ломает разбор
+func quoteDSN(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// Клиент внешнего API обогащения создается только если задан PEOPLE_INFO_URL
+func newEnricher(cfg config.PeopleInfo) enrichment.Enricher {
+	if cfg.URL == "" {
+		return nil
+	}
+	return enrichment.New(enrichment.Config{
+		BaseURL:    cfg.URL,
+		Timeout:    cfg.Timeout,
+		Retries:    cfg.Retries,
+		RetryDelay: cfg.RetryDelay,
+	})
+}
+
+// Настройки проверки забытых таймеров из STALE_TIMER_*
+func newStaleTimers(cfg config.StaleTimer) service.StaleTimers {
+	return service.StaleTimers{
+		Policy:     cfg.Policy,
+		Threshold:  cfg.Threshold,
+		Interval:   cfg.Interval,
+		Cap:        cfg.Cap,
+		WorkdayEnd: cfg.WorkdayEndOffset(),
+	}
+}
+
+// Настройки хранения журнала аудита из AUDIT_*
+func newAuditRetention(cfg config.Audit) service.AuditRetention {
+	return service.AuditRetention{
+		Retention: cfg.Retention,
+		Interval:  cfg.CleanupInterval,
+	}
+}
+
+// NewAuthenticator собирает проверку токенов из AUTH_* переменных.
+// При AUTH_ENABLED=false возвращает nil, и API доступно без токена.
+func NewAuthenticator(cfg config.Auth) (*auth.Authenticator, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	keys, activeKeyID, err := auth.ParseKeys(cfg.HMACKeys)
 	if err != nil {
-		log.Println(err)
+		return nil, fmt.Errorf("AUTH_HMAC_KEYS: %w", err)
 	}
+	return auth.New(auth.Config{
+		Keys:        keys,
+		ActiveKeyID: activeKeyID,
+		TokenTTL:    cfg.TokenTTL,
+		MaxTokenTTL: cfg.MaxTokenTTL,
+	})
 }
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/service"
+	config "github.com/bigxxby/effective-mobile-test/pkg/config"
+)
+
+func newHTTPServer(cfg config.HTTP, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// serve запускает сервер и ждёт SIGINT/SIGTERM. После сигнала сервер перестаёт принимать
+// соединения и дожидается текущих запросов не дольше shutdownTimeout, затем к запущенным
+// таймерам применяется политика openTimers.
+//
+// workers — фоновые задачи, работающие, пока работает сервер. Их контекст отменяется после
+// остановки сервера, и serve дожидается их завершения до обработки таймеров и закрытия базы.
+func serve(server *http.Server, svc *service.Service, shutdownTimeout time.Duration, openTimers string, workers ...func(ctx context.Context)) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	workersCtx, cancelWorkers := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for _, worker := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker(workersCtx)
+		}()
+	}
+	stopWorkers := func() {
+		cancelWorkers()
+		wg.Wait()
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("server started", "addr", server.Addr)
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		stopWorkers()
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+	}
+	stop()
+	slog.Info("shutting down, draining in-flight requests", "timeout", shutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("graceful shutdown failed, closing connections", "error", err)
+		server.Close()
+	}
+	stopWorkers()
+
+	timersCtx, cancelTimers := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelTimers()
+	if _, err := svc.HandleOpenTimers(timersCtx, openTimers); err != nil {
+		slog.Error("failed to handle open timers on shutdown", "policy", openTimers, "error", err)
+	}
+
+	slog.Info("server stopped")
+	return nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+)
+
+// Размер страницы журнала аудита
+const (
+	DefaultAuditPageSize = 50
+	MaxAuditPageSize     = 200
+)
+
+// AuditRetention — настройки хранения журнала аудита
+type AuditRetention struct {
+	// Retention — сколько хранить события, 0 — хранить всегда
+	Retention time.Duration
+	// Interval — период удаления устаревших событий
+	Interval time.Duration
+}
+
+// Журнал аудита от новых к старым по странице или по курсору
+func (s *Service) GetAuditEvents(ctx context.Context, filter models.AuditFilter, pagination models.Pagination) (models.Page[models.AuditEvent], error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.From.After(filter.To) {
+		return models.Page[models.AuditEvent]{}, models.ErrStartDateAfterEndDate
+	}
+	if err := normalizePagination(&pagination, DefaultAuditPageSize, MaxAuditPageSize, "id", "desc"); err != nil {
+		return models.Page[models.AuditEvent]{}, err
+	}
+
+	return s.Repository.GetAuditEvents(ctx, filter, pagination)
+}
+
+// Удаляет события аудита старше cfg.Retention, возвращает число удалённых
+func (s *Service) PurgeAuditEvents(ctx context.Context, cfg AuditRetention) (int, error) {
+	if cfg.Retention <= 0 {
+		return 0, nil
+	}
+	count, err := s.Repository.DeleteAuditEvents(ctx, cfg.Retention)
+	if err != nil {
+		return 0, err
+	}
+	if count > 0 {
+		slog.InfoContext(ctx, "audit events purged", "retention", cfg.Retention, "count", count)
+	}
+	return count, nil
+}
+
+// RunAuditRetention удаляет устаревшие события аудита сразу и затем каждые cfg.Interval, пока не отменён ctx
+func (s *Service) RunAuditRetention(ctx context.Context, cfg AuditRetention) {
+	if cfg.Retention <= 0 {
+		return
+	}
+	slog.Info("audit retention worker started", "retention", cfg.Retention, "interval", cfg.Interval)
+	defer slog.Info("audit retention worker stopped")
+
+	runEvery(ctx, cfg.Interval, func() {
+		if _, err := s.PurgeAuditEvents(ctx, cfg); err != nil && ctx.Err() == nil {
+			slog.Error("failed to purge audit events", "error", err)
+		}
+	})
+}
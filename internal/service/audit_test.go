@@ -0,0 +1,157 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/audit"
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/bigxxby/effective-mobile-test/internal/repository"
+)
+
+func TestGetAuditEvents(t *testing.T) {
+	s, _, userID, taskID := newTestService(t, nil)
+	adminCtx := audit.WithActor(ctx, audit.Actor{UserID: 99, Role: models.RoleAdmin})
+
+	if err := s.UpdateUser(adminCtx, userID, models.User{Surname: "Doe", Name: "John"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.StartTask(adminCtx, userID, taskID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.EndTask(ctx, userID, taskID); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := s.GetAuditEvents(ctx, models.AuditFilter{EntityType: models.AuditEntityUser, EntityID: userID}, models.Pagination{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 2 {
+		t.Fatalf("user events = %d, want create and update", len(page.Items))
+	}
+	update := page.Items[0]
+	if update.Action != models.AuditUserUpdate || update.ActorID == nil || *update.ActorID != 99 || update.ActorRole != models.RoleAdmin {
+		t.Errorf("newest user event = %+v, want user.update by admin 99", update)
+	}
+	var before, after map[string]any
+	if err := json.Unmarshal(update.Before, &before); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(update.After, &after); err != nil {
+		t.Fatal(err)
+	}
+	if before["updated_at"] == nil || after["updated_at"] == nil {
+		t.Errorf("diff = %s -> %s, want updated_at changed", update.Before, update.After)
+	}
+	delete(before, "updated_at")
+	delete(after, "updated_at")
+	if len(before) != 1 || before["surname"] != "Smith" || len(after) != 1 || after["surname"] != "Doe" {
+		t.Errorf("diff = %s -> %s, want surname Smith -> Doe and no other changes", update.Before, update.After)
+	}
+	if page.Items[1].Action != models.AuditUserCreate || page.Items[1].Before != nil || page.Items[1].ActorID != nil {
+		t.Errorf("oldest user event = %+v, want user.create without before and actor", page.Items[1])
+	}
+
+	page, err = s.GetAuditEvents(ctx, models.AuditFilter{EntityType: models.AuditEntityTimeEntry}, models.Pagination{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 2 || page.Items[0].Action != models.AuditTimerStop || page.Items[0].ActorID != nil ||
+		page.Items[1].Action != models.AuditTimerStart {
+		t.Errorf("time entry events = %+v, want timer.stop by the service and timer.start", page.Items)
+	}
+
+	page, err = s.GetAuditEvents(ctx, models.AuditFilter{ActorID: 99}, models.Pagination{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 2 {
+		t.Errorf("events by actor 99 = %d, want 2", len(page.Items))
+	}
+}
+
+func TestGetAuditEventsCursor(t *testing.T) {
+	s, _, _, _ := newTestService(t, nil)
+	for _, name := range []string{"A", "B", "C", "D"} {
+		if _, err := s.CreateProject(ctx, models.ProjectData{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var ids []int64
+	pagination := models.Pagination{PageSize: 2}
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("pagination does not end")
+		}
+		page, err := s.GetAuditEvents(ctx, models.AuditFilter{}, pagination)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// пользователь, задача и четыре проекта
+		if page.Total != 6 {
+			t.Errorf("total = %d, want 6", page.Total)
+		}
+		for _, event := range page.Items {
+			ids = append(ids, event.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor, err := models.DecodeCursor(page.NextCursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pagination.Cursor = &cursor
+	}
+	if len(ids) != 6 {
+		t.Fatalf("events = %v, want 6", ids)
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] >= ids[i-1] {
+			t.Errorf("events are not newest first: %v", ids)
+		}
+	}
+
+	foreign := models.Pagination{Cursor: &models.Cursor{SortBy: "id", SortOrder: "asc", ID: 1}}
+	if _, err := s.GetAuditEvents(ctx, models.AuditFilter{}, foreign); !errors.Is(err, models.ErrInvalidCursor) {
+		t.Errorf("cursor for another order err = %v, want %v", err, models.ErrInvalidCursor)
+	}
+	from := time.Date(2024, 7, 2, 0, 0, 0, 0, time.UTC)
+	if _, err := s.GetAuditEvents(ctx, models.AuditFilter{From: from, To: from.Add(-time.Hour)}, models.Pagination{}); !errors.Is(err, models.ErrStartDateAfterEndDate) {
+		t.Errorf("reversed period err = %v, want %v", err, models.ErrStartDateAfterEndDate)
+	}
+}
+
+func TestPurgeAuditEvents(t *testing.T) {
+	repo := repository.NewMemory()
+	now := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	repo.Now = func() time.Time { return now }
+	s := New(repo, nil)
+	userID, err := repo.CreateUser(ctx, models.User{PassportNumber: "1234 567890"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(100 * 24 * time.Hour)
+	if err := s.UpdateUser(ctx, userID, models.User{Surname: "Doe", Name: "John"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if count, err := s.PurgeAuditEvents(ctx, AuditRetention{}); err != nil || count != 0 {
+		t.Errorf("PurgeAuditEvents without retention = %d, %v, want 0", count, err)
+	}
+	count, err := s.PurgeAuditEvents(ctx, AuditRetention{Retention: 90 * 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := s.GetAuditEvents(ctx, models.AuditFilter{}, models.Pagination{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || len(page.Items) != 1 || page.Items[0].Action != models.AuditUserUpdate {
+		t.Errorf("purged %d, kept %+v, want the create event purged and the update kept", count, page.Items)
+	}
+}
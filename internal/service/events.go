@@ -0,0 +1,30 @@
+package service
+
+import "context"
+
+// Events получает доменные события сервиса, например для метрик
+type Events interface {
+	TimerStarted()
+	TimersStopped(count int)
+	UserCreated()
+	EnrichmentFailed()
+}
+
+type nopEvents struct{}
+
+func (nopEvents) TimerStarted()     {}
+func (nopEvents) TimersStopped(int) {}
+func (nopEvents) UserCreated()      {}
+func (nopEvents) EnrichmentFailed() {}
+
+func (s *Service) events() Events {
+	if s.Events == nil {
+		return nopEvents{}
+	}
+	return s.Events
+}
+
+// Число запущенных таймеров
+func (s *Service) CountOpenTimers(ctx context.Context) (int, error) {
+	return s.Repository.CountOpenTimers(ctx)
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Что делать с запущенными таймерами при остановке сервиса
+const (
+	// OpenTimersKeep — оставить таймеры идти
+	OpenTimersKeep = "keep"
+	// OpenTimersStop — остановить таймеры временем остановки и пометить auto_closed и needs_review
+	OpenTimersStop = "stop"
+	// OpenTimersFlag — оставить таймеры идти, но пометить needs_review
+	OpenTimersFlag = "flag"
+)
+
+// Применяет политику к запущенным таймерам, возвращает число затронутых записей
+func (s *Service) HandleOpenTimers(ctx context.Context, policy string) (int, error) {
+	var count int
+	var err error
+	switch policy {
+	case OpenTimersKeep:
+		return 0, nil
+	case OpenTimersStop:
+		count, err = s.Repository.StopOpenTimers(ctx)
+		if err == nil {
+			s.events().TimersStopped(count)
+		}
+	case OpenTimersFlag:
+		count, err = s.Repository.FlagOpenTimers(ctx)
+	default:
+		return 0, fmt.Errorf("unknown open timers policy %q", policy)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	slog.InfoContext(ctx, "open timers handled", "policy", policy, "count", count)
+	return count, nil
+}
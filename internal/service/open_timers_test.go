@@ -0,0 +1,88 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+)
+
+func TestHandleOpenTimers(t *testing.T) {
+	now := time.Date(2024, 7, 1, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		policy          string
+		wantCount       int
+		wantEnded       bool
+		wantNeedsReview bool
+	}{
+		{OpenTimersKeep, 0, false, false},
+		{OpenTimersFlag, 1, false, true},
+		{OpenTimersStop, 1, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			s, repo, userID, taskID := newTestService(t, nil)
+			repo.Now = func() time.Time { return now.Add(-2 * time.Hour) }
+			if _, err := s.StartTask(ctx, userID, taskID); err != nil {
+				t.Fatal(err)
+			}
+			repo.Now = func() time.Time { return now.Add(-time.Hour) }
+			if _, err := s.PauseTask(ctx, userID, taskID); err != nil {
+				t.Fatal(err)
+			}
+			// закрытая запись не затрагивается
+			closedEnd := now.Add(-3 * time.Hour)
+			if _, err := repo.CreateTimeEntry(ctx, models.TimeEntry{UserID: userID, TaskID: taskID, StartTime: now.Add(-4 * time.Hour), EndTime: &closedEnd}); err != nil {
+				t.Fatal(err)
+			}
+			repo.Now = func() time.Time { return now }
+
+			count, err := s.HandleOpenTimers(ctx, tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != tt.wantCount {
+				t.Errorf("count = %d, want %d", count, tt.wantCount)
+			}
+
+			entries, err := repo.GetTimeEntries(ctx, userID, time.Time{}, time.Time{}, models.Pagination{Page: 1, PageSize: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range entries.Items {
+				if entry.EndTime != nil && entry.EndTime.Equal(closedEnd) {
+					if entry.NeedsReview || entry.AutoClosed {
+						t.Errorf("closed entry changed: %+v", entry)
+					}
+					continue
+				}
+				if ended := entry.EndTime != nil; ended != tt.wantEnded {
+					t.Errorf("ended = %v, want %v", ended, tt.wantEnded)
+				}
+				if tt.wantEnded && !entry.EndTime.Equal(now) {
+					t.Errorf("end time = %v, want %v", entry.EndTime, now)
+				}
+				if entry.AutoClosed != tt.wantEnded || entry.NeedsReview != tt.wantNeedsReview {
+					t.Errorf("entry = %+v", entry)
+				}
+				// перерыв остановленного таймера закрывается вместе с ним
+				pauses, err := repo.GetPauses(ctx, entry.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(pauses) != 1 {
+					t.Fatalf("pauses = %+v, want one", pauses)
+				}
+				if closed := pauses[0].EndTime != nil; closed != tt.wantEnded || closed && !pauses[0].EndTime.Equal(now) {
+					t.Errorf("pause = %+v, want closed %v at %v", pauses[0], tt.wantEnded, now)
+				}
+			}
+		})
+	}
+
+	s, _, _, _ := newTestService(t, nil)
+	if _, err := s.HandleOpenTimers(ctx, "drop"); err == nil {
+		t.Error("unknown policy: err = nil")
+	}
+}
@@ -0,0 +1,27 @@
+package service
+
+import "github.com/bigxxby/effective-mobile-test/internal/models"
+
+// Размер страницы списков
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+// normalizePagination подставляет первую страницу и размер по умолчанию, ограничивает размер maxSize
+// и проверяет, что курсор выдан для той же сортировки списка
+func normalizePagination(pagination *models.Pagination, defaultSize, maxSize int, sortBy, sortOrder string) error {
+	if pagination.Page <= 0 {
+		pagination.Page = 1
+	}
+	if pagination.PageSize <= 0 {
+		pagination.PageSize = defaultSize
+	}
+	if pagination.PageSize > maxSize {
+		pagination.PageSize = maxSize
+	}
+	if cursor := pagination.Cursor; cursor != nil && (cursor.SortBy != sortBy || cursor.SortOrder != sortOrder) {
+		return models.ErrInvalidCursor.WithMessage("cursor was issued for another sort order")
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/bigxxby/effective-mobile-test/internal/repository"
+)
+
+// walkPages проходит список по курсорам next_cursor (или prev_cursor, если backward) начиная со страницы first
+func walkPages[T any](t *testing.T, first models.Pagination, backward bool, get func(models.Pagination) (models.Page[T], error), id func(T) int) []int {
+	t.Helper()
+	var ids []int
+	pagination := first
+	for pages := 0; ; pages++ {
+		if pages > 20 {
+			t.Fatal("pagination does not end")
+		}
+		page, err := get(pagination)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var pageIDs []int
+		for _, item := range page.Items {
+			pageIDs = append(pageIDs, id(item))
+		}
+		next := page.NextCursor
+		if backward {
+			ids = append(pageIDs, ids...)
+			next = page.PrevCursor
+		} else {
+			ids = append(ids, pageIDs...)
+		}
+		if next == "" {
+			return ids
+		}
+		cursor, err := models.DecodeCursor(next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pagination = models.Pagination{PageSize: first.PageSize, Cursor: &cursor}
+	}
+}
+
+func TestGetUsersKeyset(t *testing.T) {
+	repo := repository.NewMemory()
+	for i, surname := range []string{"Smith", "Doe", "Smith", "", "Adams", "Doe", "Brown"} {
+		passport := "1000 00000" + string(rune('0'+i))
+		if _, err := repo.CreateUser(ctx, models.User{PassportNumber: passport, Surname: surname}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := New(repo, nil)
+	userID := func(user models.User) int { return user.ID }
+
+	for _, order := range []string{"asc", "desc"} {
+		t.Run(order, func(t *testing.T) {
+			get := func(pagination models.Pagination) (models.Page[models.User], error) {
+				return s.GetUsers(ctx, models.Filter{}, pagination, "surname", order)
+			}
+			all, err := get(models.Pagination{PageSize: MaxPageSize})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want []int
+			for _, user := range all.Items {
+				want = append(want, user.ID)
+			}
+			if all.Total != 7 || len(want) != 7 || all.NextCursor != "" || all.PrevCursor != "" {
+				t.Fatalf("single page = %v, total %d, cursors %q %q", want, all.Total, all.NextCursor, all.PrevCursor)
+			}
+
+			forward := walkPages(t, models.Pagination{PageSize: 3}, false, get, userID)
+			if !slices.Equal(forward, want) {
+				t.Errorf("forward by cursor = %v, want %v", forward, want)
+			}
+
+			// с последней страницы по смещению назад по курсорам
+			backward := walkPages(t, models.Pagination{Page: 3, PageSize: 3}, true, get, userID)
+			if !slices.Equal(backward, want) {
+				t.Errorf("backward by cursor = %v, want %v", backward, want)
+			}
+		})
+	}
+}
+
+func TestKeysetPageIsStable(t *testing.T) {
+	s, repo, _, _ := newTestService(t, nil)
+	for _, passport := range []string{"2222 222222", "3333 333333", "4444 444444"} {
+		if _, err := s.CreateUser(ctx, models.UserData{PassportNumber: passport}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, err := s.GetUsers(ctx, models.Filter{}, models.Pagination{PageSize: 2}, "id", "desc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Total != 4 || first.NextCursor == "" || first.PrevCursor != "" {
+		t.Fatalf("first page = %+v", first)
+	}
+	// новый пользователь попадает в начало списка и не сдвигает следующую страницу
+	if _, err := repo.CreateUser(ctx, models.User{PassportNumber: "5555 555555"}); err != nil {
+		t.Fatal(err)
+	}
+	cursor, err := models.DecodeCursor(first.NextCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.GetUsers(ctx, models.Filter{}, models.Pagination{PageSize: 2, Cursor: &cursor}, "id", "desc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, user := range second.Items {
+		ids = append(ids, user.ID)
+	}
+	if !slices.Equal(ids, []int{2, 1}) || second.Total != 5 || second.NextCursor != "" || second.PrevCursor == "" {
+		t.Errorf("second page = %v, total %d, cursors %q %q, want [2 1] of 5 without next", ids, second.Total, second.NextCursor, second.PrevCursor)
+	}
+
+	if _, err := s.GetUsers(ctx, models.Filter{}, models.Pagination{Cursor: &cursor}, "surname", "desc"); !errors.Is(err, models.ErrInvalidCursor) {
+		t.Errorf("cursor for another sort err = %v, want %v", err, models.ErrInvalidCursor)
+	}
+	page, err := s.GetUsers(ctx, models.Filter{}, models.Pagination{PageSize: 1000}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Pagination.PageSize != MaxPageSize {
+		t.Errorf("page size = %d, want capped at %d", page.Pagination.PageSize, MaxPageSize)
+	}
+}
+
+func TestGetTimeEntriesKeyset(t *testing.T) {
+	s, _, userID, taskID := newTestService(t, nil)
+	start := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	for _, offset := range []time.Duration{3, 0, 9, 6, 12} {
+		data := models.TimeEntryData{TaskID: taskID, StartTime: start.Add(offset * time.Hour), EndTime: start.Add((offset + 1) * time.Hour)}
+		if _, err := s.CreateTimeEntry(ctx, userID, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	get := func(pagination models.Pagination) (models.Page[models.TimeEntry], error) {
+		return s.GetTimeEntries(ctx, userID, time.Time{}, time.Time{}, pagination)
+	}
+	all, err := get(models.Pagination{PageSize: MaxPageSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []int
+	for i, entry := range all.Items {
+		if i > 0 && !entry.StartTime.Before(all.Items[i-1].StartTime) {
+			t.Fatalf("entries are not newest first: %+v", all.Items)
+		}
+		want = append(want, entry.ID)
+	}
+	entryID := func(entry models.TimeEntry) int { return entry.ID }
+	if got := walkPages(t, models.Pagination{PageSize: 1}, false, get, entryID); !slices.Equal(got, want) {
+		t.Errorf("forward by cursor = %v, want %v", got, want)
+	}
+	if got := walkPages(t, models.Pagination{Page: len(want), PageSize: 1}, true, get, entryID); !slices.Equal(got, want) {
+		t.Errorf("backward by cursor = %v, want %v", got, want)
+	}
+}
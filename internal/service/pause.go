@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+)
+
+// Пауза таймера: сессия продолжается, а время до продолжения не входит в нагрузку
+func (s *Service) PauseTask(ctx context.Context, userID, taskID int) (models.Pause, error) {
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return models.Pause{}, err
+	}
+	return s.Repository.PauseTask(ctx, userID, taskID)
+}
+
+// Продолжение таймера после паузы
+func (s *Service) ResumeTask(ctx context.Context, userID, taskID int) (models.Pause, error) {
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return models.Pause{}, err
+	}
+	return s.Repository.ResumeTask(ctx, userID, taskID)
+}
+
+// Отрезки работы и перерывов записи времени пользователя
+func (s *Service) GetTimeEntryTimeline(ctx context.Context, userID, entryID int) (models.Timeline, error) {
+	entry, err := s.GetTimeEntry(ctx, userID, entryID)
+	if err != nil {
+		return models.Timeline{}, err
+	}
+	pauses, err := s.Repository.GetPauses(ctx, entryID)
+	if err != nil {
+		return models.Timeline{}, err
+	}
+	return buildTimeline(entry, pauses, time.Now()), nil
+}
+
+// Разбивает запись перерывами на отрезки. Перерыв без конца длится до конца записи,
+// у запущенного таймера последний отрезок идёт до now.
+func buildTimeline(entry models.TimeEntry, pauses []models.Pause, now time.Time) models.Timeline {
+	timeline := models.Timeline{TimeEntry: entry, Segments: []models.Segment{}}
+	add := func(kind string, start time.Time, end *time.Time) {
+		to := now
+		if end != nil {
+			to = *end
+		}
+		seconds := int64(to.Sub(start).Seconds())
+		if seconds < 0 {
+			seconds = 0
+		}
+		timeline.Segments = append(timeline.Segments, models.Segment{Kind: kind, StartTime: start, EndTime: end, Seconds: seconds})
+		if kind == models.SegmentPause {
+			timeline.PausedSeconds += seconds
+		} else {
+			timeline.WorkedSeconds += seconds
+		}
+	}
+
+	cursor := entry.StartTime
+	for _, pause := range pauses {
+		start := pause.StartTime
+		if start.Before(cursor) {
+			start = cursor
+		}
+		if entry.EndTime != nil && !start.Before(*entry.EndTime) {
+			break
+		}
+		end := pause.EndTime
+		if end == nil || (entry.EndTime != nil && end.After(*entry.EndTime)) {
+			end = entry.EndTime
+		}
+
+		if start.After(cursor) {
+			add(models.SegmentWork, cursor, &start)
+		}
+		add(models.SegmentPause, start, end)
+		if end == nil {
+			// идёт перерыв запущенного таймера
+			return timeline
+		}
+		if end.After(cursor) {
+			cursor = *end
+		}
+	}
+
+	switch {
+	case entry.EndTime == nil:
+		add(models.SegmentWork, cursor, nil)
+	case entry.EndTime.After(cursor):
+		add(models.SegmentWork, cursor, entry.EndTime)
+	}
+	return timeline
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+)
+
+func TestPauseResumeErrors(t *testing.T) {
+	s, _, userID, taskID := newTestService(t, nil)
+
+	if _, err := s.PauseTask(ctx, userID, taskID); !errors.Is(err, models.ErrTaskNotStarted) {
+		t.Fatalf("pause before start: err = %v, want %v", err, models.ErrTaskNotStarted)
+	}
+	if _, err := s.StartTask(ctx, userID, taskID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ResumeTask(ctx, userID, taskID); !errors.Is(err, models.ErrTaskNotPaused) {
+		t.Fatalf("resume without pause: err = %v, want %v", err, models.ErrTaskNotPaused)
+	}
+	if _, err := s.PauseTask(ctx, userID, taskID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.PauseTask(ctx, userID, taskID); !errors.Is(err, models.ErrTaskAlreadyPaused) {
+		t.Fatalf("second pause: err = %v, want %v", err, models.ErrTaskAlreadyPaused)
+	}
+	if _, err := s.PauseTask(ctx, userID, taskID+100); !errors.Is(err, models.ErrTaskNotFound) {
+		t.Fatalf("unknown task: err = %v, want %v", err, models.ErrTaskNotFound)
+	}
+}
+
+func TestPausedTimeIsNotCounted(t *testing.T) {
+	s, repo, userID, taskID := newTestService(t, nil)
+	day := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) { repo.Now = func() time.Time { return day.Add(d) } }
+
+	// 09:00-13:00 с обедом 11:00-12:00 и остановкой на паузе в 12:30: работа 09:00-11:00 и 12:00-12:30
+	at(9 * time.Hour)
+	started, err := s.StartTask(ctx, userID, taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at(11 * time.Hour)
+	if _, err := s.PauseTask(ctx, userID, taskID); err != nil {
+		t.Fatal(err)
+	}
+	at(12 * time.Hour)
+	if _, err := s.ResumeTask(ctx, userID, taskID); err != nil {
+		t.Fatal(err)
+	}
+	at(12*time.Hour + 30*time.Minute)
+	if _, err := s.PauseTask(ctx, userID, taskID); err != nil {
+		t.Fatal(err)
+	}
+	at(13 * time.Hour)
+	if err := s.EndTask(ctx, userID, taskID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		startDate time.Time
+		endDate   time.Time
+		want      int64
+	}{
+		{"whole day", day, day.AddDate(0, 0, 1), int64(2.5 * 3600)},
+		{"period inside the break", day.Add(11*time.Hour + 30*time.Minute), day.Add(13 * time.Hour), 30 * 60},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := s.GetUserWorkloadsByUserID(ctx, userID, tt.startDate, tt.endDate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.TotalSeconds != tt.want {
+				t.Errorf("total = %d, want %d", report.TotalSeconds, tt.want)
+			}
+		})
+	}
+
+	timeline, err := s.GetTimeEntryTimeline(ctx, userID, started.TimeEntry.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantKinds := []string{models.SegmentWork, models.SegmentPause, models.SegmentWork, models.SegmentPause}
+	wantSeconds := []int64{2 * 3600, 3600, 30 * 60, 30 * 60}
+	if len(timeline.Segments) != len(wantKinds) {
+		t.Fatalf("segments = %+v, want %d", timeline.Segments, len(wantKinds))
+	}
+	for i, segment := range timeline.Segments {
+		if segment.Kind != wantKinds[i] || segment.Seconds != wantSeconds[i] || segment.EndTime == nil {
+			t.Errorf("segment %d = %+v, want closed %s of %ds", i, segment, wantKinds[i], wantSeconds[i])
+		}
+	}
+	if timeline.WorkedSeconds != int64(2.5*3600) || timeline.PausedSeconds != int64(1.5*3600) {
+		t.Errorf("worked = %d, paused = %d, want %d and %d", timeline.WorkedSeconds, timeline.PausedSeconds, int64(2.5*3600), int64(1.5*3600))
+	}
+}
+
+func TestBuildTimelineRunning(t *testing.T) {
+	start := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	now := start.Add(3 * time.Hour)
+	entry := models.TimeEntry{ID: 1, StartTime: start}
+	pauseEnd := start.Add(90 * time.Minute)
+	pauses := []models.Pause{{ID: 1, TimeEntryID: 1, StartTime: start.Add(time.Hour), EndTime: &pauseEnd}}
+
+	timeline := buildTimeline(entry, pauses, now)
+	last := timeline.Segments[len(timeline.Segments)-1]
+	if len(timeline.Segments) != 3 || last.Kind != models.SegmentWork || last.EndTime != nil {
+		t.Fatalf("segments = %+v, want work, pause and a running work segment", timeline.Segments)
+	}
+	if timeline.WorkedSeconds != int64(2.5*3600) {
+		t.Errorf("worked = %d, want %d", timeline.WorkedSeconds, int64(2.5*3600))
+	}
+
+	pauses = append(pauses, models.Pause{ID: 2, TimeEntryID: 1, StartTime: start.Add(2 * time.Hour)})
+	timeline = buildTimeline(entry, pauses, now)
+	last = timeline.Segments[len(timeline.Segments)-1]
+	if last.Kind != models.SegmentPause || last.EndTime != nil || last.Seconds != 3600 {
+		t.Errorf("last segment = %+v, want a running one-hour pause", last)
+	}
+}
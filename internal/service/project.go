@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+)
+
+// Получение проектов: страница по номеру или по курсору, общее число и курсоры соседних страниц
+func (s *Service) GetProjects(ctx context.Context, pagination models.Pagination) (models.Page[models.Project], error) {
+	if err := normalizePagination(&pagination, DefaultPageSize, MaxPageSize, "id", "asc"); err != nil {
+		return models.Page[models.Project]{}, err
+	}
+
+	return s.Repository.GetProjects(ctx, pagination)
+}
+
+func (s *Service) GetProject(ctx context.Context, projectID int) (models.Project, error) {
+	project, err := s.Repository.GetProject(ctx, projectID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Project{}, models.ErrProjectNotFound
+		}
+		return models.Project{}, err
+	}
+
+	return project, nil
+}
+
+func validateProject(data models.ProjectData) (models.Project, error) {
+	project := models.Project{
+		Name:        strings.TrimSpace(data.Name),
+		Description: data.Description,
+	}
+	if project.Name == "" || len(project.Name) > 255 {
+		return models.Project{}, models.ErrInvalidProjectName
+	}
+	return project, nil
+}
+
+// Создание проекта
+func (s *Service) CreateProject(ctx context.Context, data models.ProjectData) (int, error) {
+	project, err := validateProject(data)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.Repository.CreateProject(ctx, project)
+}
+
+func (s *Service) UpdateProject(ctx context.Context, projectID int, data models.ProjectData) error {
+	project, err := validateProject(data)
+	if err != nil {
+		return err
+	}
+
+	err = s.Repository.UpdateProject(ctx, projectID, project)
+	if err == sql.ErrNoRows {
+		return models.ErrProjectNotFound
+	}
+	return err
+}
+
+// Удаление проекта. Проект с задачами удалить нельзя; репозиторий проверяет это в транзакции удаления.
+func (s *Service) DeleteProject(ctx context.Context, projectID int) error {
+	err := s.Repository.DeleteProject(ctx, projectID)
+	if err == sql.ErrNoRows {
+		return models.ErrProjectNotFound
+	}
+	return err
+}
+
+// Получение участников проекта
+func (s *Service) GetProjectMembers(ctx context.Context, projectID int) ([]models.User, error) {
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	return s.Repository.GetProjectMembers(ctx, projectID)
+}
+
+// Добавление участника проекта
+func (s *Service) AddProjectMember(ctx context.Context, projectID, userID int) error {
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return err
+	}
+	if _, err := s.GetUser(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.Repository.AddProjectMember(ctx, projectID, userID)
+}
+
+// Удаление участника проекта
+func (s *Service) RemoveProjectMember(ctx context.Context, projectID, userID int) error {
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return err
+	}
+
+	err := s.Repository.RemoveProjectMember(ctx, projectID, userID)
+	if err == sql.ErrNoRows {
+		return models.ErrMemberNotFound
+	}
+	return err
+}
+
+// Отчёт по времени проекта: суммы по задачам, по пользователям и общий итог.
+// Нулевые даты означают отчёт за всё время. Ненулевой managerID сужает отчёт до времени
+// команды этого руководителя.
+func (s *Service) GetProjectWorkloads(ctx context.Context, projectID, managerID int, startDate, endDate time.Time) (models.ProjectWorkloadReport, error) {
+	if endDate.IsZero() {
+		endDate = time.Now().UTC()
+	}
+	if startDate.After(endDate) {
+		return models.ProjectWorkloadReport{}, models.ErrStartDateAfterEndDate
+	}
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return models.ProjectWorkloadReport{}, err
+	}
+
+	entries, err := s.Repository.GetProjectWorkloads(ctx, projectID, managerID, startDate, endDate)
+	if err != nil {
+		return models.ProjectWorkloadReport{}, err
+	}
+
+	report := models.ProjectWorkloadReport{
+		ProjectID: projectID,
+		Tasks:     []models.ProjectTaskWorkload{},
+		Users:     []models.ProjectUserWorkload{},
+	}
+	taskIndex := make(map[int]int)
+	userIndex := make(map[int]int)
+	for _, entry := range entries {
+		seconds := int64(entry.TotalSeconds)
+
+		i, ok := taskIndex[entry.TaskID]
+		if !ok {
+			i = len(report.Tasks)
+			taskIndex[entry.TaskID] = i
+			report.Tasks = append(report.Tasks, models.ProjectTaskWorkload{TaskID: entry.TaskID, TaskName: entry.TaskName})
+		}
+		report.Tasks[i].TotalSeconds += seconds
+
+		j, ok := userIndex[entry.UserID]
+		if !ok {
+			j = len(report.Users)
+			userIndex[entry.UserID] = j
+			report.Users = append(report.Users, models.ProjectUserWorkload{UserID: entry.UserID})
+		}
+		report.Users[j].TotalSeconds += seconds
+
+		report.TotalSeconds += seconds
+	}
+
+	for i := range report.Tasks {
+		report.Tasks[i].TotalHours, report.Tasks[i].TotalMinutes = splitSeconds(report.Tasks[i].TotalSeconds)
+	}
+	for i := range report.Users {
+		report.Users[i].TotalHours, report.Users[i].TotalMinutes = splitSeconds(report.Users[i].TotalSeconds)
+	}
+	report.TotalHours, report.TotalMinutes = splitSeconds(report.TotalSeconds)
+
+	sort.SliceStable(report.Tasks, func(i, j int) bool { return report.Tasks[i].TotalSeconds > report.Tasks[j].TotalSeconds })
+	sort.SliceStable(report.Users, func(i, j int) bool { return report.Users[i].TotalSeconds > report.Users[j].TotalSeconds })
+	return report, nil
+}
+
+// Переводит секунды в целые часы и оставшиеся минуты
+func splitSeconds(seconds int64) (int, int) {
+	hours := seconds / 3600
+	minutes := (seconds - hours*3600) / 60
+	return int(hours), int(minutes)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+)
+
+func TestStartProjectTaskRequiresMembership(t *testing.T) {
+	s, _, userID, _ := newTestService(t, nil)
+	projectID, err := s.CreateProject(ctx, models.ProjectData{Name: "Client A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	taskID, err := s.CreateTask(ctx, models.TaskData{Name: "Design", ProjectID: &projectID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.StartTask(ctx, userID, taskID); !errors.Is(err, models.ErrUserNotProjectMember) {
+		t.Fatalf("StartTask err = %v, want %v", err, models.ErrUserNotProjectMember)
+	}
+	if err := s.AddProjectMember(ctx, projectID, userID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddProjectMember(ctx, projectID, userID); !errors.Is(err, models.ErrMemberAlreadyExists) {
+		t.Errorf("AddProjectMember err = %v, want %v", err, models.ErrMemberAlreadyExists)
+	}
+	if _, err := s.StartTask(ctx, userID, taskID); err != nil {
+		t.Fatalf("StartTask err = %v", err)
+	}
+	if err := s.DeleteProject(ctx, projectID); !errors.Is(err, models.ErrProjectHasTasks) {
+		t.Errorf("DeleteProject err = %v, want %v", err, models.ErrProjectHasTasks)
+	}
+}
+
+func TestCreateTaskUnknownProject(t *testing.T) {
+	s, _, _, _ := newTestService(t, nil)
+	projectID := 42
+	if _, err := s.CreateTask(ctx, models.TaskData{Name: "Design", ProjectID: &projectID}); !errors.Is(err, models.ErrProjectNotFound) {
+		t.Errorf("CreateTask err = %v, want %v", err, models.ErrProjectNotFound)
+	}
+}
+
+func TestGetProjectWorkloads(t *testing.T) {
+	s, repo, firstUserID, _ := newTestService(t, nil)
+	secondUserID, err := s.CreateUser(ctx, models.UserData{PassportNumber: "2222 222222"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectID, _ := s.CreateProject(ctx, models.ProjectData{Name: "Client A"})
+	designID, _ := s.CreateTask(ctx, models.TaskData{Name: "Design", ProjectID: &projectID})
+	buildID, _ := s.CreateTask(ctx, models.TaskData{Name: "Build", ProjectID: &projectID})
+	s.AddProjectMember(ctx, projectID, firstUserID)
+	s.AddProjectMember(ctx, projectID, secondUserID)
+
+	day := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	track := func(userID, taskID int, from, to time.Duration) {
+		repo.Now = func() time.Time { return day.Add(from) }
+		if _, err := s.StartTask(ctx, userID, taskID); err != nil {
+			t.Fatal(err)
+		}
+		repo.Now = func() time.Time { return day.Add(to) }
+		if err := s.EndTask(ctx, userID, taskID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	track(firstUserID, designID, 0, time.Hour)
+	track(secondUserID, designID, 0, 30*time.Minute)
+	track(secondUserID, buildID, time.Hour, 4*time.Hour)
+
+	report, err := s.GetProjectWorkloads(ctx, projectID, 0, day.Add(-time.Hour), day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.TotalSeconds != int64((4*time.Hour+30*time.Minute).Seconds()) || report.TotalHours != 4 || report.TotalMinutes != 30 {
+		t.Errorf("total = %d (%dh %dm)", report.TotalSeconds, report.TotalHours, report.TotalMinutes)
+	}
+	if len(report.Tasks) != 2 || report.Tasks[0].TaskID != buildID || report.Tasks[1].TotalHours != 1 || report.Tasks[1].TotalMinutes != 30 {
+		t.Errorf("tasks = %+v", report.Tasks)
+	}
+	if len(report.Users) != 2 || report.Users[0].UserID != secondUserID || report.Users[0].TotalHours != 3 || report.Users[0].TotalMinutes != 30 {
+		t.Errorf("users = %+v", report.Users)
+	}
+
+	// руководитель видит только время своей команды
+	if err := repo.UpdateUserRole(ctx, secondUserID, models.RoleEmployee, &firstUserID); err != nil {
+		t.Fatal(err)
+	}
+	team, err := s.GetProjectWorkloads(ctx, projectID, firstUserID, day.Add(-time.Hour), day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(team.Users) != 1 || team.Users[0].UserID != secondUserID || team.TotalHours != 3 || team.TotalMinutes != 30 {
+		t.Errorf("team report = %+v", team)
+	}
+
+	if _, err := s.GetProjectWorkloads(ctx, projectID+1, 0, time.Time{}, time.Time{}); !errors.Is(err, models.ErrProjectNotFound) {
+		t.Errorf("err = %v, want %v", err, models.ErrProjectNotFound)
+	}
+}
+
+// Список проектов отдаётся страницей с общим числом и курсором следующей страницы
+func TestGetProjectsPage(t *testing.T) {
+	s, _, _, _ := newTestService(t, nil)
+	for _, name := range []string{"Client A", "Client B", "Client C"} {
+		if _, err := s.CreateProject(ctx, models.ProjectData{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page, err := s.GetProjects(ctx, models.Pagination{Page: 1, PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 2 || page.Items[0].Name != "Client A" || page.Total != 3 || page.NextCursor == "" || page.PrevCursor != "" {
+		t.Fatalf("first page = %+v", page)
+	}
+
+	cursor, err := models.DecodeCursor(page.NextCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err = s.GetProjects(ctx, models.Pagination{PageSize: 2, Cursor: &cursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 1 || page.Items[0].Name != "Client C" || page.Total != 3 || page.NextCursor != "" {
+		t.Errorf("second page = %+v", page)
+	}
+}
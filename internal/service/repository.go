@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+)
+
+// Repository описывает хранилище, с которым работает Service.
+// Реализации: repository.Repository (PostgreSQL) и repository.Memory (для тестов).
+type Repository interface {
+	GetUsers(ctx context.Context, filter models.Filter, pagination models.Pagination, sortBy, sortOrder string) (models.Page[models.User], error)
+	GetUser(ctx context.Context, userID int) (models.User, error)
+	UserExistsByPassportNumber(ctx context.Context, passportNumber string) (bool, error)
+	CreateUser(ctx context.Context, user models.User) (int, error)
+	UpdateUser(ctx context.Context, userID int, user models.User) error
+	UpdateUserRole(ctx context.Context, userID int, role string, managerID *int) error
+	DeleteUser(ctx context.Context, userID int) error
+	RestoreUser(ctx context.Context, userID int) error
+	PurgeUser(ctx context.Context, userID int) error
+
+	GetUserWorkloadsByUserID(ctx context.Context, userID int, startDate, endDate time.Time) ([]models.UserWorkload, error)
+
+	GetTasks(ctx context.Context, filter models.TaskFilter, pagination models.Pagination, sortBy, sortOrder string) (models.Page[models.Task], error)
+	GetTask(ctx context.Context, taskID int) (models.Task, error)
+	CreateTask(ctx context.Context, task models.Task) (int, error)
+	UpdateTask(ctx context.Context, taskID int, task models.Task) error
+	DeleteTask(ctx context.Context, taskID int) error
+	StartTask(ctx context.Context, userID, taskID int, policy string) (models.TimerStart, error)
+	EndTask(ctx context.Context, userID, taskID int) error
+	PauseTask(ctx context.Context, userID, taskID int) (models.Pause, error)
+	ResumeTask(ctx context.Context, userID, taskID int) (models.Pause, error)
+	GetPauses(ctx context.Context, entryID int) ([]models.Pause, error)
+
+	GetTimeEntries(ctx context.Context, userID int, startDate, endDate time.Time, pagination models.Pagination) (models.Page[models.TimeEntry], error)
+	GetTimeEntry(ctx context.Context, entryID int) (models.TimeEntry, error)
+	CreateTimeEntry(ctx context.Context, entry models.TimeEntry) (int, error)
+	UpdateTimeEntry(ctx context.Context, entryID int, entry models.TimeEntry) error
+	DeleteTimeEntry(ctx context.Context, entryID int) error
+	StopOpenTimers(ctx context.Context) (int, error)
+	FlagOpenTimers(ctx context.Context) (int, error)
+	CountOpenTimers(ctx context.Context) (int, error)
+	CapStaleTimers(ctx context.Context, olderThan, limit time.Duration) (int, error)
+	CloseStaleTimersAtDayEnd(ctx context.Context, olderThan, dayEnd time.Duration) (int, error)
+	FlagStaleTimers(ctx context.Context, olderThan time.Duration) (int, error)
+	GetActiveTimers(ctx context.Context, filter models.ActiveTimerFilter) ([]models.ActiveTimer, error)
+
+	GetProjects(ctx context.Context, pagination models.Pagination) (models.Page[models.Project], error)
+	GetProject(ctx context.Context, projectID int) (models.Project, error)
+	CreateProject(ctx context.Context, project models.Project) (int, error)
+	UpdateProject(ctx context.Context, projectID int, project models.Project) error
+	DeleteProject(ctx context.Context, projectID int) error
+	GetProjectMembers(ctx context.Context, projectID int) ([]models.User, error)
+	AddProjectMember(ctx context.Context, projectID, userID int) error
+	RemoveProjectMember(ctx context.Context, projectID, userID int) error
+	IsProjectMember(ctx context.Context, projectID, userID int) (bool, error)
+	GetProjectWorkloads(ctx context.Context, projectID, managerID int, startDate, endDate time.Time) ([]models.ProjectWorkloadEntry, error)
+
+	GetAuditEvents(ctx context.Context, filter models.AuditFilter, pagination models.Pagination) (models.Page[models.AuditEvent], error)
+	DeleteAuditEvents(ctx context.Context, olderThan time.Duration) (int, error)
+}
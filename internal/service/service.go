@@ -1,25 +1,48 @@
 package service
 
 import (
-	"log"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/bigxxby/effective-mobile-test/internal/models"
-	"github.com/bigxxby/effective-mobile-test/internal/repository"
+	"github.com/bigxxby/effective-mobile-test/pkg/enrichment"
 )
 
 type Service struct {
-	Repository *repository.Repository
+	Repository Repository
+	// Enricher дополняет нового пользователя данными из внешнего API, nil — без обогащения
+	Enricher enrichment.Enricher
+	// Events получает доменные события, nil — события не отправляются
+	Events Events
+	// TimerPolicy — models.TimerPolicy*, что делать при запуске таймера, пока идёт другой; пустая — parallel
+	TimerPolicy string
 }
 
-func New(repository repository.Repository) Service {
+func New(repository Repository, enricher enrichment.Enricher) Service {
 	return Service{
-		Repository: &repository,
+		Repository: repository,
+		Enricher:   enricher,
 	}
 }
 
-// Получение всех пользователей
-func (s *Service) GetUsers(filter models.Filter, pagination models.Pagination, sortBy, sortOrder string) ([]models.User, error) {
+var passportNumberRegexp = regexp.MustCompile(`^\d{4} \d{6}$`)
+
+// Разбивает номер паспорта вида "1234 567890" на серию и номер
+func splitPassportNumber(passportNumber string) (string, string, error) {
+	if !passportNumberRegexp.MatchString(passportNumber) {
+		return "", "", models.ErrInvalidPassportNumber
+	}
+	serie, number, _ := strings.Cut(passportNumber, " ")
+	return serie, number, nil
+}
+
+// Получение пользователей: страница по номеру или по курсору, общее число и курсоры соседних страниц
+func (s *Service) GetUsers(ctx context.Context, filter models.Filter, pagination models.Pagination, sortBy, sortOrder string) (models.Page[models.User], error) {
 	validSortColumns := map[string]bool{
 		"id":              true,
 		"passport_number": true,
@@ -35,122 +58,384 @@ func (s *Service) GetUsers(filter models.Filter, pagination models.Pagination, s
 		sortOrder = "asc"
 	}
 
-	if pagination.Page <= 0 {
-		pagination.Page = 1
+	if err := normalizePagination(&pagination, DefaultPageSize, MaxPageSize, sortBy, sortOrder); err != nil {
+		return models.Page[models.User]{}, err
 	}
 
-	if pagination.PageSize <= 0 {
-		pagination.PageSize = 10
-	}
-
-	users, err := s.Repository.GetUsers(filter, pagination, sortBy, sortOrder)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	return users, nil
+	return s.Repository.GetUsers(ctx, filter, pagination, sortBy, sortOrder)
 }
 
-// Получение рабочей нагрузки пользователей по их ID
-func (s *Service) GetUserWorkloadsByUserID(userID int, startDate, endDate time.Time) ([]models.UserWorkload, error) {
+// Получение трудозатрат пользователя за период [startDate, endDate):
+// суммы по задачам от большей к меньшей и общий итог
+func (s *Service) GetUserWorkloadsByUserID(ctx context.Context, userID int, startDate, endDate time.Time) (models.UserWorkloadReport, error) {
 	if startDate.After(endDate) {
-		return nil, models.ErrStartDateAfterEndDate
+		return models.UserWorkloadReport{}, models.ErrStartDateAfterEndDate
 	}
 	if time.Since(startDate) < 0 {
-		return nil, models.ErrStartDateInFuture
+		return models.UserWorkloadReport{}, models.ErrStartDateInFuture
+	}
+	if err := s.checkUserHistory(ctx, userID); err != nil {
+		return models.UserWorkloadReport{}, err
 	}
 
-	userWorkloads, err := s.Repository.GetUserWorkloadsByUserID(userID, startDate, endDate)
+	userWorkloads, err := s.Repository.GetUserWorkloadsByUserID(ctx, userID, startDate, endDate)
 	if err != nil {
-		return nil, err
+		return models.UserWorkloadReport{}, err
+	}
+
+	report := models.UserWorkloadReport{
+		UserID:        userID,
+		StartDate:     startDate,
+		EndDate:       endDate,
+		UserWorkloads: []models.UserWorkload{},
+	}
+	for _, userWorkload := range userWorkloads {
+		userWorkload.TotalHours, userWorkload.TotalMinutes = splitSeconds(userWorkload.TotalSeconds)
+		report.UserWorkloads = append(report.UserWorkloads, userWorkload)
+		report.TotalSeconds += userWorkload.TotalSeconds
 	}
+	report.TotalHours, report.TotalMinutes = splitSeconds(report.TotalSeconds)
 
-	return userWorkloads, nil
+	return report, nil
 }
 
-// Запуск задачи
-func (s *Service) StartTask(userID, taskID int) error {
+// Запуск задачи. Проверка, что таймер ещё не запущен, и политика TimerPolicy применяются
+// в репозитории атомарно со вставкой, иначе два одновременных запроса запустили бы два таймера.
+func (s *Service) StartTask(ctx context.Context, userID, taskID int) (models.TimerStart, error) {
+	if err := s.checkTaskAccess(ctx, userID, taskID); err != nil {
+		return models.TimerStart{}, err
+	}
 
-	inPorgress, _ := s.Repository.IsTaskInProgress(userID, taskID)
-	if inPorgress {
-		return models.ErrTaskAlreadyStarted
+	policy := s.TimerPolicy
+	if policy == "" {
+		policy = models.TimerPolicyParallel
 	}
-	isTaskExists, _ := s.Repository.IsTaskExists(taskID)
-	if !isTaskExists {
-		return models.ErrTaskNotFound
+	result, err := s.Repository.StartTask(ctx, userID, taskID, policy)
+	if err != nil {
+		return models.TimerStart{}, err
 	}
 
-	err := s.Repository.StartTask(userID, taskID)
+	if len(result.Stopped) > 0 {
+		s.events().TimersStopped(len(result.Stopped))
+	}
+	s.events().TimerStarted()
+	return result, nil
+}
+
+// Проверяет, что пользователь может учитывать время по задаче:
+// пользователь не удалён, задача существует, не в архиве, а пользователь участник её проекта
+func (s *Service) checkTaskAccess(ctx context.Context, userID, taskID int) error {
+	if _, err := s.GetUser(ctx, userID); err != nil {
+		return err
+	}
+	task, err := s.GetTask(ctx, taskID)
 	if err != nil {
 		return err
 	}
-
+	if task.Archived {
+		return models.ErrTaskArchived
+	}
+	if task.ProjectID != nil {
+		isMember, err := s.Repository.IsProjectMember(ctx, *task.ProjectID, userID)
+		if err != nil {
+			return err
+		}
+		if !isMember {
+			return models.ErrUserNotProjectMember
+		}
+	}
 	return nil
 }
 
 // Завершение задачи
-func (s *Service) EndTask(userID, taskID int) error {
-	inPorgress, _ := s.Repository.IsTaskInProgress(userID, taskID)
-	if !inPorgress {
-		return models.ErrTaskNotStarted
-	}
-	isTaskExists, _ := s.Repository.IsTaskExists(taskID)
-	if !isTaskExists {
-		return models.ErrTaskNotFound
+func (s *Service) EndTask(ctx context.Context, userID, taskID int) error {
+	if _, err := s.GetTask(ctx, taskID); err != nil {
+		return err
 	}
-	err := s.Repository.EndTask(userID, taskID)
+	err := s.Repository.EndTask(ctx, userID, taskID)
 	if err != nil {
 		return err
 	}
 
+	s.events().TimersStopped(1)
 	return nil
 }
 
-func (s *Service) GetUser(userID int) (models.User, error) {
-	user, err := s.Repository.GetUser(userID)
+// Получение активного пользователя: мягко удалённый считается отсутствующим
+func (s *Service) GetUser(ctx context.Context, userID int) (models.User, error) {
+	user, err := s.Repository.GetUser(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, models.ErrUserNotFound.Wrap(err)
+	}
 	if err != nil {
 		return models.User{}, err
 	}
+	if user.DeletedAt != nil {
+		return models.User{}, models.ErrUserNotFound
+	}
 
 	return user, nil
 }
 
-// Получение всех задач
-func (s *Service) GetTasks() ([]models.Task, error) {
-	tasks, err := s.Repository.GetTasks()
+// Получение задач: страница по номеру или по курсору, общее число и курсоры соседних страниц
+func (s *Service) GetTasks(ctx context.Context, filter models.TaskFilter, pagination models.Pagination, sortBy, sortOrder string) (models.Page[models.Task], error) {
+	validSortColumns := map[string]bool{
+		"id":         true,
+		"name":       true,
+		"status":     true,
+		"created_at": true,
+	}
+
+	if !validSortColumns[sortBy] {
+		sortBy = "id"
+	}
+
+	if sortOrder != "asc" && sortOrder != "desc" {
+		sortOrder = "asc"
+	}
+
+	if err := normalizePagination(&pagination, DefaultPageSize, MaxPageSize, sortBy, sortOrder); err != nil {
+		return models.Page[models.Task]{}, err
+	}
+
+	if filter.Status != "" && !isValidTaskStatus(filter.Status) {
+		return models.Page[models.Task]{}, models.ErrInvalidTaskStatus
+	}
+
+	return s.Repository.GetTasks(ctx, filter, pagination, sortBy, sortOrder)
+}
+
+func isValidTaskStatus(status string) bool {
+	switch status {
+	case models.TaskStatusTodo, models.TaskStatusInProgress, models.TaskStatusDone:
+		return true
+	}
+	return false
+}
+
+// Проверка и нормализация задачи перед сохранением
+func (s *Service) validateTask(ctx context.Context, task *models.Task) error {
+	task.Name = strings.TrimSpace(task.Name)
+	if task.Name == "" || len(task.Name) > 255 {
+		return models.ErrInvalidTaskName
+	}
+	if task.Status == "" {
+		task.Status = models.TaskStatusTodo
+	}
+	if !isValidTaskStatus(task.Status) {
+		return models.ErrInvalidTaskStatus
+	}
+	if task.ProjectID != nil {
+		if _, err := s.GetProject(ctx, *task.ProjectID); err != nil {
+			// проект указан в теле запроса, поэтому это ошибка запроса, а не отсутствующий ресурс
+			if errors.Is(err, models.ErrProjectNotFound) {
+				return models.ErrProjectNotFound.WithStatus(400)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) GetTask(ctx context.Context, taskID int) (models.Task, error) {
+	task, err := s.Repository.GetTask(ctx, taskID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Task{}, models.ErrTaskNotFound
+		}
+		return models.Task{}, err
+	}
+
+	return task, nil
+}
+
+// Создание задачи
+func (s *Service) CreateTask(ctx context.Context, data models.TaskData) (int, error) {
+	task := models.Task{
+		Name:        data.Name,
+		Description: data.Description,
+		Status:      data.Status,
+		Archived:    data.Archived,
+		ProjectID:   data.ProjectID,
+	}
+	if err := s.validateTask(ctx, &task); err != nil {
+		return 0, err
+	}
+
+	return s.Repository.CreateTask(ctx, task)
+}
+
+// Полное обновление задачи
+func (s *Service) UpdateTask(ctx context.Context, taskID int, data models.TaskData) error {
+	task := models.Task{
+		Name:        data.Name,
+		Description: data.Description,
+		Status:      data.Status,
+		Archived:    data.Archived,
+		ProjectID:   data.ProjectID,
+	}
+	if err := s.validateTask(ctx, &task); err != nil {
+		return err
+	}
+
+	err := s.Repository.UpdateTask(ctx, taskID, task)
+	if err == sql.ErrNoRows {
+		return models.ErrTaskNotFound
+	}
+	return err
+}
+
+// Частичное обновление задачи
+func (s *Service) PatchTask(ctx context.Context, taskID int, patch models.TaskPatch) (models.Task, error) {
+	task, err := s.GetTask(ctx, taskID)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	if patch.Name != nil {
+		task.Name = *patch.Name
+	}
+	if patch.Description != nil {
+		task.Description = *patch.Description
+	}
+	if patch.Status != nil {
+		task.Status = *patch.Status
+		if task.Status == "" {
+			return models.Task{}, models.ErrInvalidTaskStatus
+		}
+	}
+	if patch.Archived != nil {
+		task.Archived = *patch.Archived
+	}
+	if patch.ProjectID != nil {
+		task.ProjectID = patch.ProjectID
+	}
+	if err := s.validateTask(ctx, &task); err != nil {
+		return models.Task{}, err
+	}
+
+	err = s.Repository.UpdateTask(ctx, taskID, task)
+	if err == sql.ErrNoRows {
+		return models.Task{}, models.ErrTaskNotFound
+	}
 	if err != nil {
-		return nil, err
+		return models.Task{}, err
 	}
+	return task, nil
+}
 
-	return tasks, nil
+// Удаление задачи. Задачи с историей времени удалить нельзя, их нужно архивировать;
+// репозиторий проверяет это в транзакции удаления.
+func (s *Service) DeleteTask(ctx context.Context, taskID int) error {
+	err := s.Repository.DeleteTask(ctx, taskID)
+	if err == sql.ErrNoRows {
+		return models.ErrTaskNotFound
+	}
+	return err
 }
-func (s *Service) CreateUser(user models.UserData) (int, error) {
-	userExists, _ := s.Repository.UserExistsByPassportNumber(user.PassportNumber)
+
+// Создание пользователя. Если задан Enricher, ФИО и адрес подтягиваются из внешнего API;
+// ошибка обогащения не мешает созданию пользователя.
+func (s *Service) CreateUser(ctx context.Context, user models.UserData) (int, error) {
+	user.PassportNumber = strings.TrimSpace(user.PassportNumber)
+	passportSerie, passportNumber, err := splitPassportNumber(user.PassportNumber)
+	if err != nil {
+		return 0, err
+	}
+
+	userExists, _ := s.Repository.UserExistsByPassportNumber(ctx, user.PassportNumber)
 	if userExists {
 		return 0, models.ErrUserAlreadyExists
 	}
 
-	userID, err := s.Repository.CreateUser(user)
+	newUser := models.User{PassportNumber: user.PassportNumber}
+	if s.Enricher != nil {
+		person, err := s.Enricher.Enrich(ctx, passportSerie, passportNumber)
+		if err != nil {
+			slog.WarnContext(ctx, "user enrichment failed", "error", err)
+			s.events().EnrichmentFailed()
+		} else {
+			newUser.Surname = person.Surname
+			newUser.Name = person.Name
+			newUser.Patronymic = person.Patronymic
+			newUser.Address = person.Address
+		}
+	}
+
+	userID, err := s.Repository.CreateUser(ctx, newUser)
 	if err != nil {
 		return 0, err
 	}
 
+	s.events().UserCreated()
 	return userID, nil
 }
 
-func (s *Service) UpdateUser(userID int, user models.User) error {
-	err := s.Repository.UpdateUser(userID, user)
+func (s *Service) UpdateUser(ctx context.Context, userID int, user models.User) error {
+	err := s.Repository.UpdateUser(ctx, userID, user)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ErrUserNotFound.Wrap(err)
+	}
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
-func (s *Service) DeleteUser(userID int) error {
-	err := s.Repository.DeleteUser(userID)
+
+// Мягкое удаление пользователя: история времени сохраняется, запущенные таймеры останавливаются
+func (s *Service) DeleteUser(ctx context.Context, userID int) error {
+	err := s.Repository.DeleteUser(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ErrUserNotFound.Wrap(err)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Восстановление мягко удалённого пользователя
+func (s *Service) RestoreUser(ctx context.Context, userID int) (models.User, error) {
+	if err := s.checkUserDeleted(ctx, userID); err != nil {
+		return models.User{}, err
+	}
+	err := s.Repository.RestoreUser(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, models.ErrUserNotDeleted.Wrap(err)
+	}
 	if err != nil {
+		return models.User{}, err
+	}
+
+	return s.Repository.GetUser(ctx, userID)
+}
+
+// Окончательное удаление пользователя вместе с его записями времени.
+// Удалить так можно только уже мягко удалённого пользователя.
+func (s *Service) PurgeUser(ctx context.Context, userID int) error {
+	if err := s.checkUserDeleted(ctx, userID); err != nil {
 		return err
 	}
+	err := s.Repository.PurgeUser(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ErrUserNotFound.Wrap(err)
+	}
+	return err
+}
 
+// Проверяет, что пользователь существует и мягко удалён
+func (s *Service) checkUserDeleted(ctx context.Context, userID int) error {
+	user, err := s.Repository.GetUser(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ErrUserNotFound.Wrap(err)
+	}
+	if err != nil {
+		return err
+	}
+	if user.DeletedAt == nil {
+		return models.ErrUserNotDeleted
+	}
 	return nil
 }
@@ -0,0 +1,669 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+	"github.com/bigxxby/effective-mobile-test/internal/repository"
+	"github.com/bigxxby/effective-mobile-test/pkg/enrichment"
+)
+
+var (
+	_ Repository = (*repository.Repository)(nil)
+	_ Repository = (*repository.Memory)(nil)
+)
+
+// ctx — контекст для вызовов сервиса и хранилища в тестах
+var ctx = context.Background()
+
+type stubEnricher struct {
+	person enrichment.Person
+	err    error
+}
+
+func (e stubEnricher) Enrich(ctx context.Context, passportSerie, passportNumber string) (enrichment.Person, error) {
+	return e.person, e.err
+}
+
+// newTestService возвращает сервис поверх Memory с одним пользователем и одной задачей
+func newTestService(t *testing.T, enricher enrichment.Enricher) (Service, *repository.Memory, int, int) {
+	t.Helper()
+	repo := repository.NewMemory()
+	userID, err := repo.CreateUser(ctx, models.User{PassportNumber: "1234 567890", Surname: "Smith", Name: "John"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	taskID, err := repo.CreateTask(ctx, models.Task{Name: "Task 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(repo, enricher), repo, userID, taskID
+}
+
+// timerRunning — идёт ли у пользователя таймер по задаче
+func timerRunning(t *testing.T, repo *repository.Memory, userID, taskID int) bool {
+	t.Helper()
+	timers, err := repo.GetActiveTimers(ctx, models.ActiveTimerFilter{UserID: userID, TaskID: taskID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(timers) > 0
+}
+
+func TestCreateUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		enricher enrichment.Enricher
+		passport string
+		wantErr  error
+		wantUser models.User
+	}{
+		{
+			name:     "without enricher",
+			passport: "4321 098765",
+			wantUser: models.User{PassportNumber: "4321 098765", Role: models.RoleEmployee},
+		},
+		{
+			name:     "enriched",
+			enricher: stubEnricher{person: enrichment.Person{Surname: "Ivanov", Name: "Ivan", Patronymic: "Ivanovich", Address: "Moscow"}},
+			passport: " 4321 098765 ",
+			wantUser: models.User{PassportNumber: "4321 098765", Surname: "Ivanov", Name: "Ivan", Patronymic: "Ivanovich", Address: "Moscow", Role: models.RoleEmployee},
+		},
+		{
+			name:     "enrichment failure still creates user",
+			enricher: stubEnricher{err: enrichment.ErrPersonNotFound},
+			passport: "4321 098765",
+			wantUser: models.User{PassportNumber: "4321 098765", Role: models.RoleEmployee},
+		},
+		{
+			name:     "duplicate passport",
+			passport: "1234 567890",
+			wantErr:  models.ErrUserAlreadyExists,
+		},
+		{
+			name:     "invalid passport",
+			passport: "1234567890",
+			wantErr:  models.ErrInvalidPassportNumber,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, _, _ := newTestService(t, tt.enricher)
+
+			userID, err := s.CreateUser(ctx, models.UserData{PassportNumber: tt.passport})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			user, err := s.GetUser(ctx, userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user.CreatedAt.IsZero() || !user.UpdatedAt.Equal(user.CreatedAt) {
+				t.Errorf("created_at = %v, updated_at = %v, want equal creation time", user.CreatedAt, user.UpdatedAt)
+			}
+			tt.wantUser.ID = userID
+			tt.wantUser.CreatedAt, tt.wantUser.UpdatedAt = user.CreatedAt, user.UpdatedAt
+			if user != tt.wantUser {
+				t.Errorf("user = %+v, want %+v", user, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestStartTask(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(s Service, userID, taskID int)
+		taskID  func(taskID int) int
+		wantErr error
+	}{
+		{
+			name: "starts task",
+		},
+		{
+			name: "already started",
+			prepare: func(s Service, userID, taskID int) {
+				s.StartTask(ctx, userID, taskID)
+			},
+			wantErr: models.ErrTaskAlreadyStarted,
+		},
+		{
+			name:    "unknown task",
+			taskID:  func(taskID int) int { return taskID + 100 },
+			wantErr: models.ErrTaskNotFound,
+		},
+		{
+			name: "archived task",
+			prepare: func(s Service, userID, taskID int) {
+				archived := true
+				s.PatchTask(ctx, taskID, models.TaskPatch{Archived: &archived})
+			},
+			wantErr: models.ErrTaskArchived,
+		},
+		{
+			name: "restart after stop",
+			prepare: func(s Service, userID, taskID int) {
+				s.StartTask(ctx, userID, taskID)
+				s.EndTask(ctx, userID, taskID)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo, userID, taskID := newTestService(t, nil)
+			if tt.prepare != nil {
+				tt.prepare(s, userID, taskID)
+			}
+			if tt.taskID != nil {
+				taskID = tt.taskID(taskID)
+			}
+
+			_, err := s.StartTask(ctx, userID, taskID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil {
+				if !timerRunning(t, repo, userID, taskID) {
+					t.Error("task is not in progress after start")
+				}
+			}
+		})
+	}
+}
+
+func TestEndTask(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(s Service, userID, taskID int)
+		wantErr error
+	}{
+		{
+			name: "ends started task",
+			prepare: func(s Service, userID, taskID int) {
+				s.StartTask(ctx, userID, taskID)
+			},
+		},
+		{
+			name:    "not started",
+			wantErr: models.ErrTaskNotStarted,
+		},
+		{
+			name: "already ended",
+			prepare: func(s Service, userID, taskID int) {
+				s.StartTask(ctx, userID, taskID)
+				s.EndTask(ctx, userID, taskID)
+			},
+			wantErr: models.ErrTaskNotStarted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo, userID, taskID := newTestService(t, nil)
+			if tt.prepare != nil {
+				tt.prepare(s, userID, taskID)
+			}
+
+			err := s.EndTask(ctx, userID, taskID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if timerRunning(t, repo, userID, taskID) {
+				t.Error("task is still in progress")
+			}
+		})
+	}
+}
+
+func TestGetUserWorkloadsByUserID(t *testing.T) {
+	s, repo, userID, firstTaskID := newTestService(t, nil)
+	secondTaskID, _ := s.CreateTask(ctx, models.TaskData{Name: "Task 2"})
+	thirdTaskID, _ := s.CreateTask(ctx, models.TaskData{Name: "Task 3"})
+
+	day := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	track := func(taskID int, from, to time.Duration) {
+		repo.Now = func() time.Time { return day.Add(from) }
+		if _, err := s.StartTask(ctx, userID, taskID); err != nil {
+			t.Fatal(err)
+		}
+		if to == 0 {
+			return
+		}
+		repo.Now = func() time.Time { return day.Add(to) }
+		if err := s.EndTask(ctx, userID, taskID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Task 1: 10:00-12:30 и 14:00-15:00, Task 2: 23:00-01:00 через полночь,
+	// Task 3 запущен в 20:00 второго дня и не остановлен
+	track(firstTaskID, 10*time.Hour, 12*time.Hour+30*time.Minute)
+	track(firstTaskID, 14*time.Hour, 15*time.Hour)
+	track(secondTaskID, 23*time.Hour, 25*time.Hour)
+	track(thirdTaskID, 44*time.Hour, 0)
+	repo.Now = func() time.Time { return day.Add(47 * time.Hour) }
+
+	hours := func(h float64) int64 { return int64(h * 3600) }
+	tests := []struct {
+		name      string
+		startDate time.Time
+		endDate   time.Time
+		want      []models.UserWorkload
+		wantTotal int64
+		wantErr   error
+	}{
+		{
+			name:      "summed per task, most time first",
+			startDate: day,
+			endDate:   day.AddDate(0, 0, 1),
+			want: []models.UserWorkload{
+				{TaskID: firstTaskID, TaskName: "Task 1", TotalSeconds: hours(3.5), TotalHours: 3, TotalMinutes: 30},
+				{TaskID: secondTaskID, TaskName: "Task 2", TotalSeconds: hours(1), TotalHours: 1},
+			},
+			wantTotal: hours(4.5),
+		},
+		{
+			name:      "partial overlap and running timer are clipped",
+			startDate: day.AddDate(0, 0, 1),
+			endDate:   day.AddDate(0, 0, 2),
+			want: []models.UserWorkload{
+				{TaskID: thirdTaskID, TaskName: "Task 3", TotalSeconds: hours(3), TotalHours: 3},
+				{TaskID: secondTaskID, TaskName: "Task 2", TotalSeconds: hours(1), TotalHours: 1},
+			},
+			wantTotal: hours(4),
+		},
+		{
+			name:      "nothing in range",
+			startDate: day.AddDate(0, 0, -2),
+			endDate:   day.AddDate(0, 0, -1),
+			want:      []models.UserWorkload{},
+		},
+		{
+			name:      "start after end",
+			startDate: day.AddDate(0, 0, 2),
+			endDate:   day.AddDate(0, 0, 1),
+			wantErr:   models.ErrStartDateAfterEndDate,
+		},
+		{
+			name:      "start in future",
+			startDate: time.Now().Add(48 * time.Hour),
+			endDate:   time.Now().Add(72 * time.Hour),
+			wantErr:   models.ErrStartDateInFuture,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.GetUserWorkloadsByUserID(ctx, userID, tt.startDate, tt.endDate)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if len(got.UserWorkloads) != len(tt.want) {
+				t.Fatalf("workloads = %+v, want %+v", got.UserWorkloads, tt.want)
+			}
+			for i := range tt.want {
+				if got.UserWorkloads[i] != tt.want[i] {
+					t.Errorf("workload[%d] = %+v, want %+v", i, got.UserWorkloads[i], tt.want[i])
+				}
+			}
+			if got.TotalSeconds != tt.wantTotal {
+				t.Errorf("total = %d, want %d", got.TotalSeconds, tt.wantTotal)
+			}
+		})
+	}
+
+	if _, err := s.GetUserWorkloadsByUserID(ctx, userID+100, day, day.AddDate(0, 0, 1)); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("unknown user err = %v, want %v", err, models.ErrUserNotFound)
+	}
+}
+
+// PUT без отчества и адреса не стирает данные, заполненные обогащением
+func TestUpdateUserKeepsEnrichedFields(t *testing.T) {
+	s, repo, _, _ := newTestService(t, nil)
+	userID, err := repo.CreateUser(ctx, models.User{PassportNumber: "4321 098765", Surname: "Ivanov", Name: "Ivan", Patronymic: "Ivanovich", Address: "Moscow"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.UpdateUser(ctx, userID, models.User{Surname: "Petrov", Name: "Petr"}); err != nil {
+		t.Fatal(err)
+	}
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Surname != "Petrov" || user.Name != "Petr" || user.Patronymic != "Ivanovich" || user.Address != "Moscow" {
+		t.Errorf("user = %+v, want new surname and name with enriched patronymic and address kept", user)
+	}
+
+	if err := s.UpdateUser(ctx, userID, models.User{Surname: "Petrov", Name: "Petr", Address: "Kazan"}); err != nil {
+		t.Fatal(err)
+	}
+	if user, _ := s.GetUser(ctx, userID); user.Address != "Kazan" || user.Patronymic != "Ivanovich" {
+		t.Errorf("user = %+v, want address replaced and patronymic kept", user)
+	}
+}
+
+func TestDeleteUserKeepsHistory(t *testing.T) {
+	s, repo, userID, taskID := newTestService(t, nil)
+	if _, err := s.StartTask(ctx, userID, taskID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.DeleteUser(ctx, userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetUser(ctx, userID); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("GetUser err = %v, want %v", err, models.ErrUserNotFound)
+	}
+	if timerRunning(t, repo, userID, taskID) {
+		t.Error("timer is still running after user deletion")
+	}
+	page, err := s.GetTimeEntries(ctx, userID, time.Time{}, time.Time{}, models.Pagination{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries := page.Items; len(entries) != 1 || !entries[0].AutoClosed {
+		t.Errorf("time entries after deletion = %+v, want one auto-closed entry", entries)
+	}
+	if _, err := s.StartTask(ctx, userID, taskID); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("StartTask for deleted user err = %v, want %v", err, models.ErrUserNotFound)
+	}
+
+	// записи удалённого пользователя можно читать, но не изменять
+	entryID := page.Items[0].ID
+	day := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	data := models.TimeEntryData{TaskID: taskID, StartTime: day, EndTime: day.Add(time.Hour)}
+	if _, err := s.CreateTimeEntry(ctx, userID, data); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("CreateTimeEntry for deleted user err = %v, want %v", err, models.ErrUserNotFound)
+	}
+	if err := s.UpdateTimeEntry(ctx, userID, entryID, data); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("UpdateTimeEntry for deleted user err = %v, want %v", err, models.ErrUserNotFound)
+	}
+	if err := s.DeleteTimeEntry(ctx, userID, entryID); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("DeleteTimeEntry for deleted user err = %v, want %v", err, models.ErrUserNotFound)
+	}
+	if _, err := s.UpdateUserRole(ctx, userID, models.UserRoleData{Role: models.RoleManager}); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("UpdateUserRole of deleted user err = %v, want %v", err, models.ErrUserNotFound)
+	}
+	if _, err := s.GetUserWorkloadsByUserID(ctx, userID, day, time.Now()); err != nil {
+		t.Errorf("GetUserWorkloadsByUserID for deleted user err = %v", err)
+	}
+	if _, err := s.GetTimeEntry(ctx, userID, entryID); err != nil {
+		t.Errorf("GetTimeEntry for deleted user err = %v", err)
+	}
+
+	if err := s.DeleteUser(ctx, userID); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("second DeleteUser err = %v, want %v", err, models.ErrUserNotFound)
+	}
+	if err := s.UpdateUser(ctx, userID, models.User{Surname: "Doe"}); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("UpdateUser of deleted user err = %v, want %v", err, models.ErrUserNotFound)
+	}
+	if err := s.DeleteUser(ctx, userID+100); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("DeleteUser of unknown user err = %v, want %v", err, models.ErrUserNotFound)
+	}
+	if err := s.UpdateUser(ctx, userID+100, models.User{Surname: "Doe"}); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("UpdateUser of unknown user err = %v, want %v", err, models.ErrUserNotFound)
+	}
+}
+
+func TestRestoreAndPurgeUser(t *testing.T) {
+	s, repo, userID, taskID := newTestService(t, nil)
+	if _, err := s.StartTask(ctx, userID, taskID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.RestoreUser(ctx, userID); !errors.Is(err, models.ErrUserNotDeleted) {
+		t.Errorf("RestoreUser of active user err = %v, want %v", err, models.ErrUserNotDeleted)
+	}
+	if err := s.PurgeUser(ctx, userID); !errors.Is(err, models.ErrUserNotDeleted) {
+		t.Errorf("PurgeUser of active user err = %v, want %v", err, models.ErrUserNotDeleted)
+	}
+	if _, err := s.RestoreUser(ctx, userID+100); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("RestoreUser of unknown user err = %v, want %v", err, models.ErrUserNotFound)
+	}
+
+	if err := s.DeleteUser(ctx, userID); err != nil {
+		t.Fatal(err)
+	}
+	users, err := s.GetUsers(ctx, models.Filter{}, models.Pagination{}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users.Items) != 0 {
+		t.Errorf("GetUsers returned %d users, want deleted user hidden", len(users.Items))
+	}
+	users, err = s.GetUsers(ctx, models.Filter{IncludeDeleted: true}, models.Pagination{}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users.Items) != 1 || users.Items[0].DeletedAt == nil {
+		t.Errorf("GetUsers with include_deleted = %+v, want the deleted user", users.Items)
+	}
+
+	user, err := s.RestoreUser(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.DeletedAt != nil {
+		t.Errorf("deleted_at after restore = %v, want nil", user.DeletedAt)
+	}
+	if _, err := s.GetUser(ctx, userID); err != nil {
+		t.Errorf("GetUser after restore err = %v", err)
+	}
+
+	if err := s.DeleteUser(ctx, userID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PurgeUser(ctx, userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetUser(ctx, userID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("purged user err = %v, want sql.ErrNoRows", err)
+	}
+	if entries, _ := repo.GetTimeEntries(ctx, userID, time.Time{}, time.Time{}, models.Pagination{Page: 1, PageSize: 10}); entries.Total != 0 {
+		t.Errorf("time entries after purge = %d, want 0", entries.Total)
+	}
+	if err := s.PurgeUser(ctx, userID); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("second PurgeUser err = %v, want %v", err, models.ErrUserNotFound)
+	}
+}
+
+func TestGetUsers(t *testing.T) {
+	s, _, _, _ := newTestService(t, nil)
+	for _, passport := range []string{"2222 222222", "3333 333333", "4444 444444"} {
+		if _, err := s.CreateUser(ctx, models.UserData{PassportNumber: passport}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		filter     models.Filter
+		pagination models.Pagination
+		sortBy     string
+		sortOrder  string
+		wantIDs    []int
+	}{
+		{name: "defaults", wantIDs: []int{1, 2, 3, 4}},
+		{name: "desc", sortOrder: "desc", wantIDs: []int{4, 3, 2, 1}},
+		{name: "second page", pagination: models.Pagination{Page: 2, PageSize: 3}, wantIDs: []int{4}},
+		{name: "filter by passport", filter: models.Filter{PassportNumber: models.TextMatch{Value: "3333 333333"}}, wantIDs: []int{3}},
+		{name: "unknown sort column falls back to id", sortBy: "password", sortOrder: "desc", wantIDs: []int{4, 3, 2, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := s.GetUsers(ctx, tt.filter, tt.pagination, tt.sortBy, tt.sortOrder)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []int
+			for _, user := range page.Items {
+				ids = append(ids, user.ID)
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Fatalf("ids = %v, want %v", ids, tt.wantIDs)
+				}
+			}
+		})
+	}
+}
+
+func TestGetUsersFilter(t *testing.T) {
+	repo := repository.NewMemory()
+	start := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	now := start
+	repo.Now = func() time.Time { return now }
+	for _, user := range []models.User{
+		{PassportNumber: "1111 111111", Surname: "Smith", Name: "John", Address: "Baker Street 221b"},
+		{PassportNumber: "1111 222222", Surname: "Smithson", Name: "Anna", Patronymic: "Maria"},
+		{PassportNumber: "2222 111111", Surname: "Blacksmith", Name: "Ivan", Address: "Moscow, Arbat"},
+		{PassportNumber: "3333 333333", Surname: "smith_", Name: "Jo%n"},
+	} {
+		if _, err := repo.CreateUser(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(24 * time.Hour)
+	}
+	s := New(repo, nil)
+	if err := s.UpdateUser(ctx, 1, models.User{Surname: "Smith", Name: "Johnny"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		filter  models.Filter
+		wantIDs []int
+	}{
+		{name: "exact", filter: models.Filter{Surname: models.TextMatch{Value: "Smith"}}, wantIDs: []int{1}},
+		{name: "case-insensitive exact", filter: models.Filter{Surname: models.TextMatch{Mode: models.MatchIExact, Value: "SMITH"}}, wantIDs: []int{1}},
+		{name: "prefix", filter: models.Filter{Surname: models.TextMatch{Mode: models.MatchPrefix, Value: "Smi"}}, wantIDs: []int{1, 2}},
+		{name: "case-insensitive prefix", filter: models.Filter{Surname: models.TextMatch{Mode: models.MatchIPrefix, Value: "smi"}}, wantIDs: []int{1, 2, 4}},
+		{name: "contains", filter: models.Filter{Surname: models.TextMatch{Mode: models.MatchContains, Value: "smith"}}, wantIDs: []int{3, 4}},
+		{name: "case-insensitive contains", filter: models.Filter{Surname: models.TextMatch{Mode: models.MatchIContains, Value: "SMITH"}}, wantIDs: []int{1, 2, 3, 4}},
+		{name: "wildcards are literal", filter: models.Filter{Name: models.TextMatch{Mode: models.MatchContains, Value: "%"}}, wantIDs: []int{4}},
+		{name: "passport prefix", filter: models.Filter{PassportNumber: models.TextMatch{Mode: models.MatchPrefix, Value: "1111 "}}, wantIDs: []int{1, 2}},
+		{name: "full-text", filter: models.Filter{Search: "arbat IVAN"}, wantIDs: []int{3}},
+		{name: "full-text matches whole words", filter: models.Filter{Search: "Smit"}},
+		{name: "full-text by patronymic", filter: models.Filter{Search: "maria"}, wantIDs: []int{2}},
+		{name: "id range", filter: models.Filter{ID: models.IDRange{Min: 2, Max: 3}}, wantIDs: []int{2, 3}},
+		{name: "id list", filter: models.Filter{ID: models.IDRange{In: []int{1, 4, 9}}}, wantIDs: []int{1, 4}},
+		{name: "id range and list", filter: models.Filter{ID: models.IDRange{Min: 2, In: []int{1, 4}}}, wantIDs: []int{4}},
+		{
+			name:    "created period",
+			filter:  models.Filter{CreatedAt: models.TimeRange{From: start.Add(24 * time.Hour), To: start.Add(72 * time.Hour)}},
+			wantIDs: []int{2, 3},
+		},
+		{
+			name:    "updated since",
+			filter:  models.Filter{UpdatedAt: models.TimeRange{From: start.Add(96 * time.Hour)}},
+			wantIDs: []int{1},
+		},
+		{
+			name:    "conditions combine",
+			filter:  models.Filter{Surname: models.TextMatch{Mode: models.MatchIPrefix, Value: "smith"}, ID: models.IDRange{Max: 2}},
+			wantIDs: []int{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := s.GetUsers(ctx, tt.filter, models.Pagination{}, "", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []int
+			for _, user := range page.Items {
+				ids = append(ids, user.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestTaskLifecycle(t *testing.T) {
+	s, _, userID, _ := newTestService(t, nil)
+
+	taskID, err := s.CreateTask(ctx, models.TaskData{Name: "  Write report  ", Description: "Q3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.GetTask(ctx, taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Name != "Write report" || task.Status != models.TaskStatusTodo || task.Archived {
+		t.Errorf("created task = %+v", task)
+	}
+
+	status := models.TaskStatusInProgress
+	task, err = s.PatchTask(ctx, taskID, models.TaskPatch{Status: &status})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status != status || task.Description != "Q3" {
+		t.Errorf("patched task = %+v", task)
+	}
+
+	if _, err := s.StartTask(ctx, userID, taskID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteTask(ctx, taskID); !errors.Is(err, models.ErrTaskHasLogs) {
+		t.Errorf("DeleteTask err = %v, want %v", err, models.ErrTaskHasLogs)
+	}
+
+	archived := true
+	if _, err := s.PatchTask(ctx, taskID, models.TaskPatch{Archived: &archived}); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := s.GetTasks(ctx, models.TaskFilter{}, models.Pagination{}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, task := range tasks.Items {
+		if task.ID == taskID {
+			t.Error("archived task is listed without include_archived")
+		}
+	}
+}
+
+func TestTaskValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    models.TaskData
+		wantErr error
+	}{
+		{name: "valid", data: models.TaskData{Name: "Task", Status: models.TaskStatusDone}},
+		{name: "empty name", data: models.TaskData{Name: "   "}, wantErr: models.ErrInvalidTaskName},
+		{name: "unknown status", data: models.TaskData{Name: "Task", Status: "paused"}, wantErr: models.ErrInvalidTaskStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, _, taskID := newTestService(t, nil)
+			if _, err := s.CreateTask(ctx, tt.data); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateTask err = %v, want %v", err, tt.wantErr)
+			}
+			if err := s.UpdateTask(ctx, taskID, tt.data); !errors.Is(err, tt.wantErr) {
+				t.Errorf("UpdateTask err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
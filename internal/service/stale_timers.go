@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Что делать с таймерами, идущими дольше порога
+const (
+	// StaleTimersOff — не проверять
+	StaleTimersOff = "off"
+	// StaleTimersCap — остановить через StaleTimers.Cap после запуска
+	StaleTimersCap = "cap"
+	// StaleTimersEndOfDay — остановить в конце рабочего дня запуска
+	StaleTimersEndOfDay = "end_of_day"
+	// StaleTimersFlag — оставить идти, но пометить needs_review
+	StaleTimersFlag = "flag"
+)
+
+// StaleTimers — настройки проверки забытых таймеров
+type StaleTimers struct {
+	Policy string
+	// Threshold — таймер считается забытым, если идёт дольше
+	Threshold time.Duration
+	// Interval — период проверки
+	Interval time.Duration
+	// Cap — длительность остановленного таймера для политики cap
+	Cap time.Duration
+	// WorkdayEnd — конец рабочего дня от полуночи для политики end_of_day
+	WorkdayEnd time.Duration
+}
+
+// Один проход проверки: применяет политику к забытым таймерам, возвращает число затронутых записей.
+// Остановленные записи помечаются auto_closed и needs_review.
+func (s *Service) HandleStaleTimers(ctx context.Context, cfg StaleTimers) (int, error) {
+	var count int
+	var err error
+	switch cfg.Policy {
+	case StaleTimersOff:
+		return 0, nil
+	case StaleTimersCap:
+		count, err = s.Repository.CapStaleTimers(ctx, cfg.Threshold, cfg.Cap)
+	case StaleTimersEndOfDay:
+		count, err = s.Repository.CloseStaleTimersAtDayEnd(ctx, cfg.Threshold, cfg.WorkdayEnd)
+	case StaleTimersFlag:
+		count, err = s.Repository.FlagStaleTimers(ctx, cfg.Threshold)
+	default:
+		return 0, fmt.Errorf("unknown stale timers policy %q", cfg.Policy)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if count > 0 {
+		if cfg.Policy != StaleTimersFlag {
+			s.events().TimersStopped(count)
+		}
+		slog.InfoContext(ctx, "stale timers handled", "policy", cfg.Policy, "threshold", cfg.Threshold, "count", count)
+	}
+	return count, nil
+}
+
+// RunStaleTimers проверяет забытые таймеры сразу и затем каждые cfg.Interval, пока не отменён ctx.
+// Отмена ctx прерывает и текущую проверку; после возврата обращений к хранилищу нет.
+func (s *Service) RunStaleTimers(ctx context.Context, cfg StaleTimers) {
+	if cfg.Policy == StaleTimersOff {
+		return
+	}
+	slog.Info("stale timers worker started", "policy", cfg.Policy, "threshold", cfg.Threshold, "interval", cfg.Interval)
+	defer slog.Info("stale timers worker stopped")
+
+	runEvery(ctx, cfg.Interval, func() {
+		if _, err := s.HandleStaleTimers(ctx, cfg); err != nil && ctx.Err() == nil {
+			slog.Error("failed to handle stale timers", "policy", cfg.Policy, "error", err)
+		}
+	})
+}
+
+// runEvery вызывает fn сразу и затем каждые interval, пока не отменён ctx
+func runEvery(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		fn()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
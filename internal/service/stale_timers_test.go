@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+)
+
+func TestHandleStaleTimers(t *testing.T) {
+	// таймер запущен в пятницу в 09:00 и забыт до понедельника
+	start := time.Date(2024, 7, 5, 9, 0, 0, 0, time.UTC)
+	now := start.Add(72 * time.Hour)
+	cfg := StaleTimers{Threshold: 12 * time.Hour, Cap: 8 * time.Hour, WorkdayEnd: 18 * time.Hour}
+
+	tests := []struct {
+		policy          string
+		wantCount       int
+		wantEnd         *time.Time
+		wantNeedsReview bool
+	}{
+		{StaleTimersOff, 0, nil, false},
+		{StaleTimersFlag, 1, nil, true},
+		{StaleTimersCap, 1, ptr(start.Add(8 * time.Hour)), true},
+		{StaleTimersEndOfDay, 1, ptr(start.Add(9 * time.Hour)), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			s, repo, userID, staleTaskID := newTestService(t, nil)
+			freshTaskID, err := repo.CreateTask(ctx, models.Task{Name: "Fresh"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			repo.Now = func() time.Time { return start }
+			stale, err := s.StartTask(ctx, userID, staleTaskID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.PauseTask(ctx, userID, staleTaskID); err != nil {
+				t.Fatal(err)
+			}
+			repo.Now = func() time.Time { return now.Add(-time.Hour) }
+			fresh, err := s.StartTask(ctx, userID, freshTaskID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			repo.Now = func() time.Time { return now }
+
+			cfg.Policy = tt.policy
+			count, err := s.HandleStaleTimers(ctx, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != tt.wantCount {
+				t.Errorf("count = %d, want %d", count, tt.wantCount)
+			}
+
+			entry, _ := repo.GetTimeEntry(ctx, stale.TimeEntry.ID)
+			switch {
+			case tt.wantEnd == nil && entry.EndTime != nil:
+				t.Errorf("end = %v, want running", entry.EndTime)
+			case tt.wantEnd != nil && (entry.EndTime == nil || !entry.EndTime.Equal(*tt.wantEnd)):
+				t.Errorf("end = %v, want %v", entry.EndTime, tt.wantEnd)
+			}
+			if entry.NeedsReview != tt.wantNeedsReview || entry.AutoClosed != (tt.wantEnd != nil) {
+				t.Errorf("entry = %+v, want needs_review %v, auto_closed %v", entry, tt.wantNeedsReview, tt.wantEnd != nil)
+			}
+			if tt.wantEnd != nil {
+				pauses, _ := repo.GetPauses(ctx, entry.ID)
+				if pauses[0].EndTime == nil {
+					t.Error("break of the closed timer is still running")
+				}
+			}
+
+			if entry, _ := repo.GetTimeEntry(ctx, fresh.TimeEntry.ID); entry.EndTime != nil || entry.NeedsReview {
+				t.Errorf("fresh timer changed: %+v", entry)
+			}
+		})
+	}
+}
+
+func TestRunStaleTimersStopsOnCancel(t *testing.T) {
+	s, repo, userID, taskID := newTestService(t, nil)
+	if _, err := s.StartTask(ctx, userID, taskID); err != nil {
+		t.Fatal(err)
+	}
+	repo.Now = func() time.Time { return time.Now().Add(24 * time.Hour) }
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.RunStaleTimers(runCtx, StaleTimers{Policy: StaleTimersCap, Threshold: time.Hour, Cap: time.Hour, Interval: time.Hour})
+	}()
+
+	// первая проверка выполняется сразу после запуска
+	deadline := time.Now().Add(time.Second)
+	for {
+		if open, _ := repo.CountOpenTimers(ctx); open == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stale timer was not closed by the first pass")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not stop after cancel")
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
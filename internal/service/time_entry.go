@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+)
+
+// Получение записей времени пользователя за период от новых к старым по странице или по курсору
+func (s *Service) GetTimeEntries(ctx context.Context, userID int, startDate, endDate time.Time, pagination models.Pagination) (models.Page[models.TimeEntry], error) {
+	if !startDate.IsZero() && !endDate.IsZero() && startDate.After(endDate) {
+		return models.Page[models.TimeEntry]{}, models.ErrStartDateAfterEndDate
+	}
+	if err := normalizePagination(&pagination, DefaultPageSize, MaxPageSize, "start_time", "desc"); err != nil {
+		return models.Page[models.TimeEntry]{}, err
+	}
+	if err := s.checkUserHistory(ctx, userID); err != nil {
+		return models.Page[models.TimeEntry]{}, err
+	}
+
+	return s.Repository.GetTimeEntries(ctx, userID, startDate, endDate, pagination)
+}
+
+// Получение записи времени, принадлежащей пользователю
+func (s *Service) GetTimeEntry(ctx context.Context, userID, entryID int) (models.TimeEntry, error) {
+	entry, err := s.Repository.GetTimeEntry(ctx, entryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.TimeEntry{}, models.ErrTimeEntryNotFound
+		}
+		return models.TimeEntry{}, err
+	}
+	if entry.UserID != userID {
+		return models.TimeEntry{}, models.ErrTimeEntryNotFound
+	}
+
+	return entry, nil
+}
+
+// Создание записи времени задним числом
+func (s *Service) CreateTimeEntry(ctx context.Context, userID int, data models.TimeEntryData) (int, error) {
+	if err := s.checkUserExists(ctx, userID); err != nil {
+		return 0, err
+	}
+	entry, err := s.validateTimeEntry(ctx, userID, data)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.Repository.CreateTimeEntry(ctx, entry)
+}
+
+// Изменение записи времени, в том числе закрытие забытого таймера
+func (s *Service) UpdateTimeEntry(ctx context.Context, userID, entryID int, data models.TimeEntryData) error {
+	if err := s.checkUserExists(ctx, userID); err != nil {
+		return err
+	}
+	if _, err := s.GetTimeEntry(ctx, userID, entryID); err != nil {
+		return err
+	}
+	entry, err := s.validateTimeEntry(ctx, userID, data)
+	if err != nil {
+		return err
+	}
+
+	err = s.Repository.UpdateTimeEntry(ctx, entryID, entry)
+	if err == sql.ErrNoRows {
+		return models.ErrTimeEntryNotFound
+	}
+	return err
+}
+
+func (s *Service) DeleteTimeEntry(ctx context.Context, userID, entryID int) error {
+	if err := s.checkUserExists(ctx, userID); err != nil {
+		return err
+	}
+	if _, err := s.GetTimeEntry(ctx, userID, entryID); err != nil {
+		return err
+	}
+
+	err := s.Repository.DeleteTimeEntry(ctx, entryID)
+	if err == sql.ErrNoRows {
+		return models.ErrTimeEntryNotFound
+	}
+	return err
+}
+
+// Запись должна заканчиваться после начала и не в будущем. Пересечение с другими записями
+// пользователя репозиторий проверяет в одной транзакции с записью.
+func (s *Service) validateTimeEntry(ctx context.Context, userID int, data models.TimeEntryData) (models.TimeEntry, error) {
+	startTime := data.StartTime.UTC()
+	endTime := data.EndTime.UTC()
+	if !startTime.Before(endTime) {
+		return models.TimeEntry{}, models.ErrStartDateAfterEndDate
+	}
+	if endTime.After(time.Now()) {
+		return models.TimeEntry{}, models.ErrEndDateInFuture
+	}
+	if err := s.checkTaskAccess(ctx, userID, data.TaskID); err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	return models.TimeEntry{
+		UserID:    userID,
+		TaskID:    data.TaskID,
+		StartTime: startTime,
+		EndTime:   &endTime,
+		Note:      data.Note,
+	}, nil
+}
+
+// Проверяет, что пользователь есть и не удалён, перед изменением его данных
+func (s *Service) checkUserExists(ctx context.Context, userID int) error {
+	_, err := s.GetUser(ctx, userID)
+	return err
+}
+
+// Проверяет, что пользователь есть, в том числе мягко удалённый: его записи времени
+// и трудозатраты остаются доступны для чтения
+func (s *Service) checkUserHistory(ctx context.Context, userID int) error {
+	_, err := s.Repository.GetUser(ctx, userID)
+	if err == sql.ErrNoRows {
+		return models.ErrUserNotFound
+	}
+	return err
+}
+
+// Запущенные таймеры пользователя
+func (s *Service) GetUserActiveTimers(ctx context.Context, userID int) ([]models.ActiveTimer, error) {
+	if err := s.checkUserHistory(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.Repository.GetActiveTimers(ctx, models.ActiveTimerFilter{UserID: userID})
+}
+
+// Запущенные таймеры всех пользователей с отбором по задаче и проекту
+func (s *Service) GetActiveTimers(ctx context.Context, filter models.ActiveTimerFilter) ([]models.ActiveTimer, error) {
+	return s.Repository.GetActiveTimers(ctx, filter)
+}
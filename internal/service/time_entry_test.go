@@ -0,0 +1,218 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+)
+
+func TestCreateTimeEntry(t *testing.T) {
+	day := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hour int) time.Time { return day.Add(time.Duration(hour) * time.Hour) }
+
+	tests := []struct {
+		name    string
+		start   time.Time
+		end     time.Time
+		wantErr error
+	}{
+		{name: "before existing", start: at(8), end: at(10)},
+		{name: "adjacent to existing", start: at(12), end: at(13)},
+		{name: "overlaps existing", start: at(11), end: at(13), wantErr: models.ErrTimeEntryOverlap},
+		{name: "contains existing", start: at(9), end: at(14), wantErr: models.ErrTimeEntryOverlap},
+		{name: "end before start", start: at(16), end: at(15), wantErr: models.ErrStartDateAfterEndDate},
+		{name: "empty interval", start: at(16), end: at(16), wantErr: models.ErrStartDateAfterEndDate},
+		{name: "in the future", start: time.Now().Add(time.Hour), end: time.Now().Add(2 * time.Hour), wantErr: models.ErrEndDateInFuture},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, userID, taskID := newTestService(t, nil)
+			if _, err := s.CreateTimeEntry(ctx, userID, models.TimeEntryData{TaskID: taskID, StartTime: at(10), EndTime: at(12)}); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := s.CreateTimeEntry(ctx, userID, models.TimeEntryData{TaskID: taskID, StartTime: tt.start, EndTime: tt.end, Note: "back-fill"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Одновременные пересекающиеся записи: проверка и вставка атомарны, создаётся ровно одна
+func TestCreateTimeEntryConcurrent(t *testing.T) {
+	s, _, userID, taskID := newTestService(t, nil)
+	start := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	const requests = 20
+	errs := make(chan error, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data := models.TimeEntryData{TaskID: taskID, StartTime: start.Add(time.Duration(i) * time.Minute), EndTime: start.Add(time.Hour)}
+			_, err := s.CreateTimeEntry(ctx, userID, data)
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !errors.Is(err, models.ErrTimeEntryOverlap):
+			t.Errorf("err = %v, want %v", err, models.ErrTimeEntryOverlap)
+		}
+	}
+	if created != 1 {
+		t.Errorf("created %d entries, want 1", created)
+	}
+}
+
+func TestUpdateTimeEntryClosesForgottenTimer(t *testing.T) {
+	s, repo, userID, taskID := newTestService(t, nil)
+	start := time.Now().Add(-60 * time.Hour).UTC().Truncate(time.Second)
+	repo.Now = func() time.Time { return start }
+	if _, err := s.StartTask(ctx, userID, taskID); err != nil {
+		t.Fatal(err)
+	}
+	// таймер забыт на паузе
+	repo.Now = func() time.Time { return start.Add(6 * time.Hour) }
+	if _, err := s.PauseTask(ctx, userID, taskID); err != nil {
+		t.Fatal(err)
+	}
+	repo.Now = time.Now
+
+	page, err := s.GetTimeEntries(ctx, userID, time.Time{}, time.Time{}, models.Pagination{})
+	if err != nil || len(page.Items) != 1 || page.Items[0].EndTime != nil {
+		t.Fatalf("entries = %+v, err = %v", page.Items, err)
+	}
+	entries := page.Items
+
+	end := start.Add(8 * time.Hour)
+	err = s.UpdateTimeEntry(ctx, userID, entries[0].ID, models.TimeEntryData{TaskID: taskID, StartTime: start, EndTime: end, Note: "forgot to stop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := s.GetTimeEntry(ctx, userID, entries[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.EndTime == nil || !entry.EndTime.Equal(end) || entry.Note != "forgot to stop" {
+		t.Errorf("entry = %+v", entry)
+	}
+	// идущий перерыв закрывается концом записи
+	pauses, err := repo.GetPauses(ctx, entry.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pauses) != 1 || pauses[0].EndTime == nil || !pauses[0].EndTime.Equal(end) {
+		t.Errorf("pauses = %+v, want one closed at %v", pauses, end)
+	}
+
+	otherUserID, _ := s.CreateUser(ctx, models.UserData{PassportNumber: "2222 222222"})
+	if _, err := s.GetTimeEntry(ctx, otherUserID, entry.ID); !errors.Is(err, models.ErrTimeEntryNotFound) {
+		t.Errorf("other user's entry err = %v, want %v", err, models.ErrTimeEntryNotFound)
+	}
+	if err := s.DeleteTimeEntry(ctx, userID, entry.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetTimeEntry(ctx, userID, entry.ID); !errors.Is(err, models.ErrTimeEntryNotFound) {
+		t.Errorf("deleted entry err = %v, want %v", err, models.ErrTimeEntryNotFound)
+	}
+}
+
+func TestGetActiveTimers(t *testing.T) {
+	s, repo, firstUserID, firstTaskID := newTestService(t, nil)
+	secondUserID, err := repo.CreateUser(ctx, models.User{PassportNumber: "4321 098765", Surname: "Doe", Name: "Jane"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectID, err := s.CreateProject(ctx, models.ProjectData{Name: "Client A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectTaskID, err := s.CreateTask(ctx, models.TaskData{Name: "Design", ProjectID: &projectID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddProjectMember(ctx, projectID, secondUserID); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) { repo.Now = func() time.Time { return start.Add(d) } }
+	// у первого пользователя одна закрытая и одна идущая запись, второй на паузе с 10:00
+	if _, err := s.StartTask(ctx, firstUserID, firstTaskID); err != nil {
+		t.Fatal(err)
+	}
+	at(30 * time.Minute)
+	if err := s.EndTask(ctx, firstUserID, firstTaskID); err != nil {
+		t.Fatal(err)
+	}
+	at(time.Hour)
+	if _, err := s.StartTask(ctx, firstUserID, firstTaskID); err != nil {
+		t.Fatal(err)
+	}
+	at(0)
+	if _, err := s.StartTask(ctx, secondUserID, projectTaskID); err != nil {
+		t.Fatal(err)
+	}
+	at(time.Hour)
+	if _, err := s.PauseTask(ctx, secondUserID, projectTaskID); err != nil {
+		t.Fatal(err)
+	}
+	at(3 * time.Hour)
+
+	timers, err := s.GetUserActiveTimers(ctx, firstUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(timers) != 1 || timers[0].TimeEntry.TaskID != firstTaskID || timers[0].ElapsedSeconds != 2*3600 || timers[0].Paused {
+		t.Errorf("first user timers = %+v, want one running 2h timer", timers)
+	}
+	if _, err := s.GetUserActiveTimers(ctx, secondUserID+100); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("unknown user: err = %v, want %v", err, models.ErrUserNotFound)
+	}
+
+	tests := []struct {
+		name      string
+		filter    models.ActiveTimerFilter
+		wantUsers []int
+	}{
+		{"everyone, oldest first", models.ActiveTimerFilter{}, []int{secondUserID, firstUserID}},
+		{"by task", models.ActiveTimerFilter{TaskID: firstTaskID}, []int{firstUserID}},
+		{"by project", models.ActiveTimerFilter{ProjectID: projectID}, []int{secondUserID}},
+		{"no match", models.ActiveTimerFilter{ProjectID: projectID, TaskID: firstTaskID}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timers, err := s.GetActiveTimers(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(timers) != len(tt.wantUsers) {
+				t.Fatalf("timers = %+v, want users %v", timers, tt.wantUsers)
+			}
+			for i, timer := range timers {
+				if timer.TimeEntry.UserID != tt.wantUsers[i] {
+					t.Errorf("timer %d user = %d, want %d", i, timer.TimeEntry.UserID, tt.wantUsers[i])
+				}
+			}
+		})
+	}
+
+	timers, _ = s.GetActiveTimers(ctx, models.ActiveTimerFilter{UserID: secondUserID})
+	paused := timers[0]
+	if !paused.Paused || paused.ElapsedSeconds != 3*3600 || paused.WorkedSeconds != 3600 || paused.UserSurname != "Doe" || paused.TaskName != "Design" {
+		t.Errorf("paused timer = %+v, want paused, 3h elapsed, 1h worked", paused)
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+)
+
+func TestStartTaskTimerPolicy(t *testing.T) {
+	tests := []struct {
+		policy      string
+		wantErr     error
+		wantStopped int
+		wantOpen    int
+	}{
+		{"", nil, 0, 2},
+		{models.TimerPolicyParallel, nil, 0, 2},
+		{models.TimerPolicyReject, models.ErrAnotherTimerRunning, 0, 1},
+		{models.TimerPolicySwitch, nil, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			s, repo, userID, firstTaskID := newTestService(t, nil)
+			s.TimerPolicy = tt.policy
+			secondTaskID, err := repo.CreateTask(ctx, models.Task{Name: "Task 2"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			first, err := s.StartTask(ctx, userID, firstTaskID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(first.Stopped) != 0 {
+				t.Errorf("first start stopped %v", first.Stopped)
+			}
+
+			second, err := s.StartTask(ctx, userID, secondTaskID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if len(second.Stopped) != tt.wantStopped {
+				t.Errorf("stopped = %v, want %d entries", second.Stopped, tt.wantStopped)
+			}
+			if tt.wantStopped > 0 {
+				stopped := second.Stopped[0]
+				if stopped.ID != first.TimeEntry.ID || stopped.EndTime == nil {
+					t.Errorf("stopped = %+v, want ended entry %d", stopped, first.TimeEntry.ID)
+				}
+			}
+			if err == nil && second.TimeEntry.TaskID != secondTaskID {
+				t.Errorf("started task = %d, want %d", second.TimeEntry.TaskID, secondTaskID)
+			}
+
+			open, err := repo.CountOpenTimers(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if open != tt.wantOpen {
+				t.Errorf("open timers = %d, want %d", open, tt.wantOpen)
+			}
+		})
+	}
+}
+
+// Повторный запуск той же задачи не останавливает её таймер ни при какой политике
+func TestStartTaskSameTaskWithSwitch(t *testing.T) {
+	s, _, userID, taskID := newTestService(t, nil)
+	s.TimerPolicy = models.TimerPolicySwitch
+	if _, err := s.StartTask(ctx, userID, taskID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.StartTask(ctx, userID, taskID); !errors.Is(err, models.ErrTaskAlreadyStarted) {
+		t.Fatalf("err = %v, want %v", err, models.ErrTaskAlreadyStarted)
+	}
+}
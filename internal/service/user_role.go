@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+)
+
+func isValidRole(role string) bool {
+	switch role {
+	case models.RoleAdmin, models.RoleManager, models.RoleEmployee:
+		return true
+	}
+	return false
+}
+
+// Назначение роли и руководителя. Руководителем может быть только не удалённый manager или admin,
+// пользователь не может быть руководителем самому себе.
+func (s *Service) UpdateUserRole(ctx context.Context, userID int, data models.UserRoleData) (models.User, error) {
+	if !isValidRole(data.Role) {
+		return models.User{}, models.ErrInvalidRole
+	}
+	if err := s.checkUserExists(ctx, userID); err != nil {
+		return models.User{}, err
+	}
+	if data.ManagerID != nil {
+		if *data.ManagerID == userID {
+			return models.User{}, models.ErrInvalidManager
+		}
+		manager, err := s.Repository.GetUser(ctx, *data.ManagerID)
+		if err == sql.ErrNoRows {
+			return models.User{}, models.ErrInvalidManager
+		}
+		if err != nil {
+			return models.User{}, err
+		}
+		if manager.DeletedAt != nil || manager.Role != models.RoleManager && manager.Role != models.RoleAdmin {
+			return models.User{}, models.ErrInvalidManager
+		}
+	}
+
+	err := s.Repository.UpdateUserRole(ctx, userID, data.Role, data.ManagerID)
+	if err == sql.ErrNoRows {
+		return models.User{}, models.ErrUserNotFound
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+	return s.Repository.GetUser(ctx, userID)
+}
+
+// Текущая роль пользователя для проверки токена; удалённый пользователь не найден
+func (s *Service) UserRole(ctx context.Context, userID int) (string, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return user.Role, nil
+}
+
+// Проверяет, что managerID — непосредственный руководитель userID
+func (s *Service) IsManagerOf(ctx context.Context, managerID, userID int) (bool, error) {
+	user, err := s.Repository.GetUser(ctx, userID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return user.ManagerID != nil && *user.ManagerID == managerID, nil
+}
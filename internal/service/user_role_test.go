@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bigxxby/effective-mobile-test/internal/models"
+)
+
+func TestUpdateUserRole(t *testing.T) {
+	s, repo, userID, _ := newTestService(t, nil)
+	managerID, err := repo.CreateUser(ctx, models.User{PassportNumber: "1111 111111", Surname: "Boss", Name: "Anna"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherID, err := repo.CreateUser(ctx, models.User{PassportNumber: "2222 222222", Surname: "Doe", Name: "Jane"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.UpdateUserRole(ctx, userID, models.UserRoleData{Role: "owner"}); !errors.Is(err, models.ErrInvalidRole) {
+		t.Errorf("invalid role err = %v, want %v", err, models.ErrInvalidRole)
+	}
+	if _, err := s.UpdateUserRole(ctx, userID+100, models.UserRoleData{Role: models.RoleEmployee}); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("unknown user err = %v, want %v", err, models.ErrUserNotFound)
+	}
+	// руководителем может быть только manager или admin
+	if _, err := s.UpdateUserRole(ctx, userID, models.UserRoleData{Role: models.RoleEmployee, ManagerID: &otherID}); !errors.Is(err, models.ErrInvalidManager) {
+		t.Errorf("employee as manager err = %v, want %v", err, models.ErrInvalidManager)
+	}
+	if _, err := s.UpdateUserRole(ctx, managerID, models.UserRoleData{Role: models.RoleManager, ManagerID: &managerID}); !errors.Is(err, models.ErrInvalidManager) {
+		t.Errorf("self as manager err = %v, want %v", err, models.ErrInvalidManager)
+	}
+
+	if _, err := s.UpdateUserRole(ctx, managerID, models.UserRoleData{Role: models.RoleManager}); err != nil {
+		t.Fatal(err)
+	}
+	user, err := s.UpdateUserRole(ctx, userID, models.UserRoleData{Role: models.RoleEmployee, ManagerID: &managerID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Role != models.RoleEmployee || user.ManagerID == nil || *user.ManagerID != managerID {
+		t.Errorf("user = %+v", user)
+	}
+
+	tests := []struct {
+		managerID, userID int
+		want              bool
+	}{
+		{managerID, userID, true},
+		{managerID, otherID, false},
+		{userID, managerID, false},
+		{managerID, userID + 100, false},
+	}
+	for _, tt := range tests {
+		got, err := s.IsManagerOf(ctx, tt.managerID, tt.userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("IsManagerOf(%d, %d) = %v, want %v", tt.managerID, tt.userID, got, tt.want)
+		}
+	}
+
+	// при окончательном удалении руководителя команда остаётся без руководителя
+	if err := s.DeleteUser(ctx, managerID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PurgeUser(ctx, managerID); err != nil {
+		t.Fatal(err)
+	}
+	user, err = s.GetUser(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ManagerID != nil {
+		t.Errorf("manager_id after manager deletion = %d, want nil", *user.ManagerID)
+	}
+}
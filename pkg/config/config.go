@@ -0,0 +1,197 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Config — настройки приложения. Поле заполняется из переменной окружения из тега env;
+// default — значение по умолчанию, required — поле обязательно, oneof — допустимые значения,
+// secret — значение не выводится в ошибках и справке. Источники и их приоритет описаны в Load.
+type Config struct {
+	DB         DB
+	HTTP       HTTP
+	Operation  Operation
+	Log        Log
+	Auth       Auth
+	PeopleInfo PeopleInfo
+	Timer      Timer
+	StaleTimer StaleTimer
+	Audit      Audit
+	Shutdown   Shutdown
+}
+
+type DB struct {
+	Host     string `env:"DB_HOST" default:"localhost" required:"true"`
+	Port     int    `env:"DB_PORT" default:"5432"`
+	User     string `env:"DB_USER" required:"true"`
+	Password string `env:"DB_PASSWORD" secret:"true"`
+	Name     string `env:"DB_NAME" required:"true"`
+	SSLMode  string `env:"DB_SSLMODE" default:"disable" oneof:"disable,allow,prefer,require,verify-ca,verify-full"`
+
+	MaxOpenConns    int           `env:"DB_MAX_OPEN_CONNS" default:"25"`
+	MaxIdleConns    int           `env:"DB_MAX_IDLE_CONNS" default:"25"`
+	ConnMaxLifetime time.Duration `env:"DB_CONN_MAX_LIFETIME" default:"30m"`
+	ConnMaxIdleTime time.Duration `env:"DB_CONN_MAX_IDLE_TIME" default:"5m"`
+
+	// ConnectTimeout — сколько ждать базу при старте, повторяя подключение
+	ConnectTimeout    time.Duration `env:"DB_CONNECT_TIMEOUT" default:"60s"`
+	ConnectRetryDelay time.Duration `env:"DB_CONNECT_RETRY_DELAY" default:"500ms"`
+
+	// AutoMigrate применяет миграции при старте serve
+	AutoMigrate bool `env:"DB_AUTO_MIGRATE" default:"true"`
+	// Seed загружает тестовые данные в пустую базу
+	Seed bool `env:"DB_SEED" default:"false"`
+}
+
+type HTTP struct {
+	Addr              string        `env:"HTTP_ADDR" default:":8080" required:"true"`
+	ReadTimeout       time.Duration `env:"HTTP_READ_TIMEOUT" default:"15s"`
+	ReadHeaderTimeout time.Duration `env:"HTTP_READ_HEADER_TIMEOUT" default:"5s"`
+	WriteTimeout      time.Duration `env:"HTTP_WRITE_TIMEOUT" default:"40s"`
+	IdleTimeout       time.Duration `env:"HTTP_IDLE_TIMEOUT" default:"60s"`
+	MaxHeaderBytes    int           `env:"HTTP_MAX_HEADER_BYTES" default:"1048576"`
+	ShutdownTimeout   time.Duration `env:"HTTP_SHUTDOWN_TIMEOUT" default:"20s"`
+}
+
+// Operation — сроки выполнения операций API, 0 — без ограничения
+type Operation struct {
+	ReadTimeout   time.Duration `env:"OPERATION_READ_TIMEOUT" default:"5s"`
+	WriteTimeout  time.Duration `env:"OPERATION_WRITE_TIMEOUT" default:"10s"`
+	ReportTimeout time.Duration `env:"OPERATION_REPORT_TIMEOUT" default:"30s"`
+}
+
+type Log struct {
+	Level  string `env:"LOG_LEVEL" default:"info" oneof:"debug,info,warn,error"`
+	Format string `env:"LOG_FORMAT" default:"json" oneof:"json,text"`
+}
+
+type Auth struct {
+	Enabled bool `env:"AUTH_ENABLED" default:"true"`
+	// HMACKeys — пары "kid:secret" через запятую, первый ключ подписывает новые токены
+	HMACKeys string        `env:"AUTH_HMAC_KEYS" secret:"true"`
+	TokenTTL time.Duration `env:"AUTH_TOKEN_TTL" default:"24h"`
+	// MaxTokenTTL — наибольший срок токена, который можно запросить при выпуске
+	MaxTokenTTL time.Duration `env:"AUTH_MAX_TOKEN_TTL" default:"168h"`
+}
+
+// PeopleInfo — внешний API обогащения пользователей, пустой URL отключает обогащение
+type PeopleInfo struct {
+	URL        string        `env:"PEOPLE_INFO_URL"`
+	Timeout    time.Duration `env:"PEOPLE_INFO_TIMEOUT" default:"5s"`
+	Retries    int           `env:"PEOPLE_INFO_RETRIES" default:"2"`
+	RetryDelay time.Duration `env:"PEOPLE_INFO_RETRY_DELAY" default:"200ms"`
+}
+
+type Timer struct {
+	// StartPolicy — что делать при запуске таймера, пока у пользователя идёт другой: parallel, reject или switch
+	StartPolicy string `env:"TIMER_START_POLICY" default:"parallel" oneof:"parallel,reject,switch"`
+}
+
+// StaleTimer — фоновая проверка забытых таймеров: таймеры, идущие дольше Threshold,
+// проверяются каждые Interval и обрабатываются по Policy
+type StaleTimer struct {
+	// Policy: off — не проверять, cap — остановить через Cap после запуска,
+	// end_of_day — остановить в WorkdayEnd дня запуска, flag — пометить needs_review
+	Policy    string        `env:"STALE_TIMER_POLICY" default:"flag" oneof:"off,cap,end_of_day,flag"`
+	Threshold time.Duration `env:"STALE_TIMER_THRESHOLD" default:"12h"`
+	Interval  time.Duration `env:"STALE_TIMER_INTERVAL" default:"10m"`
+	Cap       time.Duration `env:"STALE_TIMER_CAP" default:"8h"`
+	// WorkdayEnd — конец рабочего дня в формате ЧЧ:ММ по UTC
+	WorkdayEnd string `env:"STALE_TIMER_WORKDAY_END" default:"18:00"`
+}
+
+// WorkdayEndOffset — конец рабочего дня как смещение от полуночи, WorkdayEnd должен пройти Validate
+func (s StaleTimer) WorkdayEndOffset() time.Duration {
+	clock, err := time.Parse("15:04", s.WorkdayEnd)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute
+}
+
+// Audit — хранение журнала аудита
+type Audit struct {
+	// Retention — сколько хранить события, 0 — хранить всегда
+	Retention time.Duration `env:"AUDIT_RETENTION" default:"2160h"`
+	// CleanupInterval — период удаления устаревших событий
+	CleanupInterval time.Duration `env:"AUDIT_CLEANUP_INTERVAL" default:"1h"`
+}
+
+type Shutdown struct {
+	// OpenTimers — что делать с запущенными таймерами при остановке: keep, stop или flag
+	OpenTimers string `env:"SHUTDOWN_OPEN_TIMERS" default:"keep" oneof:"keep,stop,flag"`
+}
+
+// Validate проверяет ограничения, которые нельзя описать тегами
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, message string) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s %s", key, message))
+		}
+	}
+
+	c.DB.validate(check)
+
+	check(c.HTTP.ReadTimeout >= 0, "HTTP_READ_TIMEOUT", "must not be negative")
+	check(c.HTTP.ReadHeaderTimeout >= 0, "HTTP_READ_HEADER_TIMEOUT", "must not be negative")
+	check(c.HTTP.WriteTimeout >= 0, "HTTP_WRITE_TIMEOUT", "must not be negative")
+	check(c.HTTP.IdleTimeout >= 0, "HTTP_IDLE_TIMEOUT", "must not be negative")
+	check(c.HTTP.MaxHeaderBytes > 0, "HTTP_MAX_HEADER_BYTES", "must be positive")
+	check(c.HTTP.ShutdownTimeout > 0, "HTTP_SHUTDOWN_TIMEOUT", "must be positive")
+
+	check(c.Operation.ReadTimeout >= 0, "OPERATION_READ_TIMEOUT", "must not be negative")
+	check(c.Operation.WriteTimeout >= 0, "OPERATION_WRITE_TIMEOUT", "must not be negative")
+	check(c.Operation.ReportTimeout >= 0, "OPERATION_REPORT_TIMEOUT", "must not be negative")
+	// иначе сервер оборвёт соединение раньше, чем отчёт успеет ответить 504
+	check(c.HTTP.WriteTimeout == 0 || c.HTTP.WriteTimeout > c.Operation.ReportTimeout,
+		"HTTP_WRITE_TIMEOUT", "must be greater than OPERATION_REPORT_TIMEOUT")
+
+	if c.Auth.Enabled {
+		check(strings.TrimSpace(c.Auth.HMACKeys) != "", "AUTH_HMAC_KEYS", "is required when AUTH_ENABLED=true")
+	}
+	check(c.Auth.TokenTTL > 0, "AUTH_TOKEN_TTL", "must be positive")
+	check(c.Auth.MaxTokenTTL >= c.Auth.TokenTTL, "AUTH_MAX_TOKEN_TTL", "must not be less than AUTH_TOKEN_TTL")
+
+	check(c.PeopleInfo.Timeout > 0, "PEOPLE_INFO_TIMEOUT", "must be positive")
+	check(c.PeopleInfo.Retries >= 0, "PEOPLE_INFO_RETRIES", "must not be negative")
+	check(c.PeopleInfo.RetryDelay >= 0, "PEOPLE_INFO_RETRY_DELAY", "must not be negative")
+
+	check(c.StaleTimer.Threshold > 0, "STALE_TIMER_THRESHOLD", "must be positive")
+	check(c.StaleTimer.Interval > 0, "STALE_TIMER_INTERVAL", "must be positive")
+	// иначе таймер остановился бы в будущем
+	check(c.StaleTimer.Cap > 0 && c.StaleTimer.Cap <= c.StaleTimer.Threshold, "STALE_TIMER_CAP", "must be positive and not exceed STALE_TIMER_THRESHOLD")
+	_, err := time.Parse("15:04", c.StaleTimer.WorkdayEnd)
+	check(err == nil, "STALE_TIMER_WORKDAY_END", "must be a time of day in HH:MM format")
+
+	check(c.Audit.Retention >= 0, "AUDIT_RETENTION", "must not be negative")
+	check(c.Audit.CleanupInterval > 0, "AUDIT_CLEANUP_INTERVAL", "must be positive")
+
+	return errors.Join(errs...)
+}
+
+// ValidateDB проверяет только настройки базы: их достаточно командам migrate и seed,
+// которым не нужны ни ключи подписи, ни настройки сервера
+func (c Config) ValidateDB() error {
+	var errs []error
+	c.DB.validate(func(ok bool, key, message string) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s %s", key, message))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+func (d DB) validate(check func(ok bool, key, message string)) {
+	check(d.Port > 0 && d.Port <= 65535, "DB_PORT", "must be between 1 and 65535")
+	check(d.MaxOpenConns >= 0, "DB_MAX_OPEN_CONNS", "must not be negative")
+	check(d.MaxIdleConns >= 0, "DB_MAX_IDLE_CONNS", "must not be negative")
+	check(d.MaxOpenConns == 0 || d.MaxIdleConns <= d.MaxOpenConns, "DB_MAX_IDLE_CONNS", "must not exceed DB_MAX_OPEN_CONNS")
+	check(d.ConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME", "must not be negative")
+	check(d.ConnMaxIdleTime >= 0, "DB_CONN_MAX_IDLE_TIME", "must not be negative")
+	check(d.ConnectTimeout > 0, "DB_CONNECT_TIMEOUT", "must be positive")
+	check(d.ConnectRetryDelay > 0, "DB_CONNECT_RETRY_DELAY", "must be positive")
+}
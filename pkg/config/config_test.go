@@ -0,0 +1,186 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// required задаёт обязательные переменные, чтобы тесты проверяли только своё
+func required(t *testing.T) {
+	t.Helper()
+	t.Setenv("DB_USER", "app")
+	t.Setenv("DB_NAME", "app")
+	t.Setenv("AUTH_HMAC_KEYS", "k1:0123456789abcdef0123456789abcdef")
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func load(args ...string) (Config, error) {
+	return Load(flag.NewFlagSet("test", flag.ContinueOnError), args)
+}
+
+func TestLoadDefaults(t *testing.T) {
+	required(t)
+	// без .env в текущей директории загрузка не падает
+	t.Setenv("CONFIG_FILE", "")
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	cfg, err := load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DB.Host != "localhost" || cfg.DB.Port != 5432 || !cfg.DB.AutoMigrate || cfg.DB.Seed {
+		t.Errorf("db = %+v", cfg.DB)
+	}
+	if cfg.HTTP.Addr != ":8080" || cfg.HTTP.MaxHeaderBytes != 1<<20 || cfg.Operation.ReportTimeout != 30*time.Second {
+		t.Errorf("http = %+v, operation = %+v", cfg.HTTP, cfg.Operation)
+	}
+	if cfg.Log.Level != "info" || cfg.Shutdown.OpenTimers != "keep" || !cfg.Auth.Enabled {
+		t.Errorf("cfg = %+v", cfg)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	required(t)
+	path := writeFile(t, "config.yaml", `
+db:
+  host: file-host
+  port: 6543
+  max_open_conns: 10
+  max_idle_conns: 5
+http:
+  addr: ":7000"
+log:
+  level: debug
+  format: text
+`)
+	t.Setenv("DB_PORT", "7654")
+	t.Setenv("LOG_LEVEL", "warn")
+
+	cfg, err := load("-config", path, "-log-level", "error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		got, want any
+	}{
+		{"file over default", cfg.DB.Host, "file-host"},
+		{"env over file", cfg.DB.Port, 7654},
+		{"flag over env", cfg.Log.Level, "error"},
+		{"file only", cfg.Log.Format, "text"},
+		{"nested key", cfg.DB.MaxOpenConns, 10},
+		{"default", cfg.DB.ConnMaxLifetime, 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadDotEnv(t *testing.T) {
+	required(t)
+	path := writeFile(t, "app.env", "HTTP_ADDR=:9000\nDB_SEED=true\nPOSTGRES_USER=ignored\n")
+	t.Setenv("CONFIG_FILE", path)
+
+	cfg, err := load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.HTTP.Addr != ":9000" || !cfg.DB.Seed {
+		t.Errorf("cfg = %+v", cfg)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Setenv("DB_USER", "")
+	t.Setenv("DB_NAME", "")
+	t.Setenv("AUTH_HMAC_KEYS", "")
+	t.Setenv("DB_PASSWORD", "")
+	path := writeFile(t, "config.yaml", "db:\n  hots: typo\n")
+
+	_, err := load("-config", path, "-db-port", "abc", "-http-shutdown-timeout", "soon", "-shutdown-open-timers", "drop", "-timer-start-policy", "both", "-stale-timer-cap", "13h", "-stale-timer-workday-end", "6pm", "-audit-cleanup-interval", "0s", "-auth-max-token-ttl", "1h", "-log-level", "loud")
+	if err == nil {
+		t.Fatal("err = nil")
+	}
+	for _, want := range []string{
+		"unknown setting DB_HOTS",
+		"DB_PORT must be an integer",
+		"DB_USER is required",
+		"DB_NAME is required",
+		"HTTP_SHUTDOWN_TIMEOUT must be a duration",
+		"SHUTDOWN_OPEN_TIMERS must be one of keep, stop, flag",
+		"TIMER_START_POLICY must be one of parallel, reject, switch",
+		"STALE_TIMER_CAP must be positive and not exceed STALE_TIMER_THRESHOLD",
+		"STALE_TIMER_WORKDAY_END must be a time of day in HH:MM format",
+		"AUDIT_CLEANUP_INTERVAL must be positive",
+		"AUTH_MAX_TOKEN_TTL must not be less than AUTH_TOKEN_TTL",
+		"LOG_LEVEL must be one of",
+		"AUTH_HMAC_KEYS is required",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q:\n%v", want, err)
+		}
+	}
+	if strings.Count(err.Error(), "DB_PORT") != 1 {
+		t.Errorf("DB_PORT reported more than once:\n%v", err)
+	}
+
+	if _, err := load("-config", filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("explicit missing config file: err = nil")
+	}
+}
+
+func TestLoadSecretsHidden(t *testing.T) {
+	required(t)
+	t.Setenv("AUTH_ENABLED", "false")
+	t.Setenv("HTTP_WRITE_TIMEOUT", "1s")
+	t.Setenv("DB_PASSWORD", "s3cret")
+
+	_, err := load()
+	if err == nil || !strings.Contains(err.Error(), "HTTP_WRITE_TIMEOUT must be greater than OPERATION_REPORT_TIMEOUT") {
+		t.Fatalf("err = %v", err)
+	}
+	if strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("secret leaked: %v", err)
+	}
+}
+
+// migrate и seed не требуют ключей подписи и прочих настроек сервера, но проверяют базу
+func TestLoadDB(t *testing.T) {
+	required(t)
+	t.Setenv("AUTH_HMAC_KEYS", "")
+	t.Setenv("STALE_TIMER_WORKDAY_END", "6pm")
+
+	if _, err := load(); err == nil || !strings.Contains(err.Error(), "AUTH_HMAC_KEYS is required") {
+		t.Errorf("Load err = %v, want AUTH_HMAC_KEYS is required", err)
+	}
+	cfg, err := LoadDB(flag.NewFlagSet("migrate", flag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DB.User != "app" {
+		t.Errorf("DB_USER = %q, want app", cfg.DB.User)
+	}
+
+	_, err = LoadDB(flag.NewFlagSet("migrate", flag.ContinueOnError), []string{"-db-max-idle-conns", "50", "-db-max-open-conns", "10"})
+	if err == nil || !strings.Contains(err.Error(), "DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS") {
+		t.Errorf("LoadDB err = %v, want DB_MAX_IDLE_CONNS error", err)
+	}
+}
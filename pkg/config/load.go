@@ -0,0 +1,285 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile читается, если файл настроек не задан явно и существует
+const DefaultFile = ".env"
+
+// field — лист Config, связанный с переменной окружения
+type field struct {
+	key      string
+	value    reflect.Value
+	def      string
+	required bool
+	secret   bool
+	oneof    []string
+}
+
+func (f field) flagName() string {
+	return strings.ToLower(strings.ReplaceAll(f.key, "_", "-"))
+}
+
+// Load собирает Config из источников в порядке возрастания приоритета:
+// значения по умолчанию, файл настроек, переменные окружения, флаги командной строки.
+//
+// Файл задаётся флагом -config или переменной CONFIG_FILE; файлы .yaml/.yml читаются как YAML
+// (вложенные ключи склеиваются через "_": db: {host: x} — это DB_HOST), остальные — как .env.
+// Без явного файла читается .env из текущей директории, если он есть.
+//
+// Флаги регистрируются в fs по именам переменных: DB_HOST — -db-host. После Load в fs.Args()
+// остаются аргументы после флагов. Все ошибки возвращаются одной ошибкой.
+func Load(fs *flag.FlagSet, args []string) (Config, error) {
+	return loadWith(fs, args, Config.Validate)
+}
+
+// LoadDB — Load для команд, работающих только с базой: вместо Validate проверяется ValidateDB
+func LoadDB(fs *flag.FlagSet, args []string) (Config, error) {
+	return loadWith(fs, args, Config.ValidateDB)
+}
+
+func loadWith(fs *flag.FlagSet, args []string, validate func(Config) error) (Config, error) {
+	var cfg Config
+	fields := collectFields(reflect.ValueOf(&cfg).Elem())
+
+	configFile := fs.String("config", "", "path to a .env or YAML config file (env CONFIG_FILE)")
+	flagValues := make(map[string]*string, len(fields))
+	for _, f := range fields {
+		usage := "env " + f.key
+		if f.def != "" && !f.secret {
+			usage += ", default " + f.def
+		}
+		flagValues[f.key] = fs.String(f.flagName(), "", usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	values := make(map[string]string, len(fields))
+	for _, f := range fields {
+		if f.def != "" {
+			values[f.key] = f.def
+		}
+	}
+
+	var errs []error
+
+	path, explicit := *configFile, true
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path == "" {
+		path, explicit = DefaultFile, false
+	}
+	fileValues, err := readFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && !explicit:
+	case err != nil:
+		errs = append(errs, fmt.Errorf("config file %s: %w", path, err))
+	default:
+		for key, value := range fileValues {
+			values[key] = value
+		}
+		if isYAML(path) {
+			errs = append(errs, unknownKeys(fileValues, fields, path)...)
+		}
+	}
+
+	for _, f := range fields {
+		if value, ok := os.LookupEnv(f.key); ok {
+			values[f.key] = value
+		}
+	}
+
+	fs.Visit(func(fl *flag.Flag) {
+		for _, f := range fields {
+			if fl.Name == f.flagName() {
+				values[f.key] = *flagValues[f.key]
+			}
+		}
+	})
+
+	failed := make(map[string]bool)
+	for _, f := range fields {
+		if err := f.set(values[f.key]); err != nil {
+			errs = append(errs, err)
+			failed[f.key] = true
+		}
+	}
+	// ошибки Validate по полям, которые не удалось разобрать, повторяли бы уже найденные
+	if err := validate(cfg); err != nil {
+		invalid := []error{err}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			invalid = joined.Unwrap()
+		}
+		for _, err := range invalid {
+			key, _, _ := strings.Cut(err.Error(), " ")
+			if !failed[key] {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return Config{}, fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return cfg, nil
+}
+
+func collectFields(v reflect.Value) []field {
+	var fields []field
+	for i := 0; i < v.NumField(); i++ {
+		structField := v.Type().Field(i)
+		if structField.Type.Kind() == reflect.Struct && structField.Type != reflect.TypeOf(time.Duration(0)) {
+			fields = append(fields, collectFields(v.Field(i))...)
+			continue
+		}
+		key := structField.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		f := field{
+			key:      key,
+			value:    v.Field(i),
+			def:      structField.Tag.Get("default"),
+			required: structField.Tag.Get("required") == "true",
+			secret:   structField.Tag.Get("secret") == "true",
+		}
+		if oneof := structField.Tag.Get("oneof"); oneof != "" {
+			f.oneof = strings.Split(oneof, ",")
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// set разбирает строковое значение в поле по его типу
+func (f field) set(raw string) error {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		if f.required {
+			return fmt.Errorf("%s is required", f.key)
+		}
+		f.value.SetZero()
+		return nil
+	}
+	if f.oneof != nil && !slices.Contains(f.oneof, raw) {
+		return fmt.Errorf("%s must be one of %s, got %q", f.key, strings.Join(f.oneof, ", "), raw)
+	}
+
+	shown := strconv.Quote(raw)
+	if f.secret {
+		shown = "(hidden)"
+	}
+
+	switch f.value.Interface().(type) {
+	case string:
+		f.value.SetString(raw)
+	case int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%s must be an integer, got %s", f.key, shown)
+		}
+		f.value.SetInt(int64(n))
+	case bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false, got %s", f.key, shown)
+		}
+		f.value.SetBool(b)
+	case time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%s must be a duration like 5s or 1m, got %s", f.key, shown)
+		}
+		f.value.SetInt(int64(d))
+	default:
+		return fmt.Errorf("%s has unsupported type %s", f.key, f.value.Type())
+	}
+	return nil
+}
+
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+func readFile(path string) (map[string]string, error) {
+	if !isYAML(path) {
+		if _, err := os.Stat(path); err != nil {
+			return nil, err
+		}
+		return godotenv.Read(path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	if err := flatten("", tree, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// flatten превращает вложенные ключи YAML в имена переменных: db.max_open_conns — DB_MAX_OPEN_CONNS
+func flatten(prefix string, tree map[string]any, values map[string]string) error {
+	for key, value := range tree {
+		key = strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		switch value := value.(type) {
+		case map[string]any:
+			if err := flatten(key, value, values); err != nil {
+				return err
+			}
+		case []any:
+			return fmt.Errorf("%s: lists are not supported", key)
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	return nil
+}
+
+func unknownKeys(values map[string]string, fields []field, path string) []error {
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[f.key] = true
+	}
+	var unknown []string
+	for key := range values {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+
+	var errs []error
+	for _, key := range unknown {
+		errs = append(errs, fmt.Errorf("config file %s: unknown setting %s", path, key))
+	}
+	return errs
+}
@@ -0,0 +1,129 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Person описывает ответ внешнего API с информацией о человеке.
+type Person struct {
+	Surname    string `json:"surname"`
+	Name       string `json:"name"`
+	Patronymic string `json:"patronymic"`
+	Address    string `json:"address"`
+}
+
+// Enricher возвращает данные о человеке по серии и номеру паспорта.
+type Enricher interface {
+	Enrich(ctx context.Context, passportSerie, passportNumber string) (Person, error)
+}
+
+var (
+	ErrPersonNotFound = errors.New("person not found")
+	ErrBadResponse    = errors.New("unexpected response from people info API")
+)
+
+type Config struct {
+	BaseURL    string
+	Timeout    time.Duration
+	Retries    int
+	RetryDelay time.Duration
+}
+
+// Client ходит во внешний API: GET {BaseURL}/info?passportSerie=1234&passportNumber=567890
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Retries    int
+	RetryDelay time.Duration
+}
+
+func New(cfg Config) Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.Retries < 0 {
+		cfg.Retries = 0
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = 200 * time.Millisecond
+	}
+	return Client{
+		BaseURL:    strings.TrimRight(cfg.BaseURL, "/"),
+		HTTPClient: &http.Client{Timeout: cfg.Timeout},
+		Retries:    cfg.Retries,
+		RetryDelay: cfg.RetryDelay,
+	}
+}
+
+// Enrich запрашивает данные о человеке. Сетевые ошибки, 429 и 5xx повторяются
+// до Retries раз с экспоненциальной задержкой, остальные ответы возвращаются сразу.
+func (c Client) Enrich(ctx context.Context, passportSerie, passportNumber string) (Person, error) {
+	query := url.Values{}
+	query.Set("passportSerie", passportSerie)
+	query.Set("passportNumber", passportNumber)
+	endpoint := c.BaseURL + "/info?" + query.Encode()
+
+	var lastErr error
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		if attempt > 0 {
+			delay := c.RetryDelay << (attempt - 1)
+			select {
+			case <-ctx.Done():
+				return Person{}, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		person, retry, err := c.do(ctx, endpoint)
+		if err == nil {
+			return person, nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return Person{}, lastErr
+}
+
+func (c Client) do(ctx context.Context, endpoint string) (Person, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Person{}, false, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return Person{}, false, ctx.Err()
+		}
+		return Person{}, true, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusNotFound:
+		return Person{}, false, ErrPersonNotFound
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		io.Copy(io.Discard, resp.Body)
+		return Person{}, true, fmt.Errorf("%w: status %d", ErrBadResponse, resp.StatusCode)
+	default:
+		return Person{}, false, fmt.Errorf("%w: status %d", ErrBadResponse, resp.StatusCode)
+	}
+
+	var person Person
+	if err := json.NewDecoder(resp.Body).Decode(&person); err != nil {
+		return Person{}, false, fmt.Errorf("%w: %v", ErrBadResponse, err)
+	}
+	return person, false, nil
+}
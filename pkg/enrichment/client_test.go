@@ -0,0 +1,107 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientEnrich(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   func(calls int32) (int, any)
+		retries   int
+		want      Person
+		wantErr   error
+		wantCalls int32
+	}{
+		{
+			name: "success",
+			handler: func(int32) (int, any) {
+				return http.StatusOK, Person{Surname: "Ivanov", Name: "Ivan", Patronymic: "Ivanovich", Address: "Moscow"}
+			},
+			want:      Person{Surname: "Ivanov", Name: "Ivan", Patronymic: "Ivanovich", Address: "Moscow"},
+			wantCalls: 1,
+		},
+		{
+			name: "retries server errors",
+			handler: func(calls int32) (int, any) {
+				if calls < 3 {
+					return http.StatusInternalServerError, nil
+				}
+				return http.StatusOK, Person{Surname: "Petrov", Name: "Petr"}
+			},
+			retries:   2,
+			want:      Person{Surname: "Petrov", Name: "Petr"},
+			wantCalls: 3,
+		},
+		{
+			name: "gives up after retries",
+			handler: func(int32) (int, any) {
+				return http.StatusBadGateway, nil
+			},
+			retries:   1,
+			wantErr:   ErrBadResponse,
+			wantCalls: 2,
+		},
+		{
+			name: "not found is not retried",
+			handler: func(int32) (int, any) {
+				return http.StatusNotFound, nil
+			},
+			retries:   3,
+			wantErr:   ErrPersonNotFound,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&calls, 1)
+				if r.URL.Path != "/info" || r.URL.Query().Get("passportSerie") != "1234" || r.URL.Query().Get("passportNumber") != "567890" {
+					t.Errorf("unexpected request %s", r.URL)
+				}
+				status, body := tt.handler(n)
+				w.WriteHeader(status)
+				if body != nil {
+					json.NewEncoder(w).Encode(body)
+				}
+			}))
+			defer srv.Close()
+
+			client := New(Config{BaseURL: srv.URL + "/", Retries: tt.retries, RetryDelay: time.Millisecond})
+			got, err := client.Enrich(context.Background(), "1234", "567890")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("person = %+v, want %+v", got, tt.want)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClientEnrichTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	client := New(Config{BaseURL: srv.URL, Timeout: 20 * time.Millisecond})
+	if _, err := client.Enrich(context.Background(), "1234", "567890"); err == nil {
+		t.Fatal("expected timeout error")
+	}
+}
@@ -0,0 +1,99 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Базу, созданную до появления миграций старым table.sql (testdata/baseline.sql), миграции
+// доводят до актуальной схемы без потери данных. Нужен PostgreSQL: TEST_DATABASE_DSN
+// в формате lib/pq, иначе тест пропускается. Тест работает в отдельной временной схеме.
+func TestUpFromBaselineSchema(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		var err error
+		if dsn, err = pq.ParseURL(dsn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+	schema := fmt.Sprintf("migrations_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+
+	db, err := sql.Open("postgres", dsn+" search_path="+schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	baseline, err := os.ReadFile("testdata/baseline.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(string(baseline)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO users (passport_number, surname, name) VALUES ('1234 567890', 'Ivanov', 'Ivan')"); err != nil {
+		t.Fatal(err)
+	}
+
+	migrator, err := New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied, err := migrator.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied != len(migrator.Migrations) {
+		t.Errorf("applied %d migrations, want %d", applied, len(migrator.Migrations))
+	}
+	if version, err := migrator.Version(); err != nil || version != migrator.Latest() {
+		t.Errorf("version = %d, %v, want %d", version, err, migrator.Latest())
+	}
+
+	rows, err := db.Query("SELECT column_name FROM information_schema.columns WHERE table_schema = $1 AND table_name = 'users'", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			t.Fatal(err)
+		}
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"patronymic", "address", "role", "deleted_at", "created_at"} {
+		if !slices.Contains(columns, want) {
+			t.Errorf("users has no column %s after migration, columns: %v", want, columns)
+		}
+	}
+
+	var surname string
+	if err := db.QueryRow("SELECT surname FROM users WHERE passport_number = '1234 567890'").Scan(&surname); err != nil || surname != "Ivanov" {
+		t.Errorf("baseline user = %q, %v, want Ivanov", surname, err)
+	}
+}
@@ -1,32 +1,455 @@
 package migrations
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"os"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// Миграции лежат в sql/ в виде пар NNNN_name.up.sql / NNNN_name.down.sql
+// и применяются по возрастанию номера. Применённые версии хранятся в schema_migrations.
+//
+//go:embed sql/*.sql
+var migrationsFS embed.FS
+
+// Тестовые данные применяются отдельно и только по запросу, см. Migrator.Seed
+//
+//go:embed seed/*.sql
+var seedFS embed.FS
+
+// Ключ pg_advisory_lock, под которым выполняются миграции,
+// чтобы несколько реплик не применяли их одновременно
+const lockKey int64 = 7_310_251_000
+
+// legacyUpgrade приводит базу, созданную до появления миграций из table.sql, к схеме 0001_init.
+// У такой базы нет schema_migrations, а 0001 из-за CREATE TABLE IF NOT EXISTS пропускает
+// существующие таблицы, поэтому добавленные позже в 0001 столбцы нужно добавить отдельно.
+// Выполняется в одной транзакции с 0001; на пустой базе ничего не делает.
+const legacyUpgrade = `
+	ALTER TABLE IF EXISTS users
+		ADD COLUMN IF NOT EXISTS patronymic VARCHAR(255),
+		ADD COLUMN IF NOT EXISTS address TEXT
+`
+
+var (
+	ErrChecksumMismatch = errors.New("applied migration differs from migration file")
+	ErrInvalidMigration = errors.New("invalid migration file")
+)
+
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Status описывает состояние одной миграции в базе
+type Status struct {
+	Version          int64
+	Name             string
+	Applied          bool
+	AppliedAt        time.Time
+	ChecksumMismatch bool
+}
+
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []Migration
+}
+
+func New(db *sql.DB) (Migrator, error) {
+	migrations, err := Load(migrationsFS, "sql")
+	if err != nil {
+		return Migrator{}, err
+	}
+	return Migrator{
+		DB:         db,
+		Migrations: migrations,
+	}, nil
+}
+
+// ApplyMigrations применяет все ещё не применённые миграции
 func ApplyMigrations(db *sql.DB) error {
-	migrationFiles := []string{
-		"pkg/migrations/sql/drop.sql",
-		"pkg/migrations/sql/table.sql",
-		"pkg/migrations/sql/mock.sql",
+	migrator, err := New(db)
+	if err != nil {
+		return err
+	}
+	_, err = migrator.Up()
+	return err
+}
+
+// Load читает пары up/down файлов из dir и сортирует их по версии
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, file := range migrationFiles {
-		content, err := os.ReadFile(file)
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		version, name, direction, err := parseFileName(entry.Name())
 		if err != nil {
-			return fmt.Errorf("failed to read migration file %s: %v", file, err)
+			return nil, err
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
 		}
 
-		_, err = db.Exec(string(content))
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("%w: version %d has different names %q and %q", ErrInvalidMigration, version, migration.Name, name)
+		}
+		switch direction {
+		case "up":
+			migration.Up = string(content)
+			sum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		case "down":
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("%w: version %d has no up file", ErrInvalidMigration, migration.Version)
+		}
+		if migration.Down == "" {
+			return nil, fmt.Errorf("%w: version %d has no down file", ErrInvalidMigration, migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// "0001_init.up.sql" -> 1, "init", "up"
+func parseFileName(fileName string) (int64, string, string, error) {
+	base := strings.TrimSuffix(fileName, ".sql")
+	var direction string
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		direction = "up"
+	case strings.HasSuffix(base, ".down"):
+		direction = "down"
+	default:
+		return 0, "", "", fmt.Errorf("%w: %s must end with .up.sql or .down.sql", ErrInvalidMigration, fileName)
+	}
+	base = strings.TrimSuffix(base, "."+direction)
+
+	versionPart, name, ok := strings.Cut(base, "_")
+	if !ok || name == "" {
+		return 0, "", "", fmt.Errorf("%w: %s must be named NNNN_name.%s.sql", ErrInvalidMigration, fileName, direction)
+	}
+	version, err := strconv.ParseInt(versionPart, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", "", fmt.Errorf("%w: %s has invalid version", ErrInvalidMigration, fileName)
+	}
+	return version, name, direction, nil
+}
+
+// Latest возвращает версию последней известной миграции
+func (m Migrator) Latest() int64 {
+	if len(m.Migrations) == 0 {
+		return 0
+	}
+	return m.Migrations[len(m.Migrations)-1].Version
+}
+
+// Up применяет все неприменённые миграции и возвращает их количество
+func (m Migrator) Up() (int, error) {
+	var applied int
+	err := m.withLock(func(conn *sql.Conn) error {
+		var err error
+		applied, err = m.up(conn, 0)
+		return err
+	})
+	return applied, err
+}
+
+// Down откатывает n последних применённых миграций и возвращает их количество
+func (m Migrator) Down(n int) (int, error) {
+	var reverted int
+	err := m.withLock(func(conn *sql.Conn) error {
+		var err error
+		reverted, err = m.down(conn, n)
+		return err
+	})
+	return reverted, err
+}
+
+// Redo откатывает и заново применяет последнюю применённую миграцию
+func (m Migrator) Redo() error {
+	return m.withLock(func(conn *sql.Conn) error {
+		reverted, err := m.down(conn, 1)
+		if err != nil || reverted == 0 {
+			return err
+		}
+		_, err = m.up(conn, 1)
+		return err
+	})
+}
+
+// Status возвращает состояние всех известных миграций
+func (m Migrator) Status() ([]Status, error) {
+	var statuses []Status
+	err := m.withLock(func(conn *sql.Conn) error {
+		applied, err := m.applied(conn)
 		if err != nil {
-			return fmt.Errorf("failed to apply migration %s: %v", file, err)
+			return err
 		}
+		for _, migration := range m.Migrations {
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if row, ok := applied[migration.Version]; ok {
+				status.Applied = true
+				status.AppliedAt = row.appliedAt
+				status.ChecksumMismatch = row.checksum != migration.Checksum
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// Version возвращает последнюю применённую версию или 0
+func (m Migrator) Version() (int64, error) {
+	ctx := context.Background()
+	if err := ensureTable(ctx, m.DB); err != nil {
+		return 0, err
+	}
+	return m.AppliedVersion(ctx)
+}
 
-		fmt.Printf("Applied migration %s\n", file)
+// AppliedVersion возвращает версию последней применённой миграции без изменения схемы:
+// если таблицы schema_migrations ещё нет, версия 0
+func (m Migrator) AppliedVersion(ctx context.Context) (int64, error) {
+	var exists bool
+	if err := m.DB.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return 0, err
 	}
+	if !exists {
+		return 0, nil
+	}
+	var version int64
+	err := m.DB.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	return version, err
+}
 
-	fmt.Println("All migrations applied successfully")
+// Seed загружает тестовые данные из seed/. Ничего не делает, если в users уже есть записи.
+func (m Migrator) Seed() error {
+	return m.withLock(func(conn *sql.Conn) error {
+		ctx := context.Background()
+		var hasUsers bool
+		if err := conn.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM users)").Scan(&hasUsers); err != nil {
+			return err
+		}
+		if hasUsers {
+			slog.Info("skipping seed: users table is not empty")
+			return nil
+		}
+
+		files, err := fs.Glob(seedFS, "seed/*.sql")
+		if err != nil {
+			return err
+		}
+		sort.Strings(files)
+
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		for _, file := range files {
+			content, err := fs.ReadFile(seedFS, file)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, string(content)); err != nil {
+				return fmt.Errorf("failed to apply seed %s: %v", file, err)
+			}
+			slog.Info("applied seed", "file", file)
+		}
+		return tx.Commit()
+	})
+}
+
+func (m Migrator) up(conn *sql.Conn, limit int) (int, error) {
+	ctx := context.Background()
+	applied, err := m.applied(conn)
+	if err != nil {
+		return 0, err
+	}
+	if err := m.verify(applied); err != nil {
+		return 0, err
+	}
+
+	var count int
+	for _, migration := range m.Migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if limit > 0 && count >= limit {
+			break
+		}
+
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return count, err
+		}
+		if migration.Version == 1 {
+			if _, err := tx.ExecContext(ctx, legacyUpgrade); err != nil {
+				tx.Rollback()
+				return count, fmt.Errorf("failed to upgrade pre-migration schema: %v", err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+			tx.Rollback()
+			return count, fmt.Errorf("failed to apply migration %04d_%s: %v", migration.Version, migration.Name, err)
+		}
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+			migration.Version, migration.Name, migration.Checksum)
+		if err != nil {
+			tx.Rollback()
+			return count, err
+		}
+		if err := tx.Commit(); err != nil {
+			return count, err
+		}
+
+		slog.Info("applied migration", "version", migration.Version, "name", migration.Name)
+		count++
+	}
+	return count, nil
+}
+
+func (m Migrator) down(conn *sql.Conn, n int) (int, error) {
+	ctx := context.Background()
+	applied, err := m.applied(conn)
+	if err != nil {
+		return 0, err
+	}
+	if err := m.verify(applied); err != nil {
+		return 0, err
+	}
+
+	var count int
+	for i := len(m.Migrations) - 1; i >= 0 && count < n; i-- {
+		migration := m.Migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return count, err
+		}
+		if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+			tx.Rollback()
+			return count, fmt.Errorf("failed to revert migration %04d_%s: %v", migration.Version, migration.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", migration.Version); err != nil {
+			tx.Rollback()
+			return count, err
+		}
+		if err := tx.Commit(); err != nil {
+			return count, err
+		}
+
+		slog.Info("reverted migration", "version", migration.Version, "name", migration.Name)
+		count++
+	}
+	return count, nil
+}
+
+// Проверяет, что уже применённые миграции не были изменены после применения
+func (m Migrator) verify(applied map[int64]appliedMigration) error {
+	for _, migration := range m.Migrations {
+		row, ok := applied[migration.Version]
+		if ok && row.checksum != migration.Checksum {
+			return fmt.Errorf("%w: %04d_%s", ErrChecksumMismatch, migration.Version, migration.Name)
+		}
+	}
 	return nil
 }
+
+type appliedMigration struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+func (m Migrator) applied(conn *sql.Conn) (map[int64]appliedMigration, error) {
+	rows, err := conn.QueryContext(context.Background(), "SELECT version, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var version int64
+		var row appliedMigration
+		if err := rows.Scan(&version, &row.checksum, &row.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = row
+	}
+	return applied, rows.Err()
+}
+
+// Выполняет fn на одном соединении под pg_advisory_lock
+func (m Migrator) withLock(fn func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %v", err)
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockKey)
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func ensureTable(ctx context.Context, db execer) error {
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`)
+	return err
+}
@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	migrations, err := Load(migrationsFS, "sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, migration := range migrations {
+		if migration.Version != int64(i+1) {
+			t.Errorf("migration %d has version %d, versions must be sequential", i, migration.Version)
+		}
+		if migration.Checksum == "" {
+			t.Errorf("migration %d has no checksum", migration.Version)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []int64
+		wantErr error
+	}{
+		{
+			name: "sorted by version",
+			files: fstest.MapFS{
+				"sql/0002_b.up.sql":   {Data: []byte("b")},
+				"sql/0002_b.down.sql": {Data: []byte("b")},
+				"sql/0001_a.up.sql":   {Data: []byte("a")},
+				"sql/0001_a.down.sql": {Data: []byte("a")},
+			},
+			want: []int64{1, 2},
+		},
+		{
+			name: "missing down",
+			files: fstest.MapFS{
+				"sql/0001_a.up.sql": {Data: []byte("a")},
+			},
+			wantErr: ErrInvalidMigration,
+		},
+		{
+			name: "bad file name",
+			files: fstest.MapFS{
+				"sql/init.sql": {Data: []byte("a")},
+			},
+			wantErr: ErrInvalidMigration,
+		},
+		{
+			name: "conflicting names",
+			files: fstest.MapFS{
+				"sql/0001_a.up.sql":   {Data: []byte("a")},
+				"sql/0001_b.down.sql": {Data: []byte("b")},
+			},
+			wantErr: ErrInvalidMigration,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := Load(tt.files, "sql")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if len(migrations) != len(tt.want) {
+				t.Fatalf("got %d migrations, want %d", len(migrations), len(tt.want))
+			}
+			for i, migration := range migrations {
+				if migration.Version != tt.want[i] {
+					t.Errorf("migration %d version = %d, want %d", i, migration.Version, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+-- Вставка данных в таблицу users
+-- Insert unique mock data into users table
+INSERT INTO users (passport_number, surname, name) VALUES
+    ('1234 567890', 'Smith', 'John'),
+    ('5678 901234', 'Doe', 'Jane'),
+    ('2345 678901', 'Johnson', 'Michael'),
+    ('7890 123456', 'Williams', 'Emily'),
+    ('3456 789012', 'Brown', 'James'),
+    ('9012 345678', 'Jones', 'Emma'),
+    ('4567 890123', 'Garcia', 'Daniel'),
+    ('8901 234567', 'Martinez', 'Sophia'),
+    ('5678 901235', 'Robinson', 'Olivia'),  
+    ('1234 567891', 'Clark', 'Liam'),        
+    ('7890 123457', 'Lewis', 'Charlotte'),   
+    ('2345 678902', 'Lee', 'Benjamin'),      
+    ('9012 345679', 'Walker', 'Amelia'),     
+    ('3456 789013', 'Hall', 'Elijah'),       
+    ('4567 890124', 'Allen', 'Mia'),         
+    ('5678 901236', 'Young', 'Ethan'),       
+    ('1234 567892', 'Hernandez', 'Isabella'),
+    ('7890 123458', 'King', 'Ava'),          
+    ('2345 678903', 'Wright', 'Noah'),       
+    ('9012 345680', 'Lopez', 'Sophia');      
+
+
+-- Вставка данных в таблицу tasks
+INSERT INTO tasks (task_name) VALUES
+    ('Task 1'),
+    ('Task 2'),
+    ('Task 3'),
+    ('Task 4'),
+    ('Task 5'),
+    ('Task 6'),
+    ('Task 7'),
+    ('Task 8'),
+    ('Task 9'),
+    ('Task 10'),
+    ('Task 11'),
+    ('Task 12'),
+    ('Task 13'),
+    ('Task 14'),
+    ('Task 15'),
+    ('Task 16'),
+    ('Task 17'),
+    ('Task 18'),
+    ('Task 19'),
+    ('Task 20');
+
+-- Вставка данных в таблицу task_logs
+-- Пользователи и задачи ищутся по номеру паспорта и названию, а не по id
+INSERT INTO task_logs (user_id, task_id, start_time, end_time)
+SELECT u.id, t.id, v.start_time, v.end_time
+FROM (VALUES
+    ('1234 567890', 'Task 1', TIMESTAMP '2024-07-01 10:00:00', TIMESTAMP '2024-07-01 12:30:00'),
+    ('1234 567890', 'Task 2', TIMESTAMP '2024-07-01 14:00:00', TIMESTAMP '2024-07-01 15:45:00'),
+    ('5678 901234', 'Task 3', TIMESTAMP '2024-07-01 09:00:00', TIMESTAMP '2024-07-01 12:15:00'),
+    ('2345 678901', 'Task 4', TIMESTAMP '2024-07-02 08:30:00', TIMESTAMP '2024-07-02 09:30:00'),
+    ('7890 123456', 'Task 5', TIMESTAMP '2024-07-02 10:00:00', TIMESTAMP '2024-07-02 12:00:00'),
+    ('3456 789012', 'Task 6', TIMESTAMP '2024-07-02 13:00:00', TIMESTAMP '2024-07-02 14:30:00'),
+    ('9012 345678', 'Task 7', TIMESTAMP '2024-07-03 09:30:00', TIMESTAMP '2024-07-03 13:15:00'),
+    ('4567 890123', 'Task 8', TIMESTAMP '2024-07-03 14:00:00', TIMESTAMP '2024-07-03 16:15:00'),
+    ('8901 234567', 'Task 9', TIMESTAMP '2024-07-04 08:00:00', TIMESTAMP '2024-07-04 09:00:00'),
+    ('5678 901235', 'Task 10', TIMESTAMP '2024-07-04 10:30:00', TIMESTAMP '2024-07-04 14:30:00'),
+    ('1234 567891', 'Task 11', TIMESTAMP '2024-07-05 09:00:00', TIMESTAMP '2024-07-05 11:30:00'),
+    ('7890 123457', 'Task 12', TIMESTAMP '2024-07-05 13:00:00', TIMESTAMP '2024-07-05 14:15:00'),
+    ('2345 678902', 'Task 13', TIMESTAMP '2024-07-06 10:00:00', TIMESTAMP '2024-07-06 13:00:00'),
+    ('9012 345679', 'Task 14', TIMESTAMP '2024-07-06 14:30:00', TIMESTAMP '2024-07-06 17:15:00'),
+    ('3456 789013', 'Task 15', TIMESTAMP '2024-07-07 08:30:00', TIMESTAMP '2024-07-07 10:00:00'),
+    ('4567 890124', 'Task 16', TIMESTAMP '2024-07-07 11:00:00', TIMESTAMP '2024-07-07 13:00:00'),
+    ('5678 901236', 'Task 17', TIMESTAMP '2024-07-08 09:00:00', TIMESTAMP '2024-07-08 12:15:00'),
+    ('1234 567892', 'Task 18', TIMESTAMP '2024-07-08 14:00:00', TIMESTAMP '2024-07-08 15:45:00'),
+    ('7890 123458', 'Task 19', TIMESTAMP '2024-07-09 08:00:00', TIMESTAMP '2024-07-09 10:00:00'),
+    ('2345 678903', 'Task 20', TIMESTAMP '2024-07-09 11:30:00', TIMESTAMP '2024-07-09 13:00:00')
+) AS v(passport_number, task_name, start_time, end_time)
+INNER JOIN users u ON u.passport_number = v.passport_number
+INNER JOIN tasks t ON t.task_name = v.task_name;
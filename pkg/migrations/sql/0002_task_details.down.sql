@@ -0,0 +1,6 @@
+ALTER TABLE tasks
+    DROP CONSTRAINT IF EXISTS tasks_status_check,
+    DROP COLUMN IF EXISTS archived,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS description;
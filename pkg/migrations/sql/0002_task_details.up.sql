@@ -0,0 +1,6 @@
+ALTER TABLE tasks
+    ADD COLUMN description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT 'todo',
+    ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    ADD COLUMN archived BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD CONSTRAINT tasks_status_check CHECK (status IN ('todo', 'in_progress', 'done'));
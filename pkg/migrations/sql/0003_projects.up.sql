@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS projects (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Участники проекта: только они могут запускать задачи проекта
+CREATE TABLE IF NOT EXISTS project_members (
+    project_id INT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (project_id, user_id)
+);
+
+ALTER TABLE tasks ADD COLUMN project_id INT REFERENCES projects(id);
+CREATE INDEX IF NOT EXISTS tasks_project_id_idx ON tasks (project_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS task_logs_user_id_start_time_idx;
+ALTER TABLE task_logs DROP COLUMN IF EXISTS note;
@@ -0,0 +1,2 @@
+ALTER TABLE task_logs ADD COLUMN note TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS task_logs_user_id_start_time_idx ON task_logs (user_id, start_time);
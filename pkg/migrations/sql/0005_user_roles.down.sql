@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS users_manager_id_idx;
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_role_check,
+    DROP COLUMN IF EXISTS manager_id,
+    DROP COLUMN IF EXISTS role;
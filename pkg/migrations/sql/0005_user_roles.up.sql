@@ -0,0 +1,6 @@
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT 'employee',
+    ADD COLUMN manager_id INT REFERENCES users(id) ON DELETE SET NULL,
+    ADD CONSTRAINT users_role_check CHECK (role IN ('admin', 'manager', 'employee'));
+
+CREATE INDEX IF NOT EXISTS users_manager_id_idx ON users (manager_id);
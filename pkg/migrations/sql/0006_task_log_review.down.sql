@@ -0,0 +1,3 @@
+ALTER TABLE task_logs
+    DROP COLUMN IF EXISTS auto_closed,
+    DROP COLUMN IF EXISTS needs_review;
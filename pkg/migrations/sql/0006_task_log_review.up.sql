@@ -0,0 +1,3 @@
+ALTER TABLE task_logs
+    ADD COLUMN needs_review BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN auto_closed BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS task_logs_open_timer_idx;
@@ -0,0 +1,10 @@
+-- Дубли открытых таймеров, появившиеся из-за гонки при запуске, закрываются нулевой
+-- длительностью и помечаются для проверки; остаётся самый ранний таймер
+UPDATE task_logs
+SET end_time = start_time, auto_closed = TRUE, needs_review = TRUE
+WHERE end_time IS NULL
+  AND id NOT IN (
+    SELECT MIN(id) FROM task_logs WHERE end_time IS NULL GROUP BY user_id, task_id
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS task_logs_open_timer_idx ON task_logs (user_id, task_id) WHERE end_time IS NULL;
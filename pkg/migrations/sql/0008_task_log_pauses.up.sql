@@ -0,0 +1,11 @@
+-- Перерывы внутри записи времени: пауза открывает перерыв, продолжение закрывает его
+CREATE TABLE IF NOT EXISTS task_log_pauses (
+    id SERIAL PRIMARY KEY,
+    task_log_id INT NOT NULL REFERENCES task_logs(id) ON DELETE CASCADE,
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS task_log_pauses_task_log_id_idx ON task_log_pauses (task_log_id);
+-- У записи может идти только один перерыв
+CREATE UNIQUE INDEX IF NOT EXISTS task_log_pauses_open_idx ON task_log_pauses (task_log_id) WHERE end_time IS NULL;
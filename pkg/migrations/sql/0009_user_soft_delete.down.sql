@@ -0,0 +1,2 @@
+-- Мягко удалённые пользователи снова становятся активными
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
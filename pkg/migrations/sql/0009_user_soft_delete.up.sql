@@ -0,0 +1,2 @@
+-- Удалённый пользователь остаётся в таблице вместе с историей времени, пока его не удалят окончательно
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP;
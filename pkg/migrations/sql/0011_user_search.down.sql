@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS users_search_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS updated_at, DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,11 @@
+-- Даты создания и последнего изменения пользователя для фильтров по периоду.
+-- У существующих пользователей обе даты — момент миграции.
+ALTER TABLE users
+    ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+-- Полнотекстовый поиск по ФИО и адресу; выражение совпадает с userSearchVector в репозитории
+CREATE INDEX IF NOT EXISTS users_search_idx ON users USING GIN (
+    to_tsvector('simple', COALESCE(surname, '') || ' ' || COALESCE(name, '') || ' ' ||
+                          COALESCE(patronymic, '') || ' ' || COALESCE(address, ''))
+);
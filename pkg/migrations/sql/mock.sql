@@ -1,70 +0,0 @@
--- Вставка данных в таблицу users
--- Insert unique mock data into users table
-INSERT INTO users (passport_number, surname, name) VALUES
-    ('1234 567890', 'Smith', 'John'),
-    ('5678 901234', 'Doe', 'Jane'),
-    ('2345 678901', 'Johnson', 'Michael'),
-    ('7890 123456', 'Williams', 'Emily'),
-    ('3456 789012', 'Brown', 'James'),
-    ('9012 345678', 'Jones', 'Emma'),
-    ('4567 890123', 'Garcia', 'Daniel'),
-    ('8901 234567', 'Martinez', 'Sophia'),
-    ('5678 901235', 'Robinson', 'Olivia'),  
-    ('1234 567891', 'Clark', 'Liam'),        
-    ('7890 123457', 'Lewis', 'Charlotte'),   
-    ('2345 678902', 'Lee', 'Benjamin'),      
-    ('9012 345679', 'Walker', 'Amelia'),     
-    ('3456 789013', 'Hall', 'Elijah'),       
-    ('4567 890124', 'Allen', 'Mia'),         
-    ('5678 901236', 'Young', 'Ethan'),       
-    ('1234 567892', 'Hernandez', 'Isabella'),
-    ('7890 123458', 'King', 'Ava'),          
-    ('2345 678903', 'Wright', 'Noah'),       
-    ('9012 345680', 'Lopez', 'Sophia');      
-
-
--- Вставка данных в таблицу tasks
-INSERT INTO tasks (task_name) VALUES
-    ('Task 1'),
-    ('Task 2'),
-    ('Task 3'),
-    ('Task 4'),
-    ('Task 5'),
-    ('Task 6'),
-    ('Task 7'),
-    ('Task 8'),
-    ('Task 9'),
-    ('Task 10'),
-    ('Task 11'),
-    ('Task 12'),
-    ('Task 13'),
-    ('Task 14'),
-    ('Task 15'),
-    ('Task 16'),
-    ('Task 17'),
-    ('Task 18'),
-    ('Task 19'),
-    ('Task 20');
-
--- Вставка данных в таблицу task_logs
-INSERT INTO task_logs (user_id, task_id, start_time, end_time) VALUES
-    (1, 1, '2024-07-01 10:00:00', '2024-07-01 12:30:00'),
-    (1, 2, '2024-07-01 14:00:00', '2024-07-01 15:45:00'),
-    (2, 3, '2024-07-01 09:00:00', '2024-07-01 12:15:00'),
-    (3, 4, '2024-07-02 08:30:00', '2024-07-02 09:30:00'),
-    (4, 5, '2024-07-02 10:00:00', '2024-07-02 12:00:00'),
-    (5, 6, '2024-07-02 13:00:00', '2024-07-02 14:30:00'),
-    (6, 7, '2024-07-03 09:30:00', '2024-07-03 13:15:00'),
-    (7, 8, '2024-07-03 14:00:00', '2024-07-03 16:15:00'),
-    (8, 9, '2024-07-04 08:00:00', '2024-07-04 09:00:00'),
-    (9, 10, '2024-07-04 10:30:00', '2024-07-04 14:30:00'),
-    (10, 11, '2024-07-05 09:00:00', '2024-07-05 11:30:00'),
-    (11, 12, '2024-07-05 13:00:00', '2024-07-05 14:15:00'),
-    (12, 13, '2024-07-06 10:00:00', '2024-07-06 13:00:00'),
-    (13, 14, '2024-07-06 14:30:00', '2024-07-06 17:15:00'),
-    (14, 15, '2024-07-07 08:30:00', '2024-07-07 10:00:00'),
-    (15, 16, '2024-07-07 11:00:00', '2024-07-07 13:00:00'),
-    (16, 17, '2024-07-08 09:00:00', '2024-07-08 12:15:00'),
-    (17, 18, '2024-07-08 14:00:00', '2024-07-08 15:45:00'),
-    (18, 19, '2024-07-09 08:00:00', '2024-07-09 10:00:00'),
-    (19, 20, '2024-07-09 11:30:00', '2024-07-09 13:00:00');